@@ -0,0 +1,7 @@
+data "pwpusher_features" "example" {}
+
+resource "pwpusher_text" "example" {
+  count = data.pwpusher_features.example.text ? 1 : 0
+
+  password = "some-value"
+}
@@ -0,0 +1,16 @@
+data "pwpusher_health" "example" {
+  timeouts {
+    read = "30s"
+  }
+}
+
+resource "pwpusher_text" "example" {
+  password = "some-value"
+
+  lifecycle {
+    precondition {
+      condition     = data.pwpusher_health.example.healthy
+      error_message = data.pwpusher_health.example.message
+    }
+  }
+}
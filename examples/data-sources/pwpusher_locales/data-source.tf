@@ -0,0 +1,19 @@
+data "pwpusher_locales" "example" {}
+
+resource "pwpusher_text" "example" {
+  password = "some-value"
+}
+
+variable "locale" {
+  type    = string
+  default = "fr"
+}
+
+output "localized_url" {
+  value = "https://pwpush.com/p/${pwpusher_text.example.id}?locale=${var.locale}"
+
+  precondition {
+    condition     = contains(data.pwpusher_locales.example.locales, var.locale)
+    error_message = "The locale ${var.locale} is not supported by pwpusher."
+  }
+}
@@ -0,0 +1,10 @@
+resource "pwpusher_text" "example" {
+  password = "some-value"
+}
+
+data "pwpusher_push_check" "example" {
+  id                  = pwpusher_text.example.id
+  must_not_be_expired = true
+  min_days_remaining  = 2
+  min_views_remaining = 1
+}
@@ -0,0 +1,9 @@
+resource "pwpusher_text" "example" {
+  password = "some-value"
+}
+
+data "pwpusher_push_viewed" "example" {
+  id            = pwpusher_text.example.id
+  timeout       = "1h"
+  poll_interval = "1m"
+}
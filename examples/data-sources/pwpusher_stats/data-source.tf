@@ -0,0 +1,8 @@
+data "pwpusher_stats" "example" {
+  expiring_within_days = 2
+  period_days          = 7
+}
+
+output "expiring_pushes" {
+  value = data.pwpusher_stats.example.expiring_count
+}
@@ -0,0 +1,7 @@
+data "pwpusher_token_info" "example" {}
+
+resource "pwpusher_text" "example" {
+  password = "some-value"
+
+  depends_on = [data.pwpusher_token_info.example]
+}
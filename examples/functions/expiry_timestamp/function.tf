@@ -0,0 +1,7 @@
+resource "pwpusher_text" "example" {
+  password = "some-value"
+}
+
+output "expires_at" {
+  value = provider::pwpusher::expiry_timestamp(pwpusher_text.example.created_at, pwpusher_text.example.expire_after_days)
+}
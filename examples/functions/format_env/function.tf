@@ -0,0 +1,12 @@
+variable "db_password" {
+  type      = string
+  sensitive = true
+}
+
+resource "pwpusher_text" "example" {
+  password = provider::pwpusher::format_env({
+    DB_HOST     = "db.internal.example.com"
+    DB_USER     = "app"
+    DB_PASSWORD = var.db_password
+  })
+}
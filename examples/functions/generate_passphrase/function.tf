@@ -0,0 +1,11 @@
+# The passphrase is only as secret as the seed: pass a long random value that
+# is marked sensitive, and rotate the passphrase by changing it.
+variable "passphrase_seed" {
+  type      = string
+  sensitive = true
+}
+
+resource "pwpusher_text" "example" {
+  password   = "some-value"
+  passphrase = provider::pwpusher::generate_passphrase(var.passphrase_seed, 4, "-")
+}
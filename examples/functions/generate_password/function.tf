@@ -0,0 +1,10 @@
+# The password is only as secret as the seed: pass a long random value that
+# is marked sensitive, and rotate the password by changing it.
+variable "password_seed" {
+  type      = string
+  sensitive = true
+}
+
+resource "pwpusher_text" "example" {
+  password = provider::pwpusher::generate_password(var.password_seed, 24, "alphanumeric", "!@#$%")
+}
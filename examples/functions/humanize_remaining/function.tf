@@ -0,0 +1,7 @@
+resource "pwpusher_text" "example" {
+  password = "some-value"
+}
+
+output "remaining" {
+  value = provider::pwpusher::humanize_remaining(pwpusher_text.example.days_remaining, pwpusher_text.example.views_remaining)
+}
@@ -0,0 +1,12 @@
+resource "pwpusher_text" "example" {
+  password = "some-value"
+}
+
+output "expires_within_a_day" {
+  value = provider::pwpusher::is_expired(
+    pwpusher_text.example.expired_on,
+    pwpusher_text.example.created_at,
+    pwpusher_text.example.expire_after_days,
+    timeadd(plantimestamp(), "24h"),
+  )
+}
@@ -0,0 +1,7 @@
+resource "pwpusher_text" "example" {
+  password = "some-value"
+}
+
+output "mailto" {
+  value = provider::pwpusher::mailto_link("new.hire@example.com", "https://pwpush.com/p/${pwpusher_text.example.id}", "Your laptop password", null)
+}
@@ -0,0 +1,3 @@
+output "token" {
+  value = provider::pwpusher::parse_token("https://pwpush.com/p/abc123/r?locale=fr").token
+}
@@ -0,0 +1,9 @@
+variable "password" {
+  type      = string
+  sensitive = true
+
+  validation {
+    condition     = provider::pwpusher::password_strength(var.password).score >= 3
+    error_message = "The password must be strong."
+  }
+}
@@ -0,0 +1,7 @@
+resource "pwpusher_text" "example" {
+  password = "some-value"
+}
+
+output "qr_markdown" {
+  value = "![Scan to retrieve](${provider::pwpusher::qr_data_uri("https://pwpush.com/p/${pwpusher_text.example.id}", null)})"
+}
@@ -0,0 +1,8 @@
+variable "api_key" {
+  type      = string
+  sensitive = true
+}
+
+output "api_key_hint" {
+  value = provider::pwpusher::redact(nonsensitive(var.api_key), 3)
+}
@@ -0,0 +1,12 @@
+resource "pwpusher_text" "example" {
+  password = "some-value"
+}
+
+output "message" {
+  value = provider::pwpusher::share_message(
+    "https://pwpush.com/p/${pwpusher_text.example.id}",
+    pwpusher_text.example.days_remaining,
+    pwpusher_text.example.views_remaining,
+    "Your credentials: {{.URL}} ({{.Remaining}})",
+  )
+}
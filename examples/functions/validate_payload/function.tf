@@ -0,0 +1,9 @@
+variable "secret" {
+  type      = string
+  sensitive = true
+
+  validation {
+    condition     = provider::pwpusher::validate_payload(var.secret, 4096).valid
+    error_message = "The secret must be non-empty UTF-8 text of at most 4096 bytes."
+  }
+}
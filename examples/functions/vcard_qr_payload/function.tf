@@ -0,0 +1,8 @@
+output "vcard" {
+  value = provider::pwpusher::vcard_qr_payload({
+    first_name = "Ada"
+    last_name  = "Lovelace"
+    phone      = "+44 20 7946 0000"
+    email      = "ada@example.com"
+  })
+}
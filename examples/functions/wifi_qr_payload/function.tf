@@ -0,0 +1,8 @@
+variable "wifi_password" {
+  type      = string
+  sensitive = true
+}
+
+resource "pwpusher_text" "example" {
+  password = provider::pwpusher::wifi_qr_payload("Office", var.wifi_password, "WPA", false)
+}
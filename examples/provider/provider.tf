@@ -8,4 +8,14 @@ terraform {
 provider "pwpusher" {
   # example configuration here
   url = "http://localhost:5100"
+
+  # Optional, authenticates pushes as this account. Can also be set with the
+  # PWPUSH_EMAIL and PWPUSH_API_TOKEN environment variables
+  email     = "user@example.com"
+  api_token = var.pwpusher_api_token
+}
+
+variable "pwpusher_api_token" {
+  type      = string
+  sensitive = true
 }
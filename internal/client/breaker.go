@@ -0,0 +1,107 @@
+// Copyright (c) Plex, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package client
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// ErrCircuitOpen is a request that was not sent because the previous requests
+// to the service kept failing.
+var ErrCircuitOpen = errors.New("circuit open")
+
+// CircuitOpenError is returned instead of sending a request to a service the
+// Breaker of the client stopped the requests to.
+type CircuitOpenError struct {
+	URL      string
+	Failures int
+	// Until is when the breaker lets a request through again.
+	Until time.Time
+	// LastErr is the error of the last failed request.
+	LastErr error
+}
+
+func (e *CircuitOpenError) Error() string {
+	return fmt.Sprintf("stopped sending requests to %s after %d consecutive failures, until %s; last error: %s", e.URL, e.Failures, e.Until.Format(time.RFC3339), e.LastErr)
+}
+
+// Is reports whether target is ErrCircuitOpen.
+func (e *CircuitOpenError) Is(target error) bool {
+	return target == ErrCircuitOpen
+}
+
+// Breaker stops the requests of the clients sharing it to a service once
+// Threshold consecutive requests to it failed, so that the operations of a
+// large apply fail right away instead of each waiting for its own timeout.
+// After Cooldown it lets requests through again, and a success closes it.
+// Requests fail when they are not answered or answered with a server error.
+type Breaker struct {
+	Threshold int
+	Cooldown  time.Duration
+
+	mu       sync.Mutex
+	services map[string]*breakerState
+}
+
+// breakerState is the state of a Breaker for a service.
+type breakerState struct {
+	failures int
+	openedAt time.Time
+	lastErr  error
+}
+
+// NewBreaker returns a breaker opening after threshold consecutive failures
+// for cooldown.
+func NewBreaker(threshold int, cooldown time.Duration) *Breaker {
+	return &Breaker{Threshold: threshold, Cooldown: cooldown, services: map[string]*breakerState{}}
+}
+
+// allow returns a *CircuitOpenError when b stopped the requests to
+// serviceURL. A nil breaker allows every request.
+func (b *Breaker) allow(serviceURL string) error {
+	if b == nil {
+		return nil
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	state, ok := b.services[serviceURL]
+	if !ok || state.failures < b.Threshold {
+		return nil
+	}
+	if until := state.openedAt.Add(b.Cooldown); time.Now().Before(until) {
+		return &CircuitOpenError{URL: serviceURL, Failures: state.failures, Until: until, LastErr: state.lastErr}
+	}
+	return nil
+}
+
+// record records whether a request to serviceURL failed with err. Requests
+// of operations that were cancelled or timed out are not recorded, their
+// failure says nothing about the service.
+func (b *Breaker) record(ctx context.Context, serviceURL string, failed bool, err error) {
+	if b == nil || ctx.Err() != nil {
+		return
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if !failed {
+		delete(b.services, serviceURL)
+		return
+	}
+	state, ok := b.services[serviceURL]
+	if !ok {
+		state = &breakerState{}
+		b.services[serviceURL] = state
+	}
+	state.failures++
+	state.lastErr = err
+	if state.failures >= b.Threshold {
+		// A failure after the cooldown opens the breaker again right away.
+		state.openedAt = time.Now()
+	}
+}
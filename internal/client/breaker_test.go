@@ -0,0 +1,79 @@
+// Copyright (c) Plex, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package client
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestBreaker(t *testing.T) {
+	var requests atomic.Int32
+	var status atomic.Int32
+	status.Store(http.StatusServiceUnavailable)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		w.WriteHeader(int(status.Load()))
+		w.Write([]byte(`{}`))
+	}))
+	defer server.Close()
+
+	ctx := context.Background()
+	breaker := NewBreaker(2, 50*time.Millisecond)
+	c := New(server.Client(), server.URL, "")
+	c.Breaker = breaker
+
+	// Client errors are answers of a working service.
+	status.Store(http.StatusNotFound)
+	for i := 0; i < 3; i++ {
+		if _, err := c.Version(ctx); !errors.Is(err, ErrNotFound) {
+			t.Fatalf("got error %v, want %v", err, ErrNotFound)
+		}
+	}
+
+	status.Store(http.StatusServiceUnavailable)
+	if _, err := c.Version(ctx); errors.Is(err, ErrCircuitOpen) {
+		t.Fatalf("got error %v before the threshold", err)
+	}
+	// Cancelled operations are neither failures nor successes.
+	cancelled, cancel := context.WithCancel(ctx)
+	cancel()
+	if _, err := c.Version(cancelled); !errors.Is(err, context.Canceled) {
+		t.Errorf("got error %v, want %v", err, context.Canceled)
+	}
+	if _, err := c.Version(ctx); errors.Is(err, ErrCircuitOpen) {
+		t.Fatalf("got error %v before the threshold", err)
+	}
+
+	before := requests.Load()
+	_, err := c.Version(ctx)
+	var openErr *CircuitOpenError
+	if !errors.As(err, &openErr) || openErr.Failures != 2 || openErr.URL != server.URL {
+		t.Fatalf("got error %v, want an open circuit after 2 failures", err)
+	}
+	if requests.Load() != before {
+		t.Error("sent a request through an open circuit")
+	}
+	// Other clients of the same service share the breaker.
+	other := New(server.Client(), server.URL, "team")
+	other.Breaker = breaker
+	if _, err := other.Version(ctx); !errors.Is(err, ErrCircuitOpen) {
+		t.Errorf("other client: got error %v, want %v", err, ErrCircuitOpen)
+	}
+
+	time.Sleep(60 * time.Millisecond)
+	status.Store(http.StatusOK)
+	if _, err := c.Version(ctx); err != nil {
+		t.Fatalf("after the cooldown: unexpected error: %s", err)
+	}
+	status.Store(http.StatusServiceUnavailable)
+	if _, err := c.Version(ctx); errors.Is(err, ErrCircuitOpen) {
+		t.Errorf("got error %v after a success closed the circuit", err)
+	}
+}
@@ -0,0 +1,113 @@
+// Copyright (c) Plex, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package client
+
+import (
+	"context"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+)
+
+// Cache keeps the responses of the read requests of the clients sharing it,
+// so that the data sources of a large configuration reading the same
+// dashboard do not repeat the same request. Responses younger than TTL are
+// reused as they are, older ones are revalidated with their ETag or
+// Last-Modified header. Any other request empties the cache, since it may
+// change what the service would answer.
+type Cache struct {
+	TTL time.Duration
+
+	mu      sync.Mutex
+	entries map[string]*cacheEntry
+}
+
+// cacheEntry is a cached response. Its mutex is held while the response is
+// fetched, so that concurrent reads of the same URL send a single request.
+type cacheEntry struct {
+	mu           sync.Mutex
+	body         []byte
+	contentType  string
+	etag         string
+	lastModified string
+	fetchedAt    time.Time
+}
+
+// NewCache returns a cache reusing responses for ttl.
+func NewCache(ttl time.Duration) *Cache {
+	return &Cache{TTL: ttl, entries: map[string]*cacheEntry{}}
+}
+
+// entry returns the entry of the response of key, the URL of a request,
+// locked.
+func (c *Cache) entry(key string) *cacheEntry {
+	c.mu.Lock()
+	entry, ok := c.entries[key]
+	if !ok {
+		entry = &cacheEntry{}
+		c.entries[key] = entry
+	}
+	c.mu.Unlock()
+	entry.mu.Lock()
+	return entry
+}
+
+// clear empties c. A nil cache has nothing to clear.
+func (c *Cache) clear() {
+	if c == nil {
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.entries = map[string]*cacheEntry{}
+}
+
+// getCachedJSON is getJSON for requests whose responses are kept in the
+// Cache of the client, if any.
+func (c *Client) getCachedJSON(ctx context.Context, path string, out any) error {
+	req, err := c.newGetRequest(ctx, path)
+	if err != nil {
+		return err
+	}
+	if c.Cache == nil {
+		return c.do(req, path, out)
+	}
+
+	entry := c.Cache.entry(req.URL.String())
+	defer entry.mu.Unlock()
+	if entry.body != nil && time.Since(entry.fetchedAt) < c.Cache.TTL {
+		tflog.SubsystemTrace(ctx, LogSubsystem, "Reusing cached pwpusher response", map[string]interface{}{
+			"operation": OperationName(ctx),
+			"path":      path,
+		})
+		return decodeJSON(path, entry.contentType, entry.body, out, c.StrictDecoding)
+	}
+	if entry.body != nil {
+		if entry.etag != "" {
+			req.Header.Set("If-None-Match", entry.etag)
+		}
+		if entry.lastModified != "" {
+			req.Header.Set("If-Modified-Since", entry.lastModified)
+		}
+	}
+
+	res, body, err := c.roundTrip(req, path)
+	if err != nil {
+		return err
+	}
+	switch {
+	case res.StatusCode == http.StatusNotModified && entry.body != nil:
+		entry.fetchedAt = time.Now()
+		return decodeJSON(path, entry.contentType, entry.body, out, c.StrictDecoding)
+	case res.StatusCode == http.StatusOK:
+		entry.body = body
+		entry.contentType = res.Header.Get("Content-Type")
+		entry.etag = res.Header.Get("ETag")
+		entry.lastModified = res.Header.Get("Last-Modified")
+		entry.fetchedAt = time.Now()
+	}
+	return c.decodeResponse(path, res, body, out)
+}
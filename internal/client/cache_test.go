@@ -0,0 +1,124 @@
+// Copyright (c) Plex, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package client
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestCache(t *testing.T) {
+	var requests, revalidated atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// Creations, retrievals and expirations of pushes.
+		if r.URL.Path == "/p.json" || r.URL.Path == "/p/token1.json" {
+			w.Header().Set("Content-Type", "application/json")
+			w.Write([]byte(`{"url_token":"token1"}`))
+			return
+		}
+		requests.Add(1)
+		if r.Header.Get("If-None-Match") == `"v1"` {
+			revalidated.Add(1)
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+		if r.URL.Query().Get("page") != "1" {
+			w.Header().Set("Content-Type", "application/json")
+			w.Write([]byte(`[]`))
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("ETag", `"v1"`)
+		w.Write([]byte(`[{"url_token":"token1"}]`))
+	}))
+	defer server.Close()
+
+	ctx := context.Background()
+	cache := NewCache(time.Hour)
+	c := New(server.Client(), server.URL, "")
+	c.Cache = cache
+
+	list := func() {
+		t.Helper()
+		pushes, err := c.ListPushes(ctx, "active")
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if len(pushes) != 1 || pushes[0].ID != "token1" {
+			t.Fatalf("got pushes %+v, want token1", pushes)
+		}
+	}
+
+	// A dashboard of a single page takes two requests.
+	list()
+	list()
+	if got := requests.Load(); got != 2 {
+		t.Errorf("got %d requests within the TTL, want 2", got)
+	}
+
+	// Expired responses are revalidated.
+	cache.TTL = 0
+	list()
+	if got, want := revalidated.Load(), int32(1); got != want {
+		t.Errorf("got %d revalidated requests, want %d", got, want)
+	}
+	if got := requests.Load(); got != 4 {
+		t.Errorf("got %d requests after the TTL, want 4", got)
+	}
+
+	// Other requests empty the cache.
+	cache.TTL = time.Hour
+	for _, change := range []func() error{
+		func() error { _, err := c.CreatePush(ctx, CurrentAPI, Payload{Password: "secret"}); return err },
+		func() error { return c.ExpirePush(ctx, "token1") },
+		func() error { _, err := c.GetPush(ctx, "token1"); return err },
+	} {
+		before := requests.Load()
+		if err := change(); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		list()
+		if got := requests.Load() - before; got < 2 {
+			t.Errorf("got %d requests after a change, want 2", got)
+		}
+	}
+}
+
+func TestCacheConcurrentReads(t *testing.T) {
+	var requests atomic.Int32
+	release := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		<-release
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"application_version":"1.0.0","api_version":"1.0","edition":"oss"}`))
+	}))
+	defer server.Close()
+
+	c := New(server.Client(), server.URL, "")
+	c.Cache = NewCache(time.Hour)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if _, err := c.RouteExists(context.Background(), "/api/v1/version.json"); err != nil {
+				t.Errorf("unexpected error: %v", err)
+			}
+		}()
+	}
+	time.Sleep(50 * time.Millisecond)
+	close(release)
+	wg.Wait()
+
+	if got := requests.Load(); got != 1 {
+		t.Errorf("got %d requests for concurrent reads, want 1", got)
+	}
+}
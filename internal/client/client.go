@@ -0,0 +1,303 @@
+// Copyright (c) Plex, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+// Package client implements the requests the provider makes to the API of a
+// pwpusher service.
+package client
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+)
+
+// Client sends requests to the API of the pwpusher service at URL.
+type Client struct {
+	// HTTPClient sends the requests, authenticating them when the provider
+	// is configured with credentials.
+	HTTPClient *http.Client
+	// URL is the URL of the service, which may include a path prefix.
+	URL string
+	// AccountID selects the Pro account of the requests, empty for the
+	// default account of the user.
+	AccountID string
+	// StrictDecoding fails on fields of responses that the types of the
+	// package do not have, instead of ignoring them.
+	StrictDecoding bool
+	// MaxResponseBytes is the size of the largest response body the client
+	// reads, 0 for DefaultMaxResponseBytes.
+	MaxResponseBytes int64
+	// CompressRequests compresses the request bodies of at least
+	// CompressMinBytes with gzip.
+	CompressRequests bool
+	// Breaker stops the requests to a failing service, nil for none. It
+	// is shared by the clients of the service.
+	Breaker *Breaker
+	// Cache keeps the responses of read requests, nil for none. It is
+	// shared by the clients of the provider.
+	Cache *Cache
+	// Recorder is told of every request of the client, nil for none.
+	Recorder RequestRecorder
+}
+
+// LogSubsystem is the tflog subsystem of the logs of the client. The
+// contexts of the requests must carry it, see tflog.NewSubsystem.
+const LogSubsystem = "client"
+
+// CompressMinBytes is the size of the smallest request body a client
+// compresses, below which compression saves less than it costs.
+const CompressMinBytes = 8 << 10
+
+// DefaultMaxResponseBytes is the default size of the largest response body a
+// client reads. The responses of the API are a few kilobytes, even pages of
+// the dashboards.
+const DefaultMaxResponseBytes = 10 << 20
+
+// New returns a client of the service at serviceURL sending requests with
+// httpClient.
+func New(httpClient *http.Client, serviceURL, accountID string) *Client {
+	return &Client{HTTPClient: httpClient, URL: serviceURL, AccountID: accountID}
+}
+
+// PushClient is the API of a pwpusher service the resources and data sources
+// of the provider use. Client implements it for real services, tests may use
+// the fake service of the clienttest package instead. The requests of the
+// methods are bound to their context, so that they stop when Terraform
+// cancels the operation or its timeout expires.
+type PushClient interface {
+	CreatePush(ctx context.Context, api API, payload Payload) (Push, error)
+	GetPush(ctx context.Context, token string) (Push, error)
+	ExpirePush(ctx context.Context, token string) error
+	ListPushes(ctx context.Context, dashboard string) ([]Push, error)
+	Audit(ctx context.Context, token string) (AuditLog, error)
+	PushURL(token, locale string) (string, error)
+	RouteExists(ctx context.Context, path string) (bool, error)
+	CheckCredentials(ctx context.Context) error
+	Locales(ctx context.Context) (map[string]string, error)
+	PushForm(ctx context.Context) (PushForm, error)
+	Version(ctx context.Context) (Version, error)
+}
+
+var _ PushClient = (*Client)(nil)
+
+// Endpoint returns the URL of path on the service, below the path prefix of
+// the service URL. path may include a query string.
+func (c *Client) Endpoint(path string) (string, error) {
+	path, query, _ := strings.Cut(path, "?")
+	endpoint, err := url.JoinPath(c.URL, path)
+	if err != nil {
+		return "", err
+	}
+	if query != "" {
+		endpoint += "?" + query
+	}
+	return endpoint, nil
+}
+
+// getJSON performs a GET request for path and decodes the JSON response body
+// into out.
+func (c *Client) getJSON(ctx context.Context, path string, out any) error {
+	req, err := c.newGetRequest(ctx, path)
+	if err != nil {
+		return err
+	}
+	return c.do(req, path, out)
+}
+
+// newGetRequest returns a GET request for path, of the account of the client.
+func (c *Client) newGetRequest(ctx context.Context, path string) (*http.Request, error) {
+	endpoint, err := c.Endpoint(path)
+	if err != nil {
+		return nil, err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, endpoint, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Accept", "application/json")
+	if c.AccountID != "" {
+		query := req.URL.Query()
+		query.Set("account_id", c.AccountID)
+		req.URL.RawQuery = query.Encode()
+	}
+	return req, nil
+}
+
+// do sends req for path and decodes the JSON body of its response into out,
+// unless out is nil.
+func (c *Client) do(req *http.Request, path string, out any) error {
+	res, body, err := c.roundTrip(req, path)
+	if err != nil {
+		return err
+	}
+	return c.decodeResponse(path, res, body, out)
+}
+
+// roundTrip sends req for path, unless the Breaker of the client stopped the
+// requests to the service, and returns its response with the body read. The
+// request is recorded in the Metrics of its context, if any.
+func (c *Client) roundTrip(req *http.Request, path string) (*http.Response, []byte, error) {
+	if err := c.Breaker.allow(c.URL); err != nil {
+		return nil, nil, err
+	}
+	if req.Method != http.MethodGet {
+		// The reads made while the request is retried, such as looking for
+		// the push a failed attempt created, must not see the responses
+		// from before it either.
+		c.Cache.clear()
+		defer c.Cache.clear()
+	}
+
+	ctx := req.Context()
+	start := time.Now()
+	res, body, err := c.read(req, path)
+	status := 0
+	if res != nil {
+		status = res.StatusCode
+	}
+	duration := time.Since(start)
+	recordRequest(ctx, duration, status)
+	if c.Recorder != nil {
+		c.Recorder.RecordRequest(OperationName(ctx), duration, status)
+	}
+	fields := map[string]interface{}{
+		"operation":   OperationName(ctx),
+		"method":      req.Method,
+		"path":        path,
+		"status":      status,
+		"duration_ms": duration.Milliseconds(),
+	}
+	if err != nil {
+		fields["error"] = err.Error()
+	}
+	tflog.SubsystemTrace(ctx, LogSubsystem, "pwpusher request", fields)
+	switch {
+	case res == nil:
+		c.Breaker.record(ctx, c.URL, true, err)
+	case err == nil && res.StatusCode >= http.StatusInternalServerError:
+		c.Breaker.record(ctx, c.URL, true, newResponseError(path, res, body))
+	default:
+		c.Breaker.record(ctx, c.URL, false, err)
+	}
+	return res, body, err
+}
+
+// read sends req for path and reads the body of its response. The response
+// is nil when the service did not answer.
+func (c *Client) read(req *http.Request, path string) (*http.Response, []byte, error) {
+	res, err := c.HTTPClient.Do(req)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer res.Body.Close()
+
+	limit := c.MaxResponseBytes
+	if limit <= 0 {
+		limit = DefaultMaxResponseBytes
+	}
+	// Reading one byte past the limit tells a body of the size of the limit
+	// from a larger one.
+	body, err := io.ReadAll(io.LimitReader(res.Body, limit+1))
+	if err != nil {
+		return res, nil, fmt.Errorf("unable to read the response from %s: %w", path, err)
+	}
+	if int64(len(body)) > limit {
+		return res, nil, fmt.Errorf("the %s response from %s of type %q is larger than the limit of %d bytes", res.Status, path, res.Header.Get("Content-Type"), limit)
+	}
+	return res, body, nil
+}
+
+// decodeResponse decodes the JSON body of the response res to path into
+// out, unless out is nil. A response with a status other than 2xx is
+// returned as a *ResponseError.
+func (c *Client) decodeResponse(path string, res *http.Response, body []byte, out any) error {
+	if res.StatusCode < 200 || res.StatusCode > 299 {
+		return newResponseError(path, res, body)
+	}
+	if out == nil {
+		return nil
+	}
+	return decodeJSON(path, res.Header.Get("Content-Type"), body, out, c.StrictDecoding)
+}
+
+// decodeJSON decodes the JSON response body from path into out, describing
+// what the service sent instead when it is not the expected JSON. strict
+// fails on fields that out does not have.
+func decodeJSON(path, contentType string, body []byte, out any, strict bool) error {
+	if len(bytes.TrimSpace(body)) == 0 {
+		return fmt.Errorf("empty response from %s, expected JSON", path)
+	}
+	err := json.Unmarshal(body, out)
+	var syntaxErr *json.SyntaxError
+	var typeErr *json.UnmarshalTypeError
+	switch {
+	case err == nil && strict:
+		// The syntax and types are fine, decoding again only checks the
+		// fields.
+		decoder := json.NewDecoder(bytes.NewReader(body))
+		decoder.DisallowUnknownFields()
+		if err := decoder.Decode(out); err != nil {
+			return fmt.Errorf("unexpected response from %s, the API of the service may have changed: %w", path, err)
+		}
+		return nil
+	case err == nil:
+		return nil
+	case errors.As(err, &syntaxErr):
+		// Proxies and login pages answer with HTML rather than JSON.
+		if mediaType, _, _ := mime.ParseMediaType(contentType); mediaType != "application/json" {
+			return fmt.Errorf("invalid JSON in the response from %s of type %q, is the URL that of a pwpusher service? %w", path, contentType, err)
+		}
+		return fmt.Errorf("invalid JSON in the response from %s at offset %d: %w", path, syntaxErr.Offset, err)
+	case errors.As(err, &typeErr) && typeErr.Field != "":
+		return fmt.Errorf("unexpected %s value for %s in the response from %s, expected %s: %w", typeErr.Value, typeErr.Field, path, typeErr.Type, err)
+	case errors.As(err, &typeErr):
+		return fmt.Errorf("unexpected %s in the response from %s, expected %s: %w", typeErr.Value, path, typeErr.Type, err)
+	}
+	return fmt.Errorf("unable to decode the response from %s: %w", path, err)
+}
+
+// RouteExists reports whether the service routes path. Only a not found
+// response means it does not, authentication errors still prove the route
+// is there.
+func (c *Client) RouteExists(ctx context.Context, path string) (bool, error) {
+	ctx = withOperation(ctx, "RouteExists")
+	var body json.RawMessage
+	err := c.getCachedJSON(ctx, path, &body)
+
+	var respErr *ResponseError
+	if errors.As(err, &respErr) {
+		return !errors.Is(err, ErrNotFound), nil
+	}
+	if err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// CheckCredentials returns a *ResponseError with an unauthorized or
+// forbidden status when the service rejects the credentials of the client.
+func (c *Client) CheckCredentials(ctx context.Context) error {
+	ctx = withOperation(ctx, "CheckCredentials")
+	// The dashboard is only available to authenticated users, so a single
+	// page of it is enough to tell whether the credentials are accepted.
+	var pushes []Push
+	return c.getJSON(ctx, listActivePushesPath+"?page=1", &pushes)
+}
+
+// Version returns the version information of the service.
+func (c *Client) Version(ctx context.Context) (Version, error) {
+	ctx = withOperation(ctx, "Version")
+	var version Version
+	err := c.getJSON(ctx, versionPath, &version)
+	return version, err
+}
@@ -0,0 +1,341 @@
+// Copyright (c) Plex, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package client
+
+import (
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestClientEndpoint(t *testing.T) {
+	c := New(http.DefaultClient, "https://intranet.example.com/pwpush", "")
+
+	for path, want := range map[string]string{
+		"/p.json":               "https://intranet.example.com/pwpush/p.json",
+		"/p/active.json?page=2": "https://intranet.example.com/pwpush/p/active.json?page=2",
+		"/api/v1/version.json":  "https://intranet.example.com/pwpush/api/v1/version.json",
+		"/p/abc/../audit.json":  "https://intranet.example.com/pwpush/p/audit.json",
+	} {
+		if got, err := c.Endpoint(path); err != nil || got != want {
+			t.Errorf("%q: got %q, %v, want %q", path, got, err, want)
+		}
+	}
+}
+
+func TestAPIEncodePush(t *testing.T) {
+	payload := Payload{Password: "secret", RetrievalStep: true, Kind: "text"}
+
+	for name, test := range map[string]struct {
+		api  API
+		want string
+	}{
+		"current": {CurrentAPI, `{"payload":"secret","passphrase":null,"deletable_by_viewer":false,"retrieval_step":true,"kind":"text"}`},
+		"legacy":  {LegacyAPI, `{"password":{"payload":"secret","deletable_by_viewer":false,"retrieval_step":true}}`},
+	} {
+		body, err := test.api.EncodePush(payload)
+		if err != nil {
+			t.Fatalf("%s: unexpected error: %s", name, err)
+		}
+		if string(body) != test.want {
+			t.Errorf("%s: got %s, want %s", name, body, test.want)
+		}
+	}
+
+	passphrase := "words"
+	payload.Passphrase = &passphrase
+	if _, err := LegacyAPI.EncodePush(payload); !errors.Is(err, ErrPassphraseUnsupported) {
+		t.Errorf("legacy with passphrase: got error %v, want %v", err, ErrPassphraseUnsupported)
+	}
+}
+
+func TestClientPushes(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if got := r.URL.Query().Get("account_id"); r.Method == http.MethodGet && got != "team" {
+			t.Errorf("%s: got account_id %q, want %q", r.URL.Path, got, "team")
+		}
+		switch r.Method + " " + r.URL.Path {
+		case "POST /p.json":
+			var payload Payload
+			if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
+				t.Error(err)
+			}
+			fmt.Fprintf(w, `{"url_token":%q,"views_remaining":5}`, payload.Password)
+		case "GET /p/abc.json":
+			fmt.Fprint(w, `{"url_token":"abc","views_remaining":4}`)
+		case "DELETE /p/abc.json":
+			fmt.Fprint(w, `{"url_token":"abc","expired":true}`)
+		case "GET /p/active.json":
+			if r.URL.Query().Get("page") == "1" {
+				fmt.Fprint(w, `[{"url_token":"abc"},{"url_token":"def"}]`)
+				return
+			}
+			fmt.Fprint(w, `[]`)
+		case "GET /p/abc/audit.json":
+			fmt.Fprint(w, `{"views":[{"successful":true,"kind":0}]}`)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+
+	ctx := context.Background()
+	c := New(server.Client(), server.URL, "team")
+
+	if push, err := c.CreatePush(ctx, CurrentAPI, Payload{Password: "abc", Kind: "text"}); err != nil || push.ID != "abc" || push.ViewsRemaining != 5 {
+		t.Errorf("CreatePush: got %+v, %v", push, err)
+	}
+	if push, err := c.GetPush(ctx, "abc"); err != nil || push.ViewsRemaining != 4 {
+		t.Errorf("GetPush: got %+v, %v", push, err)
+	}
+	if err := c.ExpirePush(ctx, "abc"); err != nil {
+		t.Errorf("ExpirePush: got %v", err)
+	}
+	if pushes, err := c.ListPushes(ctx, "active"); err != nil || len(pushes) != 2 {
+		t.Errorf("ListPushes: got %+v, %v", pushes, err)
+	}
+	if auditLog, err := c.Audit(ctx, "abc"); err != nil || len(auditLog.Views) != 1 {
+		t.Errorf("Audit: got %+v, %v", auditLog, err)
+	}
+
+	_, err := c.GetPush(ctx, "missing")
+	var respErr *ResponseError
+	if !errors.As(err, &respErr) || respErr.StatusCode != http.StatusNotFound {
+		t.Errorf("GetPush of a missing push: got %v, want a not found response error", err)
+	}
+	if exists, err := c.RouteExists(ctx, "/f/active.json"); err != nil || exists {
+		t.Errorf("RouteExists: got %t, %v, want false", exists, err)
+	}
+}
+
+func TestClientResponses(t *testing.T) {
+	for name, test := range map[string]struct {
+		contentType string
+		status      int
+		body        string
+		want        *regexp.Regexp
+	}{
+		"created":        {"application/json", http.StatusCreated, `{"url_token":"abc"}`, nil},
+		"rejected":       {"application/json", http.StatusUnprocessableEntity, `{"error":"Payload is too long"}`, regexp.MustCompile(`^unexpected response from /p.json: 422 Unprocessable Entity: Payload is too long$`)},
+		"empty":          {"application/json", http.StatusOK, ``, regexp.MustCompile(`^empty response from /p.json, expected JSON$`)},
+		"html":           {"text/html; charset=utf-8", http.StatusOK, `<html>Sign in</html>`, regexp.MustCompile(`of type "text/html; charset=utf-8", is the URL that of a pwpusher service\?`)},
+		"truncated":      {"application/json", http.StatusOK, `{"url_token":`, regexp.MustCompile(`^invalid JSON in the response from /p.json at offset 13: unexpected end of JSON input$`)},
+		"invalid":        {"application/json", http.StatusOK, `{"url_token":"abc"}}`, regexp.MustCompile(`at offset 20`)},
+		"field type":     {"application/json", http.StatusOK, `{"url_token":"abc","views_remaining":"5"}`, regexp.MustCompile(`^unexpected string value for views_remaining in the response from /p.json, expected int`)},
+		"top level type": {"application/json", http.StatusOK, `[]`, regexp.MustCompile(`^unexpected array in the response from /p.json, expected client.Push`)},
+	} {
+		t.Run(name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.Header().Set("Content-Type", test.contentType)
+				w.WriteHeader(test.status)
+				fmt.Fprint(w, test.body)
+			}))
+			defer server.Close()
+
+			push, err := New(server.Client(), server.URL, "").CreatePush(context.Background(), CurrentAPI, Payload{Password: "secret"})
+			switch {
+			case test.want == nil && err != nil:
+				t.Errorf("unexpected error: %s", err)
+			case test.want == nil && push.ID != "abc":
+				t.Errorf("got push %+v", push)
+			case test.want != nil && (err == nil || !test.want.MatchString(err.Error())):
+				t.Errorf("got error %v, want %s", err, test.want)
+			}
+		})
+	}
+}
+
+func TestClientMaxResponseBytes(t *testing.T) {
+	const body = `{"url_token":"abc"}`
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, body)
+	}))
+	defer server.Close()
+
+	c := New(server.Client(), server.URL, "")
+	c.MaxResponseBytes = int64(len(body))
+	if _, err := c.GetPush(context.Background(), "abc"); err != nil {
+		t.Errorf("body of the size of the limit: unexpected error: %s", err)
+	}
+	c.MaxResponseBytes--
+	if _, err := c.GetPush(context.Background(), "abc"); err == nil || !strings.Contains(err.Error(), "larger than the limit of 18 bytes") {
+		t.Errorf("body over the limit: got error %v", err)
+	}
+}
+
+func TestClientCompressRequests(t *testing.T) {
+	large := strings.Repeat("secret ", CompressMinBytes)
+	for name, test := range map[string]struct {
+		password     string
+		decompresses bool
+		want         []string
+	}{
+		"small":                 {"secret", true, []string{""}},
+		"large":                 {large, true, []string{"gzip"}},
+		"large without support": {large, false, []string{"gzip", ""}},
+	} {
+		t.Run(name, func(t *testing.T) {
+			var encodings []string
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				encoding := r.Header.Get("Content-Encoding")
+				encodings = append(encodings, encoding)
+				if encoding == "gzip" && !test.decompresses {
+					w.WriteHeader(http.StatusUnsupportedMediaType)
+					return
+				}
+				body := io.Reader(r.Body)
+				if encoding == "gzip" {
+					reader, err := gzip.NewReader(r.Body)
+					if err != nil {
+						t.Fatal(err)
+					}
+					body = reader
+				}
+				var payload Payload
+				if err := json.NewDecoder(body).Decode(&payload); err != nil || payload.Password != test.password {
+					t.Errorf("got payload of %d bytes, %v", len(payload.Password), err)
+				}
+				fmt.Fprint(w, `{"url_token":"abc"}`)
+			}))
+			defer server.Close()
+
+			c := New(server.Client(), server.URL, "")
+			c.CompressRequests = true
+			if _, err := c.CreatePush(context.Background(), CurrentAPI, Payload{Password: test.password}); err != nil {
+				t.Fatal(err)
+			}
+			if !slices.Equal(encodings, test.want) {
+				t.Errorf("got requests with encodings %q, want %q", encodings, test.want)
+			}
+		})
+	}
+}
+
+func TestClientCancelled(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		t.Errorf("%s %s: got a request with a cancelled context", r.Method, r.URL.Path)
+	}))
+	defer server.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	c := New(server.Client(), server.URL, "")
+
+	for name, call := range map[string]func() error{
+		"CreatePush": func() error {
+			_, err := c.CreatePush(ctx, CurrentAPI, Payload{Password: "secret"})
+			return err
+		},
+		"GetPush":    func() error { _, err := c.GetPush(ctx, "abc"); return err },
+		"ExpirePush": func() error { return c.ExpirePush(ctx, "abc") },
+		"ListPushes": func() error { _, err := c.ListPushes(ctx, "active"); return err },
+		"Audit":      func() error { _, err := c.Audit(ctx, "abc"); return err },
+		"RouteExists": func() error {
+			_, err := c.RouteExists(ctx, "/f/active.json")
+			return err
+		},
+		"CheckCredentials": func() error { return c.CheckCredentials(ctx) },
+		"Version":          func() error { _, err := c.Version(ctx); return err },
+	} {
+		if err := call(); !errors.Is(err, context.Canceled) {
+			t.Errorf("%s: got error %v, want %v", name, err, context.Canceled)
+		}
+	}
+}
+
+func TestClientPushURL(t *testing.T) {
+	c := New(http.DefaultClient, "https://pwpush.com", "")
+
+	for locale, want := range map[string]string{
+		"":   "https://pwpush.com/p/a%2Fb",
+		"de": "https://pwpush.com/p/a%2Fb?locale=de",
+	} {
+		if got, err := c.PushURL("a/b", locale); err != nil || got != want {
+			t.Errorf("%q: got %q, %v, want %q", locale, got, err, want)
+		}
+	}
+}
+
+func TestClientLocales(t *testing.T) {
+	for name, test := range map[string]struct {
+		page string
+		want map[string]string
+	}{
+		"menu": {
+			page: `<ul class="dropdown-menu">
+  <li><a class="dropdown-item" href="/?locale=fr">Français</a></li>
+  <li><a class="dropdown-item" href="https://pwpush.example/?locale=pt-BR">
+    Português <span>(Brasil)</span>
+  </a></li>
+  <li><a href="/faq">FAQ</a></li>
+  <li><a href="/?locale=../etc">Invalid</a></li>
+</ul>`,
+			want: map[string]string{"fr": "Français", "pt-BR": "Português (Brasil)"},
+		},
+		"no menu": {page: `<html><body><a href="/login">Sign in</a></body></html>`, want: map[string]string{}},
+	} {
+		t.Run(name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if r.URL.Path != "/" || r.Header.Get("Accept") != "text/html" {
+					http.NotFound(w, r)
+					return
+				}
+				w.Header().Set("Content-Type", "text/html; charset=utf-8")
+				fmt.Fprint(w, test.page)
+			}))
+			defer server.Close()
+
+			locales, err := New(server.Client(), server.URL, "").Locales(context.Background())
+			if err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+			if fmt.Sprint(locales) != fmt.Sprint(test.want) {
+				t.Errorf("got locales %v, want %v", locales, test.want)
+			}
+		})
+	}
+}
+
+func TestClientPushForm(t *testing.T) {
+	for name, test := range map[string]struct {
+		page string
+		want PushForm
+	}{
+		"form": {
+			page: `<form action="/p" method="post">
+  <textarea class="form-control" name="password[payload]" maxlength="1048576"></textarea>
+  <input type="range" name="password[expire_after_days]" min="1" max="90" value="3">
+  <input type="range" name="password[expire_after_views]" min="1" max="100" value="10" />
+  <input type="checkbox" name="password[retrieval_step]" value="1">
+</form>`,
+			want: PushForm{ExpireAfterDays: 3, MaxExpireAfterDays: 90, ExpireAfterViews: 10, MaxExpireAfterViews: 100, MaxPayloadBytes: 1048576},
+		},
+		"no form": {page: `<html><body><a href="/login">Sign in</a></body></html>`},
+	} {
+		t.Run(name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.Header().Set("Content-Type", "text/html; charset=utf-8")
+				fmt.Fprint(w, test.page)
+			}))
+			defer server.Close()
+
+			form, err := New(server.Client(), server.URL, "").PushForm(context.Background())
+			if err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+			if form != test.want {
+				t.Errorf("got form %+v, want %+v", form, test.want)
+			}
+		})
+	}
+}
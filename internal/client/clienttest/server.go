@@ -0,0 +1,257 @@
+// Copyright (c) Plex, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+// Package clienttest provides a fake pwpusher service for tests of the code
+// using the client package, without network access beyond the loopback
+// interface.
+package clienttest
+
+import (
+	"encoding/json"
+	"fmt"
+	"html"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"terraform-provider-pwpusher/internal/client"
+	"time"
+)
+
+// The expiration settings of the pushes of the fake service that do not set
+// their own, unless its Form has others, the defaults of the pwpusher app.
+const (
+	DefaultExpireAfterDays  = 7
+	DefaultExpireAfterViews = 5
+)
+
+// auditViewKindDelete is the audit log kind of a manual deletion of a push.
+const auditViewKindDelete = 1
+
+// Server is a fake pwpusher service keeping its pushes in memory. It serves
+// the version, the text pushes with their audit logs and the text dashboards,
+// accepting any credentials.
+type Server struct {
+	*httptest.Server
+
+	// Locales are the locales of the language menu of the home page, keyed
+	// by locale code with the name of the language as value. The service
+	// has no home page when there are none.
+	Locales map[string]string
+	// Form are the settings of the new push form of the home page, whose
+	// defaults are those of the pushes. The service has no form when they
+	// are zero.
+	Form client.PushForm
+
+	mu     sync.Mutex
+	pushes map[string]*fakePush
+	// tokens lists the tokens of the pushes in the order of their creation,
+	// the order of the dashboards.
+	tokens []string
+}
+
+// fakePush is a push of the fake service with its payload and audit log.
+type fakePush struct {
+	push    client.Push
+	payload client.Payload
+	views   []client.AuditView
+}
+
+// NewServer starts and returns a fake service. The caller should call Close
+// when finished, to shut it down.
+func NewServer() *Server {
+	s := &Server{pushes: map[string]*fakePush{}}
+	s.Server = httptest.NewServer(http.HandlerFunc(s.serveHTTP))
+	return s
+}
+
+// Client returns a client of the service for the account accountID, empty
+// for the default account.
+func (s *Server) Client(accountID string) client.PushClient {
+	return client.New(s.Server.Client(), s.URL, accountID)
+}
+
+// Payload returns the settings the push with token was created with.
+func (s *Server) Payload(token string) (client.Payload, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	p, ok := s.pushes[token]
+	if !ok {
+		return client.Payload{}, false
+	}
+	return p.payload, true
+}
+
+// Push returns the push with token as the service reports it.
+func (s *Server) Push(token string) (client.Push, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	p, ok := s.pushes[token]
+	if !ok {
+		return client.Push{}, false
+	}
+	return p.push, true
+}
+
+// View records a retrieval of the push with token, as by a recipient opening
+// its URL, and reports whether the push could be viewed.
+func (s *Server) View(token string) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	p, ok := s.pushes[token]
+	if !ok || p.push.Expired {
+		return false
+	}
+	s.view(p, client.AuditViewKindView)
+	return true
+}
+
+// view adds a view of kind to p, expiring it on its last view or on a manual
+// deletion. s.mu must be held.
+func (s *Server) view(p *fakePush, kind int) {
+	now := time.Now().UTC().Format(time.RFC3339)
+	p.views = append(p.views, client.AuditView{Successful: true, CreatedAt: now, Kind: kind})
+	p.push.UpdatedAt = now
+	if kind == client.AuditViewKindView {
+		p.push.ViewsRemaining--
+	}
+	if kind != client.AuditViewKindView || p.push.ViewsRemaining <= 0 {
+		p.push.Expired = true
+		p.push.ExpiredAt = now
+	}
+}
+
+func (s *Server) serveHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	path := r.URL.Path
+	switch {
+	case r.Method == http.MethodGet && path == "/api/v1/version.json":
+		writeJSON(w, http.StatusOK, client.Version{ApplicationVersion: "clienttest", ApiVersion: "1.0", Edition: "oss"})
+	case r.Method == http.MethodGet && path == "/" && (len(s.Locales) > 0 || s.Form != (client.PushForm{})):
+		s.home(w)
+	case r.Method == http.MethodPost && path == "/p.json":
+		s.create(w, r)
+	case r.Method == http.MethodGet && (path == "/p/active.json" || path == "/p/expired.json"):
+		s.dashboard(w, r, path == "/p/expired.json")
+	case strings.HasPrefix(path, "/p/") && strings.HasSuffix(path, "/audit.json") && r.Method == http.MethodGet:
+		p, ok := s.pushes[strings.TrimSuffix(strings.TrimPrefix(path, "/p/"), "/audit.json")]
+		if !ok {
+			writeJSON(w, http.StatusNotFound, map[string]string{"error": "Not Found"})
+			return
+		}
+		writeJSON(w, http.StatusOK, client.AuditLog{Views: append([]client.AuditView{}, p.views...)})
+	case strings.HasPrefix(path, "/p/") && strings.HasSuffix(path, ".json"):
+		p, ok := s.pushes[strings.TrimSuffix(strings.TrimPrefix(path, "/p/"), ".json")]
+		if !ok {
+			writeJSON(w, http.StatusNotFound, map[string]string{"error": "Not Found"})
+			return
+		}
+		switch r.Method {
+		case http.MethodGet:
+			if !p.push.Expired {
+				s.view(p, client.AuditViewKindView)
+			}
+		case http.MethodDelete:
+			if !p.push.Expired {
+				s.view(p, auditViewKindDelete)
+			}
+		default:
+			writeJSON(w, http.StatusMethodNotAllowed, map[string]string{"error": "Method Not Allowed"})
+			return
+		}
+		writeJSON(w, http.StatusOK, p.push)
+	default:
+		writeJSON(w, http.StatusNotFound, map[string]string{"error": "Not Found"})
+	}
+}
+
+// create serves a request creating a push.
+func (s *Server) create(w http.ResponseWriter, r *http.Request) {
+	var payload client.Payload
+	if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": err.Error()})
+		return
+	}
+	if payload.Password == "" {
+		writeJSON(w, http.StatusUnprocessableEntity, map[string]any{"errors": map[string][]string{"payload": {"can't be blank"}}})
+		return
+	}
+
+	days, views := DefaultExpireAfterDays, DefaultExpireAfterViews
+	if s.Form.ExpireAfterDays > 0 {
+		days = s.Form.ExpireAfterDays
+	}
+	if s.Form.ExpireAfterViews > 0 {
+		views = s.Form.ExpireAfterViews
+	}
+	if payload.ExpireAfterDays > 0 {
+		days = payload.ExpireAfterDays
+	}
+	if payload.ExpireAfterViews > 0 {
+		views = payload.ExpireAfterViews
+	}
+	token := fmt.Sprintf("token%d", len(s.tokens)+1)
+	now := time.Now().UTC().Format(time.RFC3339)
+	s.pushes[token] = &fakePush{
+		payload: payload,
+		push: client.Push{
+			ID:                token,
+			ExpireAfterDays:   days,
+			ExpireAfterViews:  views,
+			CreatedAt:         now,
+			UpdatedAt:         now,
+			DeletableByViewer: payload.DeletableByViewer,
+			RetrievalStep:     payload.RetrievalStep,
+			DaysRemaining:     days,
+			ViewsRemaining:    views,
+			Name:              payload.Name,
+			Note:              payload.Note,
+		},
+	}
+	s.tokens = append(s.tokens, token)
+	writeJSON(w, http.StatusCreated, s.pushes[token].push)
+}
+
+// home serves the home page with its language menu and new push form.
+func (s *Server) home(w http.ResponseWriter) {
+	var page strings.Builder
+	page.WriteString("<!DOCTYPE html>\n<html><body><ul class=\"dropdown-menu\">\n")
+	for code, name := range s.Locales {
+		fmt.Fprintf(&page, "<li><a class=\"dropdown-item\" href=\"/?locale=%s\">%s</a></li>\n", html.EscapeString(code), html.EscapeString(name))
+	}
+	page.WriteString("</ul>\n")
+	if s.Form != (client.PushForm{}) {
+		page.WriteString("<form action=\"/p\" method=\"post\">\n")
+		fmt.Fprintf(&page, "<textarea name=\"password[payload]\" maxlength=\"%d\"></textarea>\n", s.Form.MaxPayloadBytes)
+		fmt.Fprintf(&page, "<input type=\"range\" name=\"password[expire_after_days]\" min=\"1\" max=\"%d\" value=\"%d\">\n", s.Form.MaxExpireAfterDays, s.Form.ExpireAfterDays)
+		fmt.Fprintf(&page, "<input type=\"range\" name=\"password[expire_after_views]\" min=\"1\" max=\"%d\" value=\"%d\">\n", s.Form.MaxExpireAfterViews, s.Form.ExpireAfterViews)
+		page.WriteString("</form>\n")
+	}
+	page.WriteString("</body></html>\n")
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	w.WriteHeader(http.StatusOK)
+	_, _ = w.Write([]byte(page.String()))
+}
+
+// dashboard serves a page of the active or expired dashboard. Every push is
+// on the first page.
+func (s *Server) dashboard(w http.ResponseWriter, r *http.Request, expired bool) {
+	pushes := []client.Push{}
+	if page := r.URL.Query().Get("page"); page == "" || page == "1" {
+		for _, token := range s.tokens {
+			if p := s.pushes[token]; p.push.Expired == expired {
+				pushes = append(pushes, p.push)
+			}
+		}
+	}
+	writeJSON(w, http.StatusOK, pushes)
+}
+
+// writeJSON writes a response with status and the JSON encoding of body.
+func writeJSON(w http.ResponseWriter, status int, body any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(body)
+}
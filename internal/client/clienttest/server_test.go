@@ -0,0 +1,57 @@
+// Copyright (c) Plex, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package clienttest
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"terraform-provider-pwpusher/internal/client"
+	"testing"
+)
+
+func TestServer(t *testing.T) {
+	server := NewServer()
+	defer server.Close()
+
+	ctx := context.Background()
+	c := server.Client("")
+
+	push, err := c.CreatePush(ctx, client.CurrentAPI, client.Payload{Password: "one", Kind: "text"})
+	if err != nil || push.ID != "token1" || push.ViewsRemaining != DefaultExpireAfterViews {
+		t.Fatalf("CreatePush: got %+v, %v", push, err)
+	}
+
+	// Every retrieval is a view, expiring the push on the last one.
+	for views := DefaultExpireAfterViews - 1; views >= 0; views-- {
+		push, err := c.GetPush(ctx, "token1")
+		if err != nil || push.ViewsRemaining != views || push.Expired != (views == 0) {
+			t.Fatalf("GetPush: got %+v, %v, want %d views remaining", push, err, views)
+		}
+	}
+	if server.View("token1") {
+		t.Error("View: viewed an expired push")
+	}
+	if auditLog, err := c.Audit(ctx, "token1"); err != nil || len(auditLog.Views) != DefaultExpireAfterViews {
+		t.Errorf("Audit: got %+v, %v", auditLog, err)
+	}
+
+	if _, err := c.CreatePush(ctx, client.CurrentAPI, client.Payload{Password: "two", Kind: "text"}); err != nil {
+		t.Fatal(err)
+	}
+	if active, err := c.ListPushes(ctx, "active"); err != nil || len(active) != 1 || active[0].ID != "token2" {
+		t.Errorf("ListPushes: got active %+v, %v", active, err)
+	}
+	if err := c.ExpirePush(ctx, "token2"); err != nil {
+		t.Fatal(err)
+	}
+	if expired, err := c.ListPushes(ctx, "expired"); err != nil || len(expired) != 2 {
+		t.Errorf("ListPushes: got expired %+v, %v", expired, err)
+	}
+
+	var respErr *client.ResponseError
+	if err := c.ExpirePush(ctx, "missing"); !errors.As(err, &respErr) || respErr.StatusCode != http.StatusNotFound {
+		t.Errorf("ExpirePush of a missing push: got %v, want a not found response error", err)
+	}
+}
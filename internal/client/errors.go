@@ -0,0 +1,196 @@
+// Copyright (c) Plex, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package client
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+)
+
+// Errors a *ResponseError matches with errors.Is, by the status of the
+// response.
+var (
+	// ErrNotFound is a request for a push or route the service does not
+	// have.
+	ErrNotFound = errors.New("not found")
+	// ErrUnauthorized is a request the service rejects the credentials of.
+	ErrUnauthorized = errors.New("unauthorized")
+	// ErrRateLimited is a request refused for exceeding the rate limit.
+	ErrRateLimited = errors.New("rate limited")
+	// ErrValidation is a request the service rejects the fields of. The
+	// error is also a *ValidationError when the service tells which ones.
+	ErrValidation = errors.New("validation failed")
+)
+
+// ErrEncoding is a push the client could not encode as JSON. The errors of
+// encoding/json quote the values they fail on, so they are not returned.
+var ErrEncoding = errors.New("unable to encode the push as JSON")
+
+// ResponseError is returned when the pwpusher service answers a request with
+// an unexpected status code.
+type ResponseError struct {
+	Path       string
+	StatusCode int
+	Status     string
+	// Message is the error message of the response body, in the language
+	// of the Accept-Language header of the request, if any.
+	Message string
+	// Validation holds the errors of the fields of the request when the
+	// service rejects them, nil otherwise.
+	Validation *ValidationError
+}
+
+// newResponseError returns the error of a response to path with the status
+// of res and body.
+func newResponseError(path string, res *http.Response, body []byte) *ResponseError {
+	message, fields := parseErrorBody(body)
+	err := &ResponseError{Path: path, StatusCode: res.StatusCode, Status: res.Status, Message: message}
+	if len(fields) > 0 && (res.StatusCode == http.StatusBadRequest || res.StatusCode == http.StatusUnprocessableEntity) {
+		err.Validation = &ValidationError{Fields: fields}
+	}
+	return err
+}
+
+func (e *ResponseError) Error() string {
+	return fmt.Sprintf("unexpected response from %s: %s", e.Path, e.Reason())
+}
+
+// Reason returns the status of the response, followed by its error message
+// when it has one.
+func (e *ResponseError) Reason() string {
+	switch {
+	case e.Message != "":
+		return e.Status + ": " + e.Message
+	case e.Validation != nil:
+		return e.Status + ": " + e.Validation.Error()
+	}
+	return e.Status
+}
+
+// Is reports whether target is the error of the status of the response.
+func (e *ResponseError) Is(target error) bool {
+	switch target {
+	case ErrNotFound:
+		return e.StatusCode == http.StatusNotFound
+	case ErrUnauthorized:
+		return e.StatusCode == http.StatusUnauthorized || e.StatusCode == http.StatusForbidden
+	case ErrRateLimited:
+		return e.StatusCode == http.StatusTooManyRequests
+	case ErrValidation:
+		return e.StatusCode == http.StatusBadRequest || e.StatusCode == http.StatusUnprocessableEntity
+	}
+	return false
+}
+
+// Unwrap returns the validation errors of the response, if any.
+func (e *ResponseError) Unwrap() error {
+	if e.Validation == nil {
+		return nil
+	}
+	return e.Validation
+}
+
+// ValidationError lists the fields of a request that the service rejected.
+type ValidationError struct {
+	// Fields maps the names of the JSON fields of the request to the
+	// messages of their errors.
+	Fields map[string][]string
+}
+
+func (e *ValidationError) Error() string {
+	fields := make([]string, 0, len(e.Fields))
+	for field := range e.Fields {
+		fields = append(fields, field)
+	}
+	sort.Strings(fields)
+	messages := make([]string, 0, len(fields))
+	for _, field := range fields {
+		messages = append(messages, field+" "+strings.Join(e.Fields[field], ", "))
+	}
+	return strings.Join(messages, "; ")
+}
+
+// Is reports whether target is ErrValidation.
+func (e *ValidationError) Is(target error) bool {
+	return target == ErrValidation
+}
+
+// parseErrorBody returns the error message of the JSON body of an error
+// response, which the service sets as either error or message, and the
+// errors of the fields of the request listed in errors, if any.
+func parseErrorBody(body []byte) (string, map[string][]string) {
+	var response struct {
+		Error   string          `json:"error"`
+		Message string          `json:"message"`
+		Errors  json.RawMessage `json:"errors"`
+	}
+	if err := json.Unmarshal(body, &response); err != nil {
+		return "", nil
+	}
+	message := response.Error
+	if message == "" {
+		message = response.Message
+	}
+	// Only an object of the fields tells which ones were rejected, a list
+	// of full messages does not.
+	var fields map[string][]string
+	if err := json.Unmarshal(response.Errors, &fields); err != nil {
+		return message, nil
+	}
+	return message, fields
+}
+
+// RejectedCredentials returns the response error of err when it is the
+// service rejecting the credentials of the client.
+func RejectedCredentials(err error) (*ResponseError, bool) {
+	var respErr *ResponseError
+	if errors.Is(err, ErrUnauthorized) && errors.As(err, &respErr) {
+		return respErr, true
+	}
+	return nil, false
+}
+
+// redactedSecret replaces the secrets of a push in the messages of errors.
+const redactedSecret = "***"
+
+// secretError is an error whose message is scrubbed of the secrets of a
+// push, such as an error message of the service quoting the rejected payload.
+type secretError struct {
+	err     error
+	message string
+}
+
+// RedactError returns err with each of secrets replaced in its message. The
+// error still matches what err matches with errors.Is and errors.As.
+func RedactError(err error, secrets ...string) error {
+	if err == nil {
+		return nil
+	}
+	message := err.Error()
+	redacted := false
+	for _, secret := range secrets {
+		// Replacing an empty string would insert between every character.
+		if secret == "" || !strings.Contains(message, secret) {
+			continue
+		}
+		message = strings.ReplaceAll(message, secret, redactedSecret)
+		redacted = true
+	}
+	if !redacted {
+		return err
+	}
+	return &secretError{err: err, message: message}
+}
+
+func (e *secretError) Error() string {
+	return e.message
+}
+
+func (e *secretError) Unwrap() error {
+	return e.err
+}
@@ -0,0 +1,95 @@
+// Copyright (c) Plex, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package client
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestResponseErrorIs(t *testing.T) {
+	targets := []error{ErrNotFound, ErrUnauthorized, ErrRateLimited, ErrValidation}
+	for status, want := range map[int]error{
+		http.StatusNotFound:            ErrNotFound,
+		http.StatusUnauthorized:        ErrUnauthorized,
+		http.StatusForbidden:           ErrUnauthorized,
+		http.StatusTooManyRequests:     ErrRateLimited,
+		http.StatusBadRequest:          ErrValidation,
+		http.StatusUnprocessableEntity: ErrValidation,
+		http.StatusInternalServerError: nil,
+	} {
+		res := &http.Response{StatusCode: status, Status: fmt.Sprintf("%d %s", status, http.StatusText(status))}
+		err := fmt.Errorf("wrapped: %w", newResponseError("/p.json", res, nil))
+		for _, target := range targets {
+			if got := errors.Is(err, target); got != (target == want) {
+				t.Errorf("%d: errors.Is(%v) = %t", status, target, got)
+			}
+		}
+	}
+}
+
+func TestResponseErrorValidation(t *testing.T) {
+	res := &http.Response{StatusCode: http.StatusUnprocessableEntity, Status: "422 Unprocessable Entity"}
+	err := error(newResponseError("/p.json", res, []byte(`{"errors":{"payload":["can't be blank"],"expire_after_days":["must be less than 91","must be a number"]}}`)))
+
+	var validation *ValidationError
+	if !errors.As(err, &validation) {
+		t.Fatalf("got error %v, want a validation error", err)
+	}
+	if got := len(validation.Fields["expire_after_days"]); got != 2 {
+		t.Errorf("got %d errors of expire_after_days, want 2", got)
+	}
+	if want := "unexpected response from /p.json: 422 Unprocessable Entity: expire_after_days must be less than 91, must be a number; payload can't be blank"; err.Error() != want {
+		t.Errorf("got error %q, want %q", err, want)
+	}
+
+	// Full messages do not tell the fields apart.
+	err = newResponseError("/p.json", res, []byte(`{"error":"Payload can't be blank","errors":["Payload can't be blank"]}`))
+	if !errors.Is(err, ErrValidation) || errors.As(err, &validation) {
+		t.Errorf("got error %v, want a validation failure without fields", err)
+	}
+}
+
+func TestRedactError(t *testing.T) {
+	res := &http.Response{StatusCode: http.StatusUnprocessableEntity, Status: "422 Unprocessable Entity"}
+	respErr := newResponseError("/p.json", res, []byte(`{"error":"hunter2 is a leaked password, open sesame is too short"}`))
+
+	err := RedactError(fmt.Errorf("wrapped: %w", respErr), "hunter2", "", "open sesame")
+	if got, want := err.Error(), "wrapped: unexpected response from /p.json: 422 Unprocessable Entity: *** is a leaked password, *** is too short"; got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+	var target *ResponseError
+	if !errors.Is(err, ErrValidation) || !errors.As(err, &target) {
+		t.Errorf("got error %v not matching the response error", err)
+	}
+	if other := errors.New("timeout"); RedactError(other, "hunter2") != other {
+		t.Error("got another error for an error without secrets")
+	}
+	if RedactError(nil, "hunter2") != nil {
+		t.Error("got an error for nil")
+	}
+}
+
+func TestCreatePushRedactsErrors(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusBadRequest)
+		w.Write([]byte(`{"error":"Invalid payload hunter2"}`))
+	}))
+	defer server.Close()
+
+	passphrase := "open sesame"
+	_, err := New(server.Client(), server.URL, "").CreatePush(context.Background(), CurrentAPI, Payload{Password: "hunter2", Passphrase: &passphrase})
+	if err == nil || strings.Contains(err.Error(), "hunter2") {
+		t.Errorf("got error %v", err)
+	}
+	if _, err := LegacyAPI.EncodePush(Payload{Password: "hunter2", Passphrase: &passphrase}); !errors.Is(err, ErrPassphraseUnsupported) {
+		t.Errorf("got error %v", err)
+	}
+}
@@ -0,0 +1,9 @@
+// Copyright (c) Plex, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package client
+
+// The types and paths of the API are generated from the subset of its OpenAPI
+// specification in openapi.json, the methods of Client wrap them. Adopting a
+// new endpoint or field starts with adding it to the specification.
+//go:generate go run ../openapigen -spec openapi.json -out api.gen.go -package client
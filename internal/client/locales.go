@@ -0,0 +1,93 @@
+// Copyright (c) Plex, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package client
+
+import (
+	"bytes"
+	"context"
+	"net/url"
+	"regexp"
+	"strings"
+
+	"golang.org/x/net/html"
+)
+
+// localeCode matches the locale codes of the pwpusher app, such as fr or
+// pt-BR.
+var localeCode = regexp.MustCompile(`^[a-z]{2,3}(-[A-Za-z]{2,4})?$`)
+
+// Locales returns the UI locales of the service, keyed by locale code with
+// the name of the language as value. The API does not list them, so they are
+// read from the language menu of the home page, whose entries link to the
+// page with a locale query parameter. The map is empty when the page has no
+// such menu, such as behind a custom front page.
+func (c *Client) Locales(ctx context.Context) (map[string]string, error) {
+	body, err := c.homePage(withOperation(ctx, "Locales"))
+	if err != nil {
+		return nil, err
+	}
+	return parseLocaleMenu(body), nil
+}
+
+// homePage returns the HTML home page of the service.
+func (c *Client) homePage(ctx context.Context) ([]byte, error) {
+	req, err := c.newGetRequest(ctx, homePagePath)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Accept", "text/html")
+	res, body, err := c.roundTrip(req, homePagePath)
+	if err != nil {
+		return nil, err
+	}
+	if err := c.decodeResponse(homePagePath, res, body, nil); err != nil {
+		return nil, err
+	}
+	return body, nil
+}
+
+// parseLocaleMenu returns the locales linked to by the HTML page body, keyed
+// by locale code with the text of their link as value.
+func parseLocaleMenu(body []byte) map[string]string {
+	locales := map[string]string{}
+	tokenizer := html.NewTokenizer(bytes.NewReader(body))
+	code := ""
+	var text strings.Builder
+	for {
+		switch tokenizer.Next() {
+		case html.ErrorToken:
+			return locales
+		case html.StartTagToken:
+			token := tokenizer.Token()
+			if token.Data != "a" {
+				continue
+			}
+			code = ""
+			text.Reset()
+			for _, attr := range token.Attr {
+				if attr.Key != "href" {
+					continue
+				}
+				link, err := url.Parse(attr.Val)
+				if err != nil {
+					continue
+				}
+				if locale := link.Query().Get("locale"); localeCode.MatchString(locale) {
+					code = locale
+				}
+			}
+		case html.TextToken:
+			if code != "" {
+				text.Write(tokenizer.Text())
+			}
+		case html.EndTagToken:
+			if token := tokenizer.Token(); token.Data == "a" && code != "" {
+				if name := strings.Join(strings.Fields(text.String()), " "); name != "" {
+					locales[code] = name
+				}
+				code = ""
+			}
+		}
+	}
+}
@@ -0,0 +1,159 @@
+// Copyright (c) Plex, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package client
+
+import (
+	"context"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// Metrics counts the requests of the clients given a context carrying it,
+// by operation, so that the provider can summarize what an operation of its
+// own cost.
+type Metrics struct {
+	mu         sync.Mutex
+	operations map[string]*OperationMetrics
+}
+
+// OperationMetrics are the metrics of the requests of an operation of the
+// client, named after its method.
+type OperationMetrics struct {
+	Requests int
+	// Retries counts the attempts after the first of the requests.
+	Retries int
+	// Duration is the time spent on the requests, retries included.
+	Duration time.Duration
+	// Statuses counts the responses by status code, 0 for the requests the
+	// service did not answer.
+	Statuses map[int]int
+}
+
+// NewMetrics returns empty metrics.
+func NewMetrics() *Metrics {
+	return &Metrics{operations: map[string]*OperationMetrics{}}
+}
+
+// RequestRecorder is told of the requests of a client, to emit metrics of
+// them beyond the operations of the provider. It must be safe for concurrent
+// use.
+type RequestRecorder interface {
+	// RecordRequest records a request of the operation named after the
+	// method of the client that took duration and was answered with status,
+	// 0 when the service did not answer.
+	RecordRequest(operation string, duration time.Duration, status int)
+}
+
+// metricsKey is the context key of the metrics of requests.
+type metricsKey struct{}
+
+// operationKey is the context key of the name of the operation a request
+// belongs to.
+type operationKey struct{}
+
+// WithMetrics returns a context recording the requests made with it in m.
+func WithMetrics(ctx context.Context, m *Metrics) context.Context {
+	return context.WithValue(ctx, metricsKey{}, m)
+}
+
+// withOperation returns a context whose requests belong to the operation
+// name.
+func withOperation(ctx context.Context, name string) context.Context {
+	return context.WithValue(ctx, operationKey{}, name)
+}
+
+// OperationName returns the name of the operation the requests of ctx belong
+// to, the method of the client making them, for the transports below the
+// client to describe them with. It is empty outside of the methods.
+func OperationName(ctx context.Context) string {
+	name, _ := ctx.Value(operationKey{}).(string)
+	return name
+}
+
+// operation returns the metrics of the operation of the requests of ctx,
+// with m.mu held. It returns nil with nothing held when ctx has no metrics.
+func operation(ctx context.Context) (*Metrics, *OperationMetrics) {
+	m, ok := ctx.Value(metricsKey{}).(*Metrics)
+	if !ok || m == nil {
+		return nil, nil
+	}
+	name := OperationName(ctx)
+	m.mu.Lock()
+	op, ok := m.operations[name]
+	if !ok {
+		op = &OperationMetrics{Statuses: map[int]int{}}
+		m.operations[name] = op
+	}
+	return m, op
+}
+
+// recordRequest records a request of ctx that took duration and was answered
+// with status, 0 for none.
+func recordRequest(ctx context.Context, duration time.Duration, status int) {
+	m, op := operation(ctx)
+	if m == nil {
+		return
+	}
+	defer m.mu.Unlock()
+	op.Requests++
+	op.Duration += duration
+	op.Statuses[status]++
+}
+
+// RecordRetry records another attempt of a request of ctx. The transports
+// retrying requests below the client call it.
+func RecordRetry(ctx context.Context) {
+	m, op := operation(ctx)
+	if m == nil {
+		return
+	}
+	defer m.mu.Unlock()
+	op.Retries++
+}
+
+// Summary returns the metrics by operation, empty when no request was made.
+func (m *Metrics) Summary() map[string]OperationMetrics {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	summary := make(map[string]OperationMetrics, len(m.operations))
+	for name, op := range m.operations {
+		statuses := make(map[int]int, len(op.Statuses))
+		for status, count := range op.Statuses {
+			statuses[status] = count
+		}
+		summary[name] = OperationMetrics{Requests: op.Requests, Retries: op.Retries, Duration: op.Duration, Statuses: statuses}
+	}
+	return summary
+}
+
+// Fields returns the metrics as the fields of a log entry: the totals of the
+// requests, retries and duration, and the metrics of each operation.
+func (m *Metrics) Fields() map[string]interface{} {
+	summary := m.Summary()
+	var requests, retries int
+	var duration time.Duration
+	operations := make(map[string]interface{}, len(summary))
+	for name, op := range summary {
+		requests += op.Requests
+		retries += op.Retries
+		duration += op.Duration
+		statuses := make(map[string]int, len(op.Statuses))
+		for status, count := range op.Statuses {
+			statuses[strconv.Itoa(status)] = count
+		}
+		operations[name] = map[string]interface{}{
+			"requests":    op.Requests,
+			"retries":     op.Retries,
+			"duration_ms": op.Duration.Milliseconds(),
+			"statuses":    statuses,
+		}
+	}
+	return map[string]interface{}{
+		"requests":    requests,
+		"retries":     retries,
+		"duration_ms": duration.Milliseconds(),
+		"operations":  operations,
+	}
+}
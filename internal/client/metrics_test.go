@@ -0,0 +1,97 @@
+// Copyright (c) Plex, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package client
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+// retryingTransport sends every request twice, as a transport retrying a
+// failed attempt would.
+type retryingTransport struct {
+	next http.RoundTripper
+}
+
+func (t retryingTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	resp, err := t.next.RoundTrip(req)
+	if err != nil {
+		return nil, err
+	}
+	resp.Body.Close()
+	RecordRetry(req.Context())
+	return t.next.RoundTrip(req)
+}
+
+func TestMetrics(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch r.URL.Path {
+		case "/api/v1/version.json":
+			w.Write([]byte(`{"application_version":"1.0.0"}`))
+		case "/p/active.json":
+			if r.URL.Query().Get("page") == "1" {
+				w.Write([]byte(`[{"url_token":"token1"}]`))
+				return
+			}
+			w.Write([]byte(`[]`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+			w.Write([]byte(`{"error":"Not Found"}`))
+		}
+	}))
+	defer server.Close()
+
+	metrics := NewMetrics()
+	ctx := WithMetrics(context.Background(), metrics)
+	c := New(&http.Client{Transport: retryingTransport{next: server.Client().Transport}}, server.URL, "")
+
+	if _, err := c.Version(ctx); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if _, err := c.ListPushes(ctx, "active"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if _, err := c.GetPush(ctx, "missing"); err == nil {
+		t.Fatal("expected an error for a missing push")
+	}
+	// Requests without metrics are not recorded.
+	if _, err := c.Version(context.Background()); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	summary := metrics.Summary()
+	for name, want := range map[string]OperationMetrics{
+		"Version":    {Requests: 1, Retries: 1, Statuses: map[int]int{http.StatusOK: 1}},
+		"ListPushes": {Requests: 2, Retries: 2, Statuses: map[int]int{http.StatusOK: 2}},
+		"GetPush":    {Requests: 1, Retries: 1, Statuses: map[int]int{http.StatusNotFound: 1}},
+	} {
+		got, ok := summary[name]
+		if !ok {
+			t.Errorf("%s: no metrics", name)
+			continue
+		}
+		if got.Requests != want.Requests || got.Retries != want.Retries || len(got.Statuses) != len(want.Statuses) {
+			t.Errorf("%s: got %+v, want %+v", name, got, want)
+		}
+		for status, count := range want.Statuses {
+			if got.Statuses[status] != count {
+				t.Errorf("%s: got %d responses with status %d, want %d", name, got.Statuses[status], status, count)
+			}
+		}
+		if got.Duration <= 0 {
+			t.Errorf("%s: got duration %s, want more than 0", name, got.Duration)
+		}
+	}
+	if len(summary) != 3 {
+		t.Errorf("got metrics of %d operations, want 3", len(summary))
+	}
+
+	fields := metrics.Fields()
+	if fields["requests"] != 4 || fields["retries"] != 4 {
+		t.Errorf("got totals of %v requests and %v retries, want 4 and 4", fields["requests"], fields["retries"])
+	}
+}
@@ -0,0 +1,70 @@
+// Copyright (c) Plex, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package client
+
+import (
+	"bytes"
+	"context"
+	"strconv"
+	"strings"
+
+	"golang.org/x/net/html"
+)
+
+// PushForm are the settings of the new push form of the home page of a
+// service, which shows the expiration settings of the instance. Zero fields
+// are settings the form does not have.
+type PushForm struct {
+	// ExpireAfterDays and ExpireAfterViews are the expiration settings of
+	// the pushes that do not set their own.
+	ExpireAfterDays  int
+	ExpireAfterViews int
+	// MaxExpireAfterDays and MaxExpireAfterViews are the largest expiration
+	// settings the service accepts.
+	MaxExpireAfterDays  int
+	MaxExpireAfterViews int
+	// MaxPayloadBytes is the maxlength of the payload field, the size of
+	// the largest payload the service accepts.
+	MaxPayloadBytes int64
+}
+
+// PushForm returns the settings of the new push form of the home page of the
+// service. The API does not report them. The settings are zero when the page
+// has no such form, such as behind a custom front page.
+func (c *Client) PushForm(ctx context.Context) (PushForm, error) {
+	body, err := c.homePage(withOperation(ctx, "PushForm"))
+	if err != nil {
+		return PushForm{}, err
+	}
+	return parsePushForm(body), nil
+}
+
+// parsePushForm returns the settings of the fields of the new push form of
+// the HTML page body, named like password[expire_after_days].
+func parsePushForm(body []byte) PushForm {
+	var form PushForm
+	tokenizer := html.NewTokenizer(bytes.NewReader(body))
+	for {
+		switch tokenizer.Next() {
+		case html.ErrorToken:
+			return form
+		case html.StartTagToken, html.SelfClosingTagToken:
+			token := tokenizer.Token()
+			attrs := map[string]string{}
+			for _, attr := range token.Attr {
+				attrs[attr.Key] = attr.Val
+			}
+			value, _ := strconv.Atoi(attrs["value"])
+			limit, _ := strconv.Atoi(attrs["max"])
+			switch name := attrs["name"]; {
+			case token.Data == "input" && strings.HasSuffix(name, "[expire_after_days]"):
+				form.ExpireAfterDays, form.MaxExpireAfterDays = value, limit
+			case token.Data == "input" && strings.HasSuffix(name, "[expire_after_views]"):
+				form.ExpireAfterViews, form.MaxExpireAfterViews = value, limit
+			case strings.HasSuffix(name, "[payload]"):
+				form.MaxPayloadBytes, _ = strconv.ParseInt(attrs["maxlength"], 10, 64)
+			}
+		}
+	}
+}
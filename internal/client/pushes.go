@@ -0,0 +1,217 @@
+// Copyright (c) Plex, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package client
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+)
+
+// AuditViewKindView is the audit log kind recorded for a retrieval of the
+// push, as opposed to a manual deletion.
+const AuditViewKindView = 0
+
+// API describes the differences between the APIs of pwpush releases that the
+// client adapts its requests to. Every release creates pushes at the same
+// paths, only the payloads differ.
+type API struct {
+	// LegacyPayload nests the fields of new pushes under a "password" key,
+	// like the form parameters the JSON API of releases before 1.0 mirrors.
+	// Those releases do not know the kind and passphrase fields.
+	LegacyPayload bool
+}
+
+// The APIs of the pwpush releases from 1.0 on, and of the older ones.
+var (
+	CurrentAPI = API{}
+	LegacyAPI  = API{LegacyPayload: true}
+)
+
+// Errors returned when a push uses a feature the API of the service does not
+// support.
+var (
+	ErrPassphraseUnsupported = errors.New("the legacy API of the pwpusher service does not support passphrases")
+	ErrAccountUnsupported    = errors.New("the legacy API of the pwpusher service does not support accounts")
+)
+
+// EncodePush returns the request body creating payload as a new push.
+func (a API) EncodePush(payload Payload) ([]byte, error) {
+	if !a.LegacyPayload {
+		return encodeJSON(payload)
+	}
+	if payload.Passphrase != nil {
+		return nil, ErrPassphraseUnsupported
+	}
+	if payload.AccountID != "" {
+		return nil, ErrAccountUnsupported
+	}
+	legacy := struct {
+		Password          string `json:"payload"`
+		ExpireAfterDays   int    `json:"expire_after_days,omitempty"`
+		ExpireAfterViews  int    `json:"expire_after_views,omitempty"`
+		DeletableByViewer bool   `json:"deletable_by_viewer"`
+		RetrievalStep     bool   `json:"retrieval_step"`
+	}{payload.Password, payload.ExpireAfterDays, payload.ExpireAfterViews, payload.DeletableByViewer, payload.RetrievalStep}
+	return encodeJSON(map[string]any{"password": legacy})
+}
+
+// encodeJSON returns the JSON encoding of the request body v, or ErrEncoding.
+func encodeJSON(v any) ([]byte, error) {
+	body, err := json.Marshal(v)
+	if err != nil {
+		return nil, ErrEncoding
+	}
+	return body, nil
+}
+
+// CreatePush creates a push of payload with api. The payload and passphrase
+// are redacted from the messages of the errors returned, as the service may
+// quote them when rejecting them.
+func (c *Client) CreatePush(ctx context.Context, api API, payload Payload) (Push, error) {
+	push, err := c.createPush(ctx, api, payload)
+	if err != nil {
+		passphrase := ""
+		if payload.Passphrase != nil {
+			passphrase = *payload.Passphrase
+		}
+		return Push{}, RedactError(err, payload.Password, passphrase)
+	}
+	return push, nil
+}
+
+func (c *Client) createPush(ctx context.Context, api API, payload Payload) (Push, error) {
+	ctx = withOperation(ctx, "CreatePush")
+	body, err := api.EncodePush(payload)
+	if err != nil {
+		return Push{}, err
+	}
+	var push Push
+	compress := c.CompressRequests && len(body) >= CompressMinBytes
+	err = c.post(ctx, createPushPath, body, compress, &push)
+	// Services that do not decompress requests may refuse compressed ones
+	// with 415 Unsupported Media Type.
+	var respErr *ResponseError
+	if compress && errors.As(err, &respErr) && respErr.StatusCode == http.StatusUnsupportedMediaType {
+		tflog.SubsystemDebug(ctx, LogSubsystem, "Sending the request again uncompressed", map[string]interface{}{
+			"path": createPushPath,
+		})
+		err = c.post(ctx, createPushPath, body, false, &push)
+	}
+	if err != nil {
+		return Push{}, err
+	}
+	return push, nil
+}
+
+// post sends the JSON body to path, compressed with gzip when compress is
+// set, and decodes the JSON response body into out.
+func (c *Client) post(ctx context.Context, path string, body []byte, compress bool, out any) error {
+	endpoint, err := c.Endpoint(path)
+	if err != nil {
+		return err
+	}
+	if compress {
+		var compressed bytes.Buffer
+		writer := gzip.NewWriter(&compressed)
+		if _, err := writer.Write(body); err != nil {
+			return err
+		}
+		if err := writer.Close(); err != nil {
+			return err
+		}
+		body = compressed.Bytes()
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Accept", "application/json")
+	if compress {
+		req.Header.Set("Content-Encoding", "gzip")
+	}
+	return c.do(req, path, out)
+}
+
+// GetPush returns the push with token. Retrieving a push counts as one of its
+// views.
+func (c *Client) GetPush(ctx context.Context, token string) (Push, error) {
+	// The view changes the push on the dashboards.
+	defer c.Cache.clear()
+	ctx = withOperation(ctx, "GetPush")
+	ctx = tflog.SubsystemSetField(ctx, LogSubsystem, "token", token)
+	var push Push
+	err := c.getJSON(ctx, getPushPath(token), &push)
+	return push, err
+}
+
+// ExpirePush expires the push with token, so that it cannot be viewed
+// anymore.
+func (c *Client) ExpirePush(ctx context.Context, token string) error {
+	ctx = withOperation(ctx, "ExpirePush")
+	ctx = tflog.SubsystemSetField(ctx, LogSubsystem, "token", token)
+	path := expirePushPath(token)
+	endpoint, err := c.Endpoint(path)
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodDelete, endpoint, nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Accept", "application/json")
+	return c.do(req, path, nil)
+}
+
+// ListPushes returns every push of the authenticated account on the given
+// dashboard, either "active" or "expired", following pagination until the
+// service returns an empty page.
+func (c *Client) ListPushes(ctx context.Context, dashboard string) ([]Push, error) {
+	ctx = withOperation(ctx, "ListPushes")
+	path, ok := map[string]string{"active": listActivePushesPath, "expired": listExpiredPushesPath}[dashboard]
+	if !ok {
+		return nil, fmt.Errorf("unknown dashboard %q, expected active or expired", dashboard)
+	}
+	var pushes []Push
+	for page := 1; ; page++ {
+		var batch []Push
+		if err := c.getCachedJSON(ctx, fmt.Sprintf("%s?page=%d", path, page), &batch); err != nil {
+			return nil, err
+		}
+		if len(batch) == 0 {
+			return pushes, nil
+		}
+		pushes = append(pushes, batch...)
+	}
+}
+
+// Audit returns the audit log of the push with token.
+func (c *Client) Audit(ctx context.Context, token string) (AuditLog, error) {
+	ctx = withOperation(ctx, "Audit")
+	ctx = tflog.SubsystemSetField(ctx, LogSubsystem, "token", token)
+	var auditLog AuditLog
+	err := c.getJSON(ctx, auditPath(token), &auditLog)
+	return auditLog, err
+}
+
+// PushURL returns the URL recipients open to view the push with token,
+// showing the app in locale unless it is empty.
+func (c *Client) PushURL(token, locale string) (string, error) {
+	pushURL, err := c.Endpoint(viewPushPath(token))
+	if err != nil {
+		return "", err
+	}
+	if locale != "" {
+		pushURL += "?" + url.Values{"locale": {locale}}.Encode()
+	}
+	return pushURL, nil
+}
@@ -0,0 +1,305 @@
+// Copyright (c) Plex, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+// Command openapigen generates the types and paths of the pwpusher API from
+// its OpenAPI specification, for the client package to wrap:
+//
+//	openapigen -spec openapi.json -out api.gen.go -package client
+//
+// It supports the parts of OpenAPI 3.0 the specification uses: object
+// schemas of strings, integers, booleans, arrays and references to other
+// schemas, and operations with path parameters.
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"go/format"
+	"log"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+func main() {
+	specPath := flag.String("spec", "openapi.json", "the OpenAPI specification")
+	out := flag.String("out", "api.gen.go", "the Go file to write")
+	pkg := flag.String("package", "client", "the package of the Go file")
+	flag.Parse()
+
+	spec, err := os.ReadFile(*specPath)
+	if err != nil {
+		log.Fatal(err)
+	}
+	code, err := generate(spec, filepath.Base(*specPath), *pkg)
+	if err != nil {
+		log.Fatalf("%s: %s", *specPath, err)
+	}
+	if err := os.WriteFile(*out, code, 0o644); err != nil {
+		log.Fatal(err)
+	}
+}
+
+// specification is the part of an OpenAPI specification the generator
+// reads.
+type specification struct {
+	Paths      map[string]pathItem `json:"paths"`
+	Components struct {
+		Schemas orderedSchemas `json:"schemas"`
+	} `json:"components"`
+}
+
+// pathItem are the operations on a path, with the parameters they share.
+type pathItem struct {
+	Parameters []parameter `json:"parameters"`
+	Get        *operation  `json:"get"`
+	Post       *operation  `json:"post"`
+	Delete     *operation  `json:"delete"`
+}
+
+type operation struct {
+	OperationID string      `json:"operationId"`
+	Summary     string      `json:"summary"`
+	Parameters  []parameter `json:"parameters"`
+}
+
+type parameter struct {
+	Name string `json:"name"`
+	In   string `json:"in"`
+}
+
+type schema struct {
+	Description string         `json:"description"`
+	Type        string         `json:"type"`
+	Ref         string         `json:"$ref"`
+	Nullable    bool           `json:"nullable"`
+	Items       *schema        `json:"items"`
+	Required    []string       `json:"required"`
+	Properties  orderedSchemas `json:"properties"`
+	GoName      string         `json:"x-go-name"`
+}
+
+// namedSchema is a schema with the name it has in its object.
+type namedSchema struct {
+	name string
+	schema
+}
+
+// orderedSchemas are the schemas of a JSON object in the order of its keys,
+// which is the order of the generated types and fields.
+type orderedSchemas []namedSchema
+
+func (s *orderedSchemas) UnmarshalJSON(data []byte) error {
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	if _, err := decoder.Token(); err != nil {
+		return err
+	}
+	for decoder.More() {
+		key, err := decoder.Token()
+		if err != nil {
+			return err
+		}
+		named := namedSchema{name: key.(string)}
+		if err := decoder.Decode(&named.schema); err != nil {
+			return err
+		}
+		*s = append(*s, named)
+	}
+	return nil
+}
+
+// generate returns the Go source of package pkg with the types of the
+// schemas and the paths of the operations of spec, read from source.
+func generate(spec []byte, source, pkg string) ([]byte, error) {
+	var s specification
+	if err := json.Unmarshal(spec, &s); err != nil {
+		return nil, err
+	}
+
+	var code bytes.Buffer
+	code.WriteString("// Copyright (c) Plex, Inc.\n// SPDX-License-Identifier: MPL-2.0\n\n")
+	fmt.Fprintf(&code, "// Code generated by openapigen from %s. DO NOT EDIT.\n\npackage %s\n\nimport \"net/url\"\n", source, pkg)
+
+	for _, named := range s.Components.Schemas {
+		if err := writeType(&code, named); err != nil {
+			return nil, fmt.Errorf("schema %s: %w", named.name, err)
+		}
+	}
+	if err := writePaths(&code, s.Paths); err != nil {
+		return nil, err
+	}
+
+	formatted, err := format.Source(code.Bytes())
+	if err != nil {
+		return nil, fmt.Errorf("invalid generated code: %w", err)
+	}
+	return formatted, nil
+}
+
+// writeType writes the struct type of the object schema named.
+func writeType(code *bytes.Buffer, named namedSchema) error {
+	if named.Type != "object" {
+		return fmt.Errorf("unsupported type %q, only objects are", named.Type)
+	}
+	fmt.Fprintf(code, "\n// %s is %s\ntype %s struct {\n", named.name, lowerFirst(named.Description), named.name)
+	for _, property := range named.Properties {
+		goType, err := goTypeOf(property.schema)
+		if err != nil {
+			return fmt.Errorf("property %s: %w", property.name, err)
+		}
+		field := property.GoName
+		if field == "" {
+			field = goName(property.name)
+		}
+		tag := property.name
+		if !contains(named.Required, property.name) {
+			tag += ",omitempty"
+		}
+		if property.Description != "" {
+			fmt.Fprintf(code, "// %s is %s\n", field, lowerFirst(property.Description))
+		}
+		fmt.Fprintf(code, "%s %s `json:%q`\n", field, goType, tag)
+	}
+	code.WriteString("}\n")
+	return nil
+}
+
+// goTypeOf returns the Go type of the values of s.
+func goTypeOf(s schema) (string, error) {
+	if s.Ref != "" {
+		name, ok := strings.CutPrefix(s.Ref, "#/components/schemas/")
+		if !ok {
+			return "", fmt.Errorf("unsupported reference %q", s.Ref)
+		}
+		return name, nil
+	}
+	var goType string
+	switch s.Type {
+	case "string":
+		goType = "string"
+	case "integer":
+		goType = "int"
+	case "boolean":
+		goType = "bool"
+	case "array":
+		if s.Items == nil {
+			return "", fmt.Errorf("array without items")
+		}
+		items, err := goTypeOf(*s.Items)
+		if err != nil {
+			return "", err
+		}
+		return "[]" + items, nil
+	default:
+		return "", fmt.Errorf("unsupported type %q", s.Type)
+	}
+	if s.Nullable {
+		goType = "*" + goType
+	}
+	return goType, nil
+}
+
+// writePaths writes a constant with the path of every operation of paths
+// without path parameters, and a function returning it for the others.
+func writePaths(code *bytes.Buffer, paths map[string]pathItem) error {
+	type entry struct {
+		path, method string
+		params       []parameter
+		op           *operation
+	}
+	var entries []entry
+	for path, item := range paths {
+		for method, op := range map[string]*operation{"GET": item.Get, "POST": item.Post, "DELETE": item.Delete} {
+			if op != nil {
+				entries = append(entries, entry{path, method, append(append([]parameter{}, item.Parameters...), op.Parameters...), op})
+			}
+		}
+	}
+	sort.Slice(entries, func(i, j int) bool { return entries[i].op.OperationID < entries[j].op.OperationID })
+
+	code.WriteString("\n// The paths of the operations of the API without path parameters.\nconst (\n")
+	for _, e := range entries {
+		if !strings.Contains(e.path, "{") {
+			fmt.Fprintf(code, "// %sPath is the path of %s %s: %s\n%sPath = %q\n", lowerFirst(e.op.OperationID), e.method, e.op.OperationID, lowerFirst(e.op.Summary), lowerFirst(e.op.OperationID), e.path)
+		}
+	}
+	code.WriteString(")\n")
+
+	for _, e := range entries {
+		if !strings.Contains(e.path, "{") {
+			continue
+		}
+		var args, expr []string
+		rest := e.path
+		for {
+			before, after, found := strings.Cut(rest, "{")
+			if !found {
+				expr = append(expr, fmt.Sprintf("%q", rest))
+				break
+			}
+			name, after, ok := strings.Cut(after, "}")
+			if !ok || !hasPathParameter(e.params, name) {
+				return fmt.Errorf("path %s: undeclared path parameter %q", e.path, name)
+			}
+			arg := lowerFirst(goName(name))
+			args = append(args, arg)
+			if before != "" {
+				expr = append(expr, fmt.Sprintf("%q", before))
+			}
+			expr = append(expr, "url.PathEscape("+arg+")")
+			rest = after
+		}
+		if expr[len(expr)-1] == `""` {
+			expr = expr[:len(expr)-1]
+		}
+		fmt.Fprintf(code, "\n// %sPath returns the path of %s %s with %s: %s\nfunc %sPath(%s string) string {\nreturn %s\n}\n",
+			lowerFirst(e.op.OperationID), e.method, e.op.OperationID, strings.Join(args, " and "), lowerFirst(e.op.Summary),
+			lowerFirst(e.op.OperationID), strings.Join(args, ", "), strings.Join(expr, " + "))
+	}
+	return nil
+}
+
+// hasPathParameter reports whether params declare the path parameter name.
+func hasPathParameter(params []parameter, name string) bool {
+	for _, p := range params {
+		if p.Name == name && p.In == "path" {
+			return true
+		}
+	}
+	return false
+}
+
+// goName returns the exported Go name of the snake case name, such as
+// AccountID for account_id.
+func goName(name string) string {
+	var b strings.Builder
+	for _, word := range strings.Split(name, "_") {
+		if word == "id" {
+			b.WriteString("ID")
+			continue
+		}
+		b.WriteString(strings.ToUpper(word[:1]) + word[1:])
+	}
+	return b.String()
+}
+
+// lowerFirst returns s with its first letter in lower case, for the names
+// and sentences that follow others.
+func lowerFirst(s string) string {
+	if s == "" {
+		return s
+	}
+	return strings.ToLower(s[:1]) + s[1:]
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
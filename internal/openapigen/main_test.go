@@ -0,0 +1,35 @@
+// Copyright (c) Plex, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package main
+
+import (
+	"bytes"
+	"os"
+	"testing"
+)
+
+func TestGenerateUpToDate(t *testing.T) {
+	spec, err := os.ReadFile("../client/openapi.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want, err := os.ReadFile("../client/api.gen.go")
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, err := generate(spec, "openapi.json", "client")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(got, want) {
+		t.Error("internal/client/api.gen.go is out of date with openapi.json, run make generate")
+	}
+}
+
+func TestGenerateUndeclaredPathParameter(t *testing.T) {
+	spec := `{"paths": {"/p/{url_token}.json": {"get": {"operationId": "GetPush"}}}}`
+	if _, err := generate([]byte(spec), "openapi.json", "client"); err == nil {
+		t.Error("expected an error for the undeclared url_token parameter")
+	}
+}
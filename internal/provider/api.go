@@ -0,0 +1,28 @@
+// Copyright (c) Plex, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package provider
+
+import (
+	"terraform-provider-pwpusher/internal/client"
+	"time"
+)
+
+// defaultDataSourceReadTimeout is the read timeout of data sources that do
+// not configure one in their timeouts block.
+const defaultDataSourceReadTimeout = 2 * time.Minute
+
+// apiClient returns the client of the API of the service of d.
+func (d ProviderData) apiClient() client.PushClient {
+	if d.newPushClient != nil {
+		return d.newPushClient(d.client, d.url.ValueString(), d.accountID)
+	}
+	c := client.New(d.client, d.url.ValueString(), d.accountID)
+	c.StrictDecoding = d.strictDecoding
+	c.MaxResponseBytes = d.maxResponseBytes
+	c.CompressRequests = d.compressRequests
+	c.Breaker = d.breaker
+	c.Cache = d.cache
+	c.Recorder = d.recorder
+	return c
+}
@@ -0,0 +1,71 @@
+// Copyright (c) Plex, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package provider
+
+import (
+	"encoding/json"
+	"os"
+	"sync"
+	"time"
+)
+
+// Actions of the records of the audit log.
+const (
+	auditActionCreate = "create"
+	auditActionExpire = "expire"
+)
+
+// auditRecord is a line of the audit log. It never holds the payload or the
+// passphrase of a push.
+type auditRecord struct {
+	Time time.Time `json:"time"`
+	// Action is auditActionCreate or auditActionExpire.
+	Action string `json:"action"`
+	// ResourceType is the type of the resource, providers are not told the
+	// address of resources.
+	ResourceType     string `json:"resource_type"`
+	ServiceURL       string `json:"service_url"`
+	Token            string `json:"token"`
+	Name             string `json:"name,omitempty"`
+	ExpireAfterDays  int32  `json:"expire_after_days"`
+	ExpireAfterViews int32  `json:"expire_after_views"`
+	RetrievalStep    bool   `json:"retrieval_step"`
+	Passphrase       bool   `json:"passphrase"`
+	DryRun           bool   `json:"dry_run,omitempty"`
+}
+
+// auditLog appends records to the JSON lines file at path. Resources of a
+// provider share it, so that their records do not interleave.
+type auditLog struct {
+	mu   sync.Mutex
+	path string
+}
+
+// write appends record to the log, a nil log discards it.
+func (l *auditLog) write(record auditRecord) error {
+	if l == nil {
+		return nil
+	}
+	line, err := json.Marshal(record)
+	if err != nil {
+		return err
+	}
+	line = append(line, '\n')
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	// The tokens of the records give access to the pushes, so the log is
+	// only readable by its owner.
+	file, err := os.OpenFile(l.path, os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0o600)
+	if err != nil {
+		return err
+	}
+	// A single write keeps the lines of concurrent Terraform runs appending
+	// to the same file whole.
+	if _, err := file.Write(line); err != nil {
+		file.Close()
+		return err
+	}
+	return file.Close()
+}
@@ -0,0 +1,79 @@
+// Copyright (c) Plex, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package provider
+
+import (
+	"context"
+	"sort"
+	"strings"
+	"sync"
+	"terraform-provider-pwpusher/internal/client"
+
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+)
+
+// Values of the api_compatibility attribute of the provider. Automatic
+// detection is not an API of its own, so it is not in apiCompatibilities.
+const (
+	autoAPI    = "auto"
+	currentAPI = "current"
+	legacyAPI  = "legacy"
+)
+
+// apiCompatibilities maps the values of api_compatibility to the API they
+// select.
+var apiCompatibilities = map[string]client.API{
+	currentAPI: client.CurrentAPI,
+	legacyAPI:  client.LegacyAPI,
+}
+
+// apiDetection detects the API of a service on first use and remembers it
+// for the following requests.
+type apiDetection struct {
+	mu       sync.Mutex
+	detected *client.API
+}
+
+// compatibility returns the API of the service, detecting it unless the
+// provider selects one. Only releases from 1.0 on have the /api/v1
+// endpoints, older ones are sent the legacy payloads.
+func (d ProviderData) compatibility(ctx context.Context) (client.API, error) {
+	if d.apiDetection == nil {
+		return d.api, nil
+	}
+	d.apiDetection.mu.Lock()
+	defer d.apiDetection.mu.Unlock()
+	if d.apiDetection.detected != nil {
+		return *d.apiDetection.detected, nil
+	}
+
+	// Failures to reach the service are not remembered, so that the next
+	// request detects the API again.
+	current, err := d.routeExists(ctx, "/api/v1/version.json")
+	if err != nil {
+		return client.API{}, err
+	}
+	name := currentAPI
+	if !current {
+		name = legacyAPI
+	}
+	tflog.SubsystemDebug(ctx, client.LogSubsystem, "Detected the API of the pwpusher service", map[string]interface{}{
+		"url":               d.url.ValueString(),
+		"api_compatibility": name,
+	})
+	api := apiCompatibilities[name]
+	d.apiDetection.detected = &api
+	return api, nil
+}
+
+// apiCompatibilityNames returns the accepted values of api_compatibility
+// for use in messages.
+func apiCompatibilityNames() string {
+	names := []string{"`" + autoAPI + "`"}
+	for name := range apiCompatibilities {
+		names = append(names, "`"+name+"`")
+	}
+	sort.Strings(names)
+	return strings.Join(names, ", ")
+}
@@ -0,0 +1,52 @@
+// Copyright (c) Plex, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package provider
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"terraform-provider-pwpusher/internal/client"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+func TestProviderDataCompatibility(t *testing.T) {
+	for name, test := range map[string]struct {
+		status int
+		want   client.API
+	}{
+		"current":      {status: http.StatusOK, want: apiCompatibilities[currentAPI]},
+		"unauthorized": {status: http.StatusUnauthorized, want: apiCompatibilities[currentAPI]},
+		"legacy":       {status: http.StatusNotFound, want: apiCompatibilities[legacyAPI]},
+	} {
+		t.Run(name, func(t *testing.T) {
+			probes := 0
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if r.URL.Path != "/api/v1/version.json" {
+					t.Errorf("unexpected request for %s", r.URL.Path)
+				}
+				probes++
+				w.WriteHeader(test.status)
+				_, _ = w.Write([]byte(`{}`))
+			}))
+			defer server.Close()
+
+			d := ProviderData{client: server.Client(), url: types.StringValue(server.URL), apiDetection: &apiDetection{}}
+			for range 2 {
+				api, err := d.compatibility(context.Background())
+				if err != nil {
+					t.Fatal(err)
+				}
+				if api != test.want {
+					t.Errorf("got %+v, want %+v", api, test.want)
+				}
+			}
+			if probes != 1 {
+				t.Errorf("got %d probes, want 1", probes)
+			}
+		})
+	}
+}
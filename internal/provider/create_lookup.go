@@ -0,0 +1,93 @@
+// Copyright (c) Plex, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package provider
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"net"
+	"net/http"
+	"strings"
+	"terraform-provider-pwpusher/internal/client"
+)
+
+// createMarkerPrefix starts the line of the notes of authenticated pushes
+// telling them apart from the other pushes of the dashboard.
+const createMarkerPrefix = "Terraform create ID: "
+
+// errCreateUncertain is a create request that failed after the service may
+// already have created the push, which the provider could not rule out.
+var errCreateUncertain = errors.New("the pwpusher service may have created the push before the request failed")
+
+// newCreateMarker returns a marker unique to a push about to be created.
+func newCreateMarker() (string, error) {
+	id := make([]byte, 8)
+	if _, err := rand.Read(id); err != nil {
+		return "", err
+	}
+	return createMarkerPrefix + hex.EncodeToString(id), nil
+}
+
+// noteWithMarker returns note with marker on a line of its own.
+func noteWithMarker(note, marker string) string {
+	if note == "" {
+		return marker
+	}
+	return note + "\n" + marker
+}
+
+// pushLookup looks for the push a create request that failed may have
+// created, returning whether it found it.
+type pushLookup func(ctx context.Context) (client.Push, bool, error)
+
+// pushLookupKey is the context key of the pushLookup of the create requests
+// made with the context.
+type pushLookupKey struct{}
+
+// withPushLookup returns a context whose create requests are only retried
+// after lookup did not find the push of an attempt that may have succeeded.
+func withPushLookup(ctx context.Context, lookup pushLookup) context.Context {
+	return context.WithValue(ctx, pushLookupKey{}, lookup)
+}
+
+// dashboardLookup returns the lookup of the push whose note holds marker on
+// the active dashboard of pushClient.
+func dashboardLookup(pushClient client.PushClient, marker string) pushLookup {
+	return func(ctx context.Context) (client.Push, bool, error) {
+		pushes, err := pushClient.ListPushes(ctx, "active")
+		if err != nil {
+			return client.Push{}, false, err
+		}
+		for _, push := range pushes {
+			if strings.Contains(push.Note, marker) {
+				return push, true, nil
+			}
+		}
+		return client.Push{}, false, nil
+	}
+}
+
+// createMayHaveSucceeded reports whether a create request that got resp and
+// err may have been processed by the service anyway, such as one that timed
+// out or whose gateway gave up waiting for the service. Requests whose
+// connection could not be established, and refusals like 429 and 503, never
+// reached it.
+func createMayHaveSucceeded(req *http.Request, resp *http.Response, err error) bool {
+	// The only requests of the provider changing what the service holds
+	// are the ones creating pushes, expiring them is idempotent.
+	if req.Method != http.MethodPost {
+		return false
+	}
+	if err != nil {
+		var opErr *net.OpError
+		return !errors.As(err, &opErr) || opErr.Op != "dial"
+	}
+	switch resp.StatusCode {
+	case http.StatusInternalServerError, http.StatusBadGateway, http.StatusGatewayTimeout:
+		return true
+	}
+	return false
+}
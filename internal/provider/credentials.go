@@ -0,0 +1,404 @@
+// Copyright (c) Plex, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package provider
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"net/url"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/zalando/go-keyring"
+)
+
+// credentialsCommandTimeout bounds the run of credentials_command.
+const credentialsCommandTimeout = time.Minute
+
+// cliConfigUnset is the value the pwpush CLI stores for settings that were
+// never set.
+const cliConfigUnset = "Not Set"
+
+// sourcedCredentials are the URL and credentials of the provider read from
+// a source other than its configuration.
+type sourcedCredentials struct {
+	url   string
+	email string
+	token string
+}
+
+// apply sets the URL and the credentials of data that are not set from c.
+// The credentials of a source with a URL are only taken for that service, a
+// token of pwpush.com must not be sent to the service the configuration
+// names instead.
+func (c sourcedCredentials) apply(data *PwPusherProviderModel) {
+	if data.Url.IsNull() && c.url != "" {
+		data.Url = types.StringValue(c.url)
+	}
+	if c.url != "" && !sameServiceURL(c.url, data.Url.ValueString()) {
+		return
+	}
+	// Credentials are only taken as a pair, an email of the configuration
+	// with a token of another source would not authenticate.
+	if data.Email.IsNull() && data.ApiToken.IsNull() && c.email != "" && c.token != "" {
+		data.Email = types.StringValue(c.email)
+		data.ApiToken = types.StringValue(c.token)
+	}
+}
+
+// sameServiceURL reports whether the URLs a and b are those of the same
+// service, ignoring the case of their scheme and host, default ports and
+// trailing slashes.
+func sameServiceURL(a, b string) bool {
+	ua, errA := url.Parse(a)
+	ub, errB := url.Parse(b)
+	if errA != nil || errB != nil {
+		return false
+	}
+	origin := func(u *url.URL) string {
+		scheme := strings.ToLower(u.Scheme)
+		host := strings.ToLower(u.Host)
+		if port := u.Port(); (scheme == "https" && port == "443") || (scheme == "http" && port == "80") {
+			host = strings.ToLower(u.Hostname())
+		}
+		return scheme + "://" + host + strings.TrimRight(u.Path, "/")
+	}
+	return origin(ua) == origin(ub)
+}
+
+// defaultCLIConfigFile returns the path the pwpush CLI stores its
+// configuration at.
+func defaultCLIConfigFile() (string, error) {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "pwpush", "config.ini"), nil
+}
+
+// readCLIConfig reads the instance section of the INI configuration file of
+// the pwpush CLI.
+func readCLIConfig(file string) (sourcedCredentials, error) {
+	f, err := os.Open(file)
+	if err != nil {
+		return sourcedCredentials{}, err
+	}
+	defer f.Close()
+
+	var config sourcedCredentials
+	section := ""
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		switch {
+		case line == "" || strings.HasPrefix(line, "#") || strings.HasPrefix(line, ";"):
+			continue
+		case strings.HasPrefix(line, "[") && strings.HasSuffix(line, "]"):
+			section = strings.TrimSpace(line[1 : len(line)-1])
+			continue
+		}
+		if section != "instance" {
+			continue
+		}
+		key, value, ok := strings.Cut(line, "=")
+		if !ok {
+			key, value, ok = strings.Cut(line, ":")
+		}
+		if !ok {
+			return sourcedCredentials{}, fmt.Errorf("invalid line in the instance section: %q", line)
+		}
+		value = strings.TrimSpace(value)
+		if value == cliConfigUnset {
+			value = ""
+		}
+		switch strings.ToLower(strings.TrimSpace(key)) {
+		case "url":
+			config.url = value
+		case "email":
+			config.email = value
+		case "token":
+			config.token = value
+		}
+	}
+	return config, scanner.Err()
+}
+
+// applyCLIConfig sets the URL and the credentials of data that are not set
+// from the configuration file of the pwpush CLI. A missing file is only an
+// error when cli_config_file sets it.
+func applyCLIConfig(data *PwPusherProviderModel) diag.Diagnostics {
+	var diags diag.Diagnostics
+	if !data.Url.IsNull() && !data.Email.IsNull() {
+		return diags
+	}
+
+	file := data.CliConfigFile.ValueString()
+	if data.CliConfigFile.IsNull() {
+		var err error
+		if file, err = defaultCLIConfigFile(); err != nil {
+			return diags
+		}
+	}
+	config, err := readCLIConfig(file)
+	if errors.Is(err, fs.ErrNotExist) && data.CliConfigFile.IsNull() {
+		return diags
+	}
+	if err != nil {
+		diags.AddAttributeError(
+			path.Root("cli_config_file"),
+			"Unable to Read File",
+			fmt.Sprintf("Unable to read the pwpush CLI configuration file %s, got error: %s", file, err),
+		)
+		return diags
+	}
+
+	config.apply(data)
+	return diags
+}
+
+// applyKeychain sets the API token of data when it is not set from the OS
+// credential store item of the service set with api_token_keychain, whose
+// account is the email of data.
+func applyKeychain(data *PwPusherProviderModel) diag.Diagnostics {
+	var diags diag.Diagnostics
+	if data.ApiTokenKeychain.IsNull() || !data.ApiToken.IsNull() {
+		return diags
+	}
+	if data.Email.IsNull() {
+		diags.AddAttributeError(
+			path.Root("api_token_keychain"),
+			"Missing Credentials",
+			"The api_token_keychain attribute requires the email attribute, the account of the token in the credential store.",
+		)
+		return diags
+	}
+
+	service, email := data.ApiTokenKeychain.ValueString(), data.Email.ValueString()
+	token, err := keyring.Get(service, email)
+	if errors.Is(err, keyring.ErrNotFound) {
+		diags.AddAttributeError(
+			path.Root("api_token_keychain"),
+			"Missing Keychain Item",
+			fmt.Sprintf("The OS credential store has no item of the service %q for the account %q.", service, email),
+		)
+		return diags
+	}
+	if err != nil {
+		diags.AddAttributeError(
+			path.Root("api_token_keychain"),
+			"Unable to Read Keychain",
+			fmt.Sprintf("Unable to read the API token from the OS credential store, got error: %s", err),
+		)
+		return diags
+	}
+	data.ApiToken = types.StringValue(token)
+	return diags
+}
+
+// applyCredentialsCommand sets the URL and the credentials of data that are
+// not set from the JSON output of the program set with credentials_command,
+// if any.
+func applyCredentialsCommand(ctx context.Context, data *PwPusherProviderModel) diag.Diagnostics {
+	var diags diag.Diagnostics
+	if data.CredentialsCommand.IsNull() || (!data.Url.IsNull() && !data.Email.IsNull()) {
+		return diags
+	}
+	var command []string
+	diags.Append(data.CredentialsCommand.ElementsAs(ctx, &command, false)...)
+	if diags.HasError() {
+		return diags
+	}
+	if len(command) == 0 {
+		diags.AddAttributeError(
+			path.Root("credentials_command"),
+			"Invalid Credentials Command",
+			"The credentials_command attribute must contain at least the program to run.",
+		)
+		return diags
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, credentialsCommandTimeout)
+	defer cancel()
+	cmd := exec.CommandContext(ctx, command[0], command[1:]...)
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	output, err := cmd.Output()
+	if err != nil {
+		diags.AddAttributeError(
+			path.Root("credentials_command"),
+			"Credentials Command Failed",
+			fmt.Sprintf("Unable to run %s, got error: %s\n\n%s", command[0], err, strings.TrimSpace(stderr.String())),
+		)
+		return diags
+	}
+	credentials, err := parseCredentialsJSON(output)
+	if err != nil {
+		diags.AddAttributeError(
+			path.Root("credentials_command"),
+			"Invalid Credentials Command Output",
+			fmt.Sprintf("Unable to parse the output of %s as JSON, got error: %s", command[0], err),
+		)
+		return diags
+	}
+	credentials.apply(data)
+	return diags
+}
+
+// applyCredentialsFile sets the URL and the credentials of data that are not
+// set from the JSON file set with credentials_file, if any.
+func applyCredentialsFile(data *PwPusherProviderModel) diag.Diagnostics {
+	var diags diag.Diagnostics
+	if data.CredentialsFile.IsNull() {
+		return diags
+	}
+	file := data.CredentialsFile.ValueString()
+	content, err := os.ReadFile(file)
+	if err != nil {
+		diags.AddAttributeError(
+			path.Root("credentials_file"),
+			"Unable to Read File",
+			fmt.Sprintf("Unable to read the credentials file %s, got error: %s", file, err),
+		)
+		return diags
+	}
+	credentials, err := parseCredentialsJSON(content)
+	if err != nil {
+		diags.AddAttributeError(
+			path.Root("credentials_file"),
+			"Invalid Credentials File",
+			fmt.Sprintf("Unable to parse the credentials file %s, got error: %s", file, err),
+		)
+		return diags
+	}
+	credentials.apply(data)
+	return diags
+}
+
+// parseCredentialsJSON parses a JSON object with optional url, email and
+// token members.
+func parseCredentialsJSON(content []byte) (sourcedCredentials, error) {
+	var credentials struct {
+		Url   string `json:"url"`
+		Email string `json:"email"`
+		Token string `json:"token"`
+	}
+	if err := json.Unmarshal(content, &credentials); err != nil {
+		return sourcedCredentials{}, err
+	}
+	return sourcedCredentials{url: credentials.Url, email: credentials.Email, token: credentials.Token}, nil
+}
+
+// netrcFile returns the path of the netrc file of the user, set by the
+// NETRC environment variable like curl does.
+func netrcFile() (string, error) {
+	if file := os.Getenv("NETRC"); file != "" {
+		return file, nil
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(home, ".netrc"), nil
+}
+
+// applyNetrc sets the credentials of data when they are not set from the
+// entry of the host of its URL in the netrc file of the user, with the email
+// as login and the API token as password.
+func applyNetrc(data *PwPusherProviderModel) diag.Diagnostics {
+	var diags diag.Diagnostics
+	if !data.Email.IsNull() || !data.ApiToken.IsNull() {
+		return diags
+	}
+	serviceURL, err := url.Parse(data.Url.ValueString())
+	if err != nil {
+		// The URL is checked, with a better message, later on.
+		return diags
+	}
+	file, err := netrcFile()
+	if err != nil {
+		return diags
+	}
+	content, err := os.ReadFile(file)
+	if errors.Is(err, fs.ErrNotExist) {
+		return diags
+	}
+	if err != nil {
+		diags.AddError("Unable to Read File", fmt.Sprintf("Unable to read the netrc file %s, got error: %s", file, err))
+		return diags
+	}
+	login, password := netrcLookup(string(content), serviceURL.Hostname())
+	sourcedCredentials{email: login, token: password}.apply(data)
+	return diags
+}
+
+// netrcLookup returns the login and password of the entry of machine in
+// the netrc content. The default entry is ignored: its credentials are
+// meant for any host, such as anonymous FTP logins, and must not be sent to
+// a pwpusher service as an API token.
+func netrcLookup(content, machine string) (login, password string) {
+	type entry struct {
+		machine, login, password string
+	}
+	var entries []*entry
+	var current *entry
+	key := ""
+	inMacro := false
+	for _, line := range strings.Split(content, "\n") {
+		// Macros last until the next empty line.
+		if inMacro {
+			inMacro = strings.TrimSpace(line) != ""
+			continue
+		}
+		for _, field := range strings.Fields(line) {
+			if key != "" {
+				switch key {
+				case "machine":
+					current = &entry{machine: field}
+					entries = append(entries, current)
+				case "login":
+					if current != nil {
+						current.login = field
+					}
+				case "password":
+					if current != nil {
+						current.password = field
+					}
+				}
+				key = ""
+				continue
+			}
+			switch field {
+			case "default":
+				// The fields of the default entry must not end up in the
+				// entry before it.
+				current = nil
+			case "machine", "login", "password", "account":
+				key = field
+			case "macdef":
+				inMacro = true
+			}
+			if inMacro {
+				break
+			}
+		}
+	}
+
+	for _, e := range entries {
+		if e.machine == machine {
+			return e.login, e.password
+		}
+	}
+	return "", ""
+}
@@ -0,0 +1,70 @@
+// Copyright (c) Plex, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package provider
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"terraform-provider-pwpusher/internal/client"
+	"time"
+)
+
+// fingerprintPrefix starts the line of the notes of pushes created with a
+// dedupe_window recording the fingerprint of their payload and settings.
+const fingerprintPrefix = "Terraform push fingerprint: "
+
+// fingerprintKey returns the key of the fingerprints of the pushes of the
+// user of apiToken. Fingerprints are keyed so that the notes of the
+// dashboard cannot be used to guess the payloads of the pushes.
+func fingerprintKey(apiToken string) []byte {
+	key := sha256.Sum256([]byte("pwpusher push fingerprint\n" + apiToken))
+	return key[:]
+}
+
+// pushFingerprint returns the line of the note of a push of payload telling
+// it apart from the pushes of other payloads, settings or resources, keyed
+// with key. dedupeKey is the dedupe_key of the resource of the push, as
+// identical payloads of different resources must not adopt each other's push.
+func pushFingerprint(key []byte, dedupeKey string, payload client.Payload) (string, error) {
+	// The note holds the fingerprint itself, and the marker of the
+	// request, which differs between identical pushes.
+	payload.Note = ""
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return "", client.ErrEncoding
+	}
+	mac := hmac.New(sha256.New, key)
+	// The length ends the dedupe key unambiguously, whatever it holds.
+	fmt.Fprintf(mac, "%d:%s", len(dedupeKey), dedupeKey)
+	mac.Write(body)
+	return fingerprintPrefix + hex.EncodeToString(mac.Sum(nil)[:16]), nil
+}
+
+// findRecentPush returns the active push of the dashboard of pushClient
+// whose note holds fingerprint and that was created less than window ago,
+// the most recent one if there are several, and whether there is one.
+func findRecentPush(ctx context.Context, pushClient client.PushClient, fingerprint string, window time.Duration, now time.Time) (client.Push, bool, error) {
+	pushes, err := pushClient.ListPushes(ctx, "active")
+	if err != nil {
+		return client.Push{}, false, err
+	}
+	var recent client.Push
+	var recentAt time.Time
+	for _, push := range pushes {
+		if push.Expired || push.Deleted || !strings.Contains(push.Note, fingerprint) {
+			continue
+		}
+		createdAt, err := time.Parse(time.RFC3339, push.CreatedAt)
+		if err != nil || now.Sub(createdAt) > window || createdAt.Before(recentAt) {
+			continue
+		}
+		recent, recentAt = push, createdAt
+	}
+	return recent, !recentAt.IsZero(), nil
+}
@@ -0,0 +1,75 @@
+// Copyright (c) Plex, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package provider
+
+import (
+	"context"
+	"strings"
+	"terraform-provider-pwpusher/internal/client"
+	"terraform-provider-pwpusher/internal/client/clienttest"
+	"testing"
+	"time"
+)
+
+func TestPushFingerprint(t *testing.T) {
+	key := fingerprintKey("api-token")
+	passphrase := "open sesame"
+	payload := client.Payload{Password: "hunter2", Passphrase: &passphrase, Kind: "text", Name: "db"}
+	fingerprint, err := pushFingerprint(key, "pwpusher_text.db", payload)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(fingerprint, fingerprintPrefix) || strings.Contains(fingerprint, "hunter2") {
+		t.Errorf("got fingerprint %q", fingerprint)
+	}
+
+	noted := payload
+	noted.Note = "Created by the acme/onboarding/aws module"
+	if got, _ := pushFingerprint(key, "pwpusher_text.db", noted); got != fingerprint {
+		t.Errorf("got fingerprint %q for another note, want %q", got, fingerprint)
+	}
+	other := payload
+	other.Password = "hunter3"
+	retrieval := payload
+	retrieval.RetrievalStep = true
+	for name, test := range map[string]struct {
+		key       []byte
+		dedupeKey string
+		payload   client.Payload
+	}{
+		"payload":        {key, "pwpusher_text.db", other},
+		"retrieval step": {key, "pwpusher_text.db", retrieval},
+		"other user":     {fingerprintKey("other-token"), "pwpusher_text.db", payload},
+		"other resource": {key, "pwpusher_text.cache", payload},
+	} {
+		if got, _ := pushFingerprint(test.key, test.dedupeKey, test.payload); got == fingerprint {
+			t.Errorf("%s: got the same fingerprint", name)
+		}
+	}
+}
+
+func TestFindRecentPush(t *testing.T) {
+	server := clienttest.NewServer()
+	defer server.Close()
+	pushClient := server.Client("")
+	ctx := context.Background()
+
+	fingerprint := fingerprintPrefix + "abc"
+	for _, note := range []string{"unrelated", noteWithMarker("Created by the acme/onboarding/aws module", fingerprint), fingerprint} {
+		if _, err := pushClient.CreatePush(ctx, client.CurrentAPI, client.Payload{Password: "hunter2", Kind: "text", Note: note}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	push, found, err := findRecentPush(ctx, pushClient, fingerprint, time.Minute, time.Now())
+	if err != nil || !found || (push.ID != "token2" && push.ID != "token3") {
+		t.Errorf("got push %+v, %t, %v", push, found, err)
+	}
+	if _, found, err := findRecentPush(ctx, pushClient, fingerprint, time.Minute, time.Now().Add(time.Hour)); err != nil || found {
+		t.Errorf("got a push created before the window, %v", err)
+	}
+	if _, found, err := findRecentPush(ctx, pushClient, fingerprintPrefix+"def", time.Minute, time.Now()); err != nil || found {
+		t.Errorf("got a push of another fingerprint, %v", err)
+	}
+}
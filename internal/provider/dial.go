@@ -0,0 +1,127 @@
+// Copyright (c) Plex, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package provider
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+)
+
+// defaultDNSPort is the port of dns_server when it does not set one.
+const defaultDNSPort = "53"
+
+// ipFamilies maps the values of ip_family to the suffix of the networks
+// connections are restricted to.
+var ipFamilies = map[string]string{
+	"any":  "",
+	"ipv4": "4",
+	"ipv6": "6",
+}
+
+// dialFunc is the signature of http.Transport.DialContext.
+type dialFunc func(ctx context.Context, network, addr string) (net.Conn, error)
+
+// newDialer returns a dialer with the settings of the dialer of
+// http.DefaultTransport.
+func newDialer() *net.Dialer {
+	return &net.Dialer{Timeout: 30 * time.Second, KeepAlive: 30 * time.Second}
+}
+
+// newDialFunc returns a function connecting to the service as configured by
+// data, or nil to connect with newDialer. The service URL
+// still sets the Host header and the name the TLS certificate is verified
+// for.
+func newDialFunc(data PwPusherProviderModel) (dialFunc, diag.Diagnostics) {
+	var diags diag.Diagnostics
+	dialer := newDialer()
+	custom := false
+
+	if !data.DnsServer.IsNull() {
+		server := data.DnsServer.ValueString()
+		if _, _, err := net.SplitHostPort(server); err != nil {
+			server = net.JoinHostPort(server, defaultDNSPort)
+		}
+		if host, _, _ := net.SplitHostPort(server); net.ParseIP(host) == nil {
+			diags.AddAttributeError(
+				path.Root("dns_server"),
+				"Invalid DNS Server",
+				fmt.Sprintf("The dns_server attribute must be an IP address with an optional port, got %q.", data.DnsServer.ValueString()),
+			)
+		}
+		// The Go resolver is the only one whose server can be replaced.
+		dialer.Resolver = &net.Resolver{
+			PreferGo: true,
+			Dial: func(ctx context.Context, network, _ string) (net.Conn, error) {
+				var d net.Dialer
+				return d.DialContext(ctx, network, server)
+			},
+		}
+		custom = true
+	}
+
+	family := ""
+	if !data.IpFamily.IsNull() {
+		var ok bool
+		family, ok = ipFamilies[data.IpFamily.ValueString()]
+		if !ok {
+			diags.AddAttributeError(
+				path.Root("ip_family"),
+				"Invalid IP Family",
+				fmt.Sprintf("The ip_family attribute must be one of %s, got %q.", ipFamilyNames(), data.IpFamily.ValueString()),
+			)
+		}
+		custom = custom || family != ""
+	}
+
+	unixSocket, dialAddress := data.UnixSocket.ValueString(), data.DialAddress.ValueString()
+	switch {
+	case unixSocket != "" && dialAddress != "":
+		diags.AddAttributeError(
+			path.Root("dial_address"),
+			"Conflicting Dial Configuration",
+			"Only one of the unix_socket and dial_address attributes can be set.",
+		)
+	case unixSocket != "":
+		return func(ctx context.Context, _, _ string) (net.Conn, error) {
+			return dialer.DialContext(ctx, "unix", unixSocket)
+		}, diags
+	case dialAddress != "":
+		if _, _, err := net.SplitHostPort(dialAddress); err != nil {
+			diags.AddAttributeError(
+				path.Root("dial_address"),
+				"Invalid Dial Address",
+				fmt.Sprintf("The dial_address attribute must be a host and a port, got error: %s", err),
+			)
+		}
+		custom = true
+	}
+	if diags.HasError() || !custom {
+		return nil, diags
+	}
+
+	return func(ctx context.Context, network, addr string) (net.Conn, error) {
+		if dialAddress != "" {
+			addr = dialAddress
+		}
+		return dialer.DialContext(ctx, network+family, addr)
+	}, diags
+}
+
+// ipFamilyNames returns the accepted values of ip_family for use in
+// messages.
+func ipFamilyNames() string {
+	names := make([]string, 0, len(ipFamilies))
+	for name := range ipFamilies {
+		names = append(names, "`"+name+"`")
+	}
+	sort.Strings(names)
+	return strings.Join(names, ", ")
+}
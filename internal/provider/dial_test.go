@@ -0,0 +1,126 @@
+// Copyright (c) Plex, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package provider
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"golang.org/x/net/dns/dnsmessage"
+)
+
+func TestNewDialFunc(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer server.Close()
+	_, port, _ := net.SplitHostPort(server.Listener.Addr().String())
+	dnsServer := testDNSServer(t, net.IPv4(127, 0, 0, 1))
+
+	for name, test := range map[string]struct {
+		data    PwPusherProviderModel
+		addr    string
+		connect bool
+	}{
+		"dns server": {
+			data:    PwPusherProviderModel{DnsServer: types.StringValue(dnsServer)},
+			addr:    net.JoinHostPort("pwpush.test", port),
+			connect: true,
+		},
+		"ipv4": {
+			data:    PwPusherProviderModel{IpFamily: types.StringValue("ipv4")},
+			addr:    server.Listener.Addr().String(),
+			connect: true,
+		},
+		"ipv6": {
+			data: PwPusherProviderModel{IpFamily: types.StringValue("ipv6")},
+			addr: server.Listener.Addr().String(),
+		},
+		"dial address": {
+			data:    PwPusherProviderModel{DialAddress: types.StringValue(server.Listener.Addr().String())},
+			addr:    "pwpush.invalid:443",
+			connect: true,
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			dial, diags := newDialFunc(test.data)
+			if diags.HasError() {
+				t.Fatalf("unexpected error: %v", diags)
+			}
+			conn, err := dial(context.Background(), "tcp", test.addr)
+			if err == nil {
+				conn.Close()
+			}
+			if connected := err == nil; connected != test.connect {
+				t.Errorf("connected: got %t, want %t (error: %v)", connected, test.connect, err)
+			}
+		})
+	}
+}
+
+func TestNewDialFuncInvalid(t *testing.T) {
+	for name, data := range map[string]PwPusherProviderModel{
+		"dns server host name": {DnsServer: types.StringValue("dns.example.com")},
+		"ip family":            {IpFamily: types.StringValue("ipx")},
+		"dial address port":    {DialAddress: types.StringValue("10.0.0.5")},
+		"unix socket and dial address": {
+			UnixSocket:  types.StringValue("/run/pwpush.sock"),
+			DialAddress: types.StringValue("10.0.0.5:443"),
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			if _, diags := newDialFunc(data); !diags.HasError() {
+				t.Error("expected an error")
+			}
+		})
+	}
+	if dial, diags := newDialFunc(PwPusherProviderModel{IpFamily: types.StringValue("any")}); dial != nil || diags.HasError() {
+		t.Errorf("default settings: got a dial function or %v, want neither", diags)
+	}
+}
+
+// testDNSServer starts a DNS server answering every A query with ip and
+// returns its address.
+func testDNSServer(t *testing.T, ip net.IP) string {
+	t.Helper()
+
+	conn, err := net.ListenPacket("udp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { conn.Close() })
+
+	go func() {
+		buf := make([]byte, 512)
+		for {
+			n, addr, err := conn.ReadFrom(buf)
+			if err != nil {
+				return
+			}
+			var query dnsmessage.Message
+			if err := query.Unpack(buf[:n]); err != nil || len(query.Questions) == 0 {
+				continue
+			}
+			answer := dnsmessage.Message{
+				Header:    dnsmessage.Header{ID: query.ID, Response: true, Authoritative: true},
+				Questions: query.Questions,
+			}
+			if question := query.Questions[0]; question.Type == dnsmessage.TypeA {
+				answer.Answers = []dnsmessage.Resource{{
+					Header: dnsmessage.ResourceHeader{Name: question.Name, Type: dnsmessage.TypeA, Class: dnsmessage.ClassINET, TTL: 60},
+					Body:   &dnsmessage.AResource{A: [4]byte(ip.To4())},
+				}}
+			}
+			packed, err := answer.Pack()
+			if err != nil {
+				continue
+			}
+			_, _ = conn.WriteTo(packed, addr)
+		}
+	}()
+
+	return conn.LocalAddr().String()
+}
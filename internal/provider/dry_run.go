@@ -0,0 +1,88 @@
+// Copyright (c) Plex, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package provider
+
+import (
+	"crypto/rand"
+	"encoding/base64"
+	"terraform-provider-pwpusher/internal/client"
+	"time"
+)
+
+// Expiration settings of the pushes the pwpusher app creates without
+// explicit ones, by default.
+const (
+	appDefaultExpireAfterDays  = 7
+	appDefaultExpireAfterViews = 5
+)
+
+// pushDefaults are the expiration settings of the pushes of a service that do
+// not set their own, and the size limit of their payloads.
+type pushDefaults struct {
+	expireAfterDays  int32
+	expireAfterViews int32
+	maxPayloadBytes  int64
+}
+
+// appPushDefaults are the pushDefaults of the pwpusher app, the fallback for
+// the ones the service does not show.
+var appPushDefaults = pushDefaults{
+	expireAfterDays:  appDefaultExpireAfterDays,
+	expireAfterViews: appDefaultExpireAfterViews,
+	maxPayloadBytes:  defaultMaxPayloadBytes,
+}
+
+// newPushDefaults returns the pushDefaults of form, with those of
+// appPushDefaults for the settings it does not have.
+func newPushDefaults(form client.PushForm) pushDefaults {
+	defaults := appPushDefaults
+	if form.ExpireAfterDays > 0 {
+		defaults.expireAfterDays = int32(form.ExpireAfterDays)
+	}
+	if form.ExpireAfterViews > 0 {
+		defaults.expireAfterViews = int32(form.ExpireAfterViews)
+	}
+	if form.MaxPayloadBytes > 0 {
+		defaults.maxPayloadBytes = form.MaxPayloadBytes
+	}
+	return defaults
+}
+
+// dryRunTokenPrefix prefixes the tokens of simulated pushes, so that their
+// URLs are recognizable.
+const dryRunTokenPrefix = "dry-run-"
+
+// dryRunSecret returns the push the service with defaults would create for
+// payload, without creating it.
+func dryRunSecret(payload client.Payload, defaults pushDefaults) (client.Push, error) {
+	random := make([]byte, 12)
+	if _, err := rand.Read(random); err != nil {
+		return client.Push{}, err
+	}
+	return simulatedSecret(payload, dryRunTokenPrefix+base64.RawURLEncoding.EncodeToString(random), time.Now(), defaults), nil
+}
+
+// simulatedSecret returns the push with token the service with defaults
+// would create for payload at now, with the expiration settings of payload
+// when it has them.
+func simulatedSecret(payload client.Payload, token string, now time.Time, defaults pushDefaults) client.Push {
+	timestamp := now.UTC().Format(time.RFC3339)
+	if payload.ExpireAfterDays > 0 {
+		defaults.expireAfterDays = int32(payload.ExpireAfterDays)
+	}
+	if payload.ExpireAfterViews > 0 {
+		defaults.expireAfterViews = int32(payload.ExpireAfterViews)
+	}
+	return client.Push{
+		ID:                token,
+		ExpireAfterDays:   int(defaults.expireAfterDays),
+		ExpireAfterViews:  int(defaults.expireAfterViews),
+		CreatedAt:         timestamp,
+		UpdatedAt:         timestamp,
+		DeletableByViewer: payload.DeletableByViewer,
+		RetrievalStep:     payload.RetrievalStep,
+		DaysRemaining:     int(defaults.expireAfterDays),
+		ViewsRemaining:    int(defaults.expireAfterViews),
+	}
+}
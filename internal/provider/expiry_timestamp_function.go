@@ -0,0 +1,62 @@
+// Copyright (c) Plex, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package provider
+
+import (
+	"context"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-framework/function"
+)
+
+// Ensure provider defined types fully satisfy framework interfaces.
+var _ function.Function = &ExpiryTimestampFunction{}
+
+func NewExpiryTimestampFunction() function.Function {
+	return &ExpiryTimestampFunction{}
+}
+
+// ExpiryTimestampFunction defines the function implementation.
+type ExpiryTimestampFunction struct{}
+
+func (f *ExpiryTimestampFunction) Metadata(ctx context.Context, req function.MetadataRequest, resp *function.MetadataResponse) {
+	resp.Name = "expiry_timestamp"
+}
+
+func (f *ExpiryTimestampFunction) Definition(ctx context.Context, req function.DefinitionRequest, resp *function.DefinitionResponse) {
+	resp.Definition = function.Definition{
+		Summary:             "Compute the time a push expires",
+		MarkdownDescription: "Returns the RFC 3339 timestamp, in UTC, at which a push expires by age. The push may expire earlier once its views are used up",
+		Parameters: []function.Parameter{
+			function.StringParameter{
+				Name:                "created_at",
+				MarkdownDescription: "The `created_at` timestamp of the push",
+			},
+			function.Int64Parameter{
+				Name:                "expire_after_days",
+				MarkdownDescription: "The `expire_after_days` of the push",
+			},
+		},
+		Return: function.StringReturn{},
+	}
+}
+
+func (f *ExpiryTimestampFunction) Run(ctx context.Context, req function.RunRequest, resp *function.RunResponse) {
+	var createdAt string
+	var days int64
+
+	resp.Error = function.ConcatFuncErrors(req.Arguments.Get(ctx, &createdAt, &days))
+
+	if resp.Error != nil {
+		return
+	}
+
+	expiry, funcErr := pushExpiry(createdAt, days, 0)
+	if funcErr != nil {
+		resp.Error = funcErr
+		return
+	}
+
+	resp.Error = function.ConcatFuncErrors(resp.Result.Set(ctx, expiry.UTC().Format(time.RFC3339)))
+}
@@ -0,0 +1,110 @@
+// Copyright (c) Plex, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package provider
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// pushExport is the artifact export_path writes after a push is created, for
+// pipelines to attach to tickets or release notes.
+type pushExport struct {
+	URL              string `json:"url"`
+	CreatedAt        string `json:"created_at"`
+	ExpireAfterDays  int32  `json:"expire_after_days"`
+	ExpireAfterViews int32  `json:"expire_after_views"`
+	Passphrase       bool   `json:"passphrase"`
+	Instructions     string `json:"instructions"`
+}
+
+// newPushExport returns the artifact of the push of data, whose passphrase is
+// set when passphrase is.
+func newPushExport(data TextResourceModel, passphrase bool) pushExport {
+	days, views := int64(data.DaysRemaining.ValueInt32()), int64(data.ViewsRemaining.ValueInt32())
+	instructions := fmt.Sprintf("Open the link to view the secret, it has %s before it expires. View it once and store it somewhere safe.", humanizeRemaining(&days, &views))
+	if data.RetrievalStep.ValueBool() {
+		instructions += " The link opens a page with a button revealing the secret."
+	}
+	if passphrase {
+		instructions += " The secret is protected with a passphrase, which is shared separately."
+	}
+	return pushExport{
+		URL:              data.Url.ValueString(),
+		CreatedAt:        data.CreatedAt.ValueString(),
+		ExpireAfterDays:  data.ExpireAfterDays.ValueInt32(),
+		ExpireAfterViews: data.ExpireAfterViews.ValueInt32(),
+		Passphrase:       passphrase,
+		Instructions:     instructions,
+	}
+}
+
+// markdown returns the artifact as a Markdown document.
+func (e pushExport) markdown() string {
+	var doc strings.Builder
+	doc.WriteString("# Shared secret\n\n")
+	fmt.Fprintf(&doc, "<%s>\n\n", e.URL)
+	fmt.Fprintf(&doc, "%s\n\n", e.Instructions)
+	fmt.Fprintf(&doc, "| Created | Expires after days | Expires after views |\n")
+	fmt.Fprintf(&doc, "| --- | --- | --- |\n")
+	fmt.Fprintf(&doc, "| %s | %d | %d |\n", e.CreatedAt, e.ExpireAfterDays, e.ExpireAfterViews)
+	return doc.String()
+}
+
+// write writes the artifact to path, as Markdown when it has an .md
+// extension and as JSON otherwise.
+func (e pushExport) write(path string) error {
+	var content []byte
+	if strings.EqualFold(filepath.Ext(path), ".md") {
+		content = []byte(e.markdown())
+	} else {
+		var err error
+		content, err = json.MarshalIndent(e, "", "  ")
+		if err != nil {
+			return err
+		}
+		content = append(content, '\n')
+	}
+	// The URL gives access to the push, so the artifact is only readable by
+	// its owner.
+	return os.WriteFile(path, content, 0o600)
+}
+
+// removeExport removes the artifact of the push at url from path. The file
+// may not exist anymore, or hold the artifact of another push written to the
+// same path since, which is left alone.
+func removeExport(path, url string) error {
+	content, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if !exportHolds(path, content, url) {
+		return nil
+	}
+	if err := os.Remove(path); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return err
+	}
+	return nil
+}
+
+// exportHolds returns whether content, read from path, is the artifact of the
+// push at url.
+func exportHolds(path string, content []byte, url string) bool {
+	if strings.EqualFold(filepath.Ext(path), ".md") {
+		return strings.Contains(string(content), "<"+url+">")
+	}
+	var export pushExport
+	if err := json.Unmarshal(content, &export); err != nil {
+		return false
+	}
+	return export.URL == url
+}
@@ -0,0 +1,128 @@
+// Copyright (c) Plex, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package provider
+
+import (
+	"bytes"
+	"compress/gzip"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"regexp"
+	"strings"
+	"terraform-provider-pwpusher/internal/client"
+	"time"
+)
+
+// fakeTokenPrefix prefixes the tokens of the pushes of the fake service.
+const fakeTokenPrefix = "fake-"
+
+// fakeTokenKey keys the tokens of the pushes of the fake service without a
+// fake_seed. It is fixed, so that the tokens are the same across the runs of
+// the provider.
+const fakeTokenKey = "pwpusher fake tokens"
+
+// fakeDashboardPath matches the dashboard paths of every push kind.
+var fakeDashboardPath = regexp.MustCompile(`/(p|f|r|qr)/(active|expired)\.json$`)
+
+// fakePushPath matches the paths of text pushes, with their token.
+var fakePushPath = regexp.MustCompile(`/p/([^/]+)\.json$`)
+
+// fakeTransport answers requests like an empty pwpusher service would,
+// without any network access. The fake mode of the provider sends every
+// request to it.
+type fakeTransport struct {
+	// key keys the tokens of the pushes, see fakeToken.
+	key []byte
+}
+
+// newFakeTransport returns a fake service whose tokens are keyed with seed,
+// or fakeTokenKey when it is empty.
+func newFakeTransport(seed string) fakeTransport {
+	if seed == "" {
+		seed = fakeTokenKey
+	}
+	return fakeTransport{key: []byte(seed)}
+}
+
+func (t fakeTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if req.Body != nil {
+		defer req.Body.Close()
+	}
+
+	switch path := req.URL.Path; {
+	case req.Method == http.MethodGet && strings.HasSuffix(path, "/api/v1/version.json"):
+		return fakeResponse(req, http.StatusOK, client.Version{ApplicationVersion: "fake", ApiVersion: "1.0", Edition: "oss"})
+	case req.Method == http.MethodGet && fakeDashboardPath.MatchString(path):
+		return fakeResponse(req, http.StatusOK, []client.Push{})
+	case req.Method == http.MethodGet && strings.HasSuffix(path, "/audit.json"):
+		// Fake pushes count as viewed right away, so that configurations
+		// waiting for a view do not wait for the timeout.
+		view := client.AuditView{Successful: true, CreatedAt: time.Now().UTC().Format(time.RFC3339), Kind: client.AuditViewKindView}
+		return fakeResponse(req, http.StatusOK, client.AuditLog{Views: []client.AuditView{view}})
+	case req.Method == http.MethodDelete && fakePushPath.MatchString(path):
+		now := time.Now().UTC().Format(time.RFC3339)
+		token := fakePushPath.FindStringSubmatch(path)[1]
+		return fakeResponse(req, http.StatusOK, client.Push{ID: token, Expired: true, UpdatedAt: now, ExpiredAt: now})
+	case req.Method == http.MethodPost && strings.HasSuffix(path, "/p.json"):
+		body, err := fakeRequestBody(req)
+		if err != nil {
+			return fakeResponse(req, http.StatusBadRequest, map[string]string{"error": err.Error()})
+		}
+		var payload client.Payload
+		if err := json.Unmarshal(body, &payload); err != nil {
+			return fakeResponse(req, http.StatusBadRequest, map[string]string{"error": err.Error()})
+		}
+		return fakeResponse(req, http.StatusCreated, simulatedSecret(payload, fakeToken(t.key, req.URL.String(), body), time.Now(), appPushDefaults))
+	}
+	return fakeResponse(req, http.StatusNotFound, map[string]string{"error": "Not Found"})
+}
+
+// fakeRequestBody returns the body of req, decompressed when the client
+// compressed it.
+func fakeRequestBody(req *http.Request) ([]byte, error) {
+	if !strings.EqualFold(req.Header.Get("Content-Encoding"), "gzip") {
+		return io.ReadAll(req.Body)
+	}
+	reader, err := gzip.NewReader(req.Body)
+	if err != nil {
+		return nil, err
+	}
+	defer reader.Close()
+	return io.ReadAll(reader)
+}
+
+// fakeToken returns the token of the fake push created at endpoint with
+// body, keyed with key. It is the same for the same request, so that the
+// pushes of a run are reproducible.
+func fakeToken(key []byte, endpoint string, body []byte) string {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(endpoint + "\n"))
+	mac.Write(body)
+	return fakeTokenPrefix + base64.RawURLEncoding.EncodeToString(mac.Sum(nil)[:12])
+}
+
+// fakeResponse returns a response to req with status and the JSON encoding
+// of body.
+func fakeResponse(req *http.Request, status int, body any) (*http.Response, error) {
+	encoded, err := json.Marshal(body)
+	if err != nil {
+		return nil, err
+	}
+	return &http.Response{
+		Status:        fmt.Sprintf("%d %s", status, http.StatusText(status)),
+		StatusCode:    status,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        http.Header{"Content-Type": {"application/json"}},
+		Body:          io.NopCloser(bytes.NewReader(encoded)),
+		ContentLength: int64(len(encoded)),
+		Request:       req,
+	}, nil
+}
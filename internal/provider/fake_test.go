@@ -0,0 +1,119 @@
+// Copyright (c) Plex, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package provider
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/json"
+	"net/http"
+	"strings"
+	"terraform-provider-pwpusher/internal/client"
+	"testing"
+)
+
+func TestFakeTransport(t *testing.T) {
+	httpClient := &http.Client{Transport: newFakeTransport("")}
+
+	pushWith := func(httpClient *http.Client, payload string, compress bool) string {
+		t.Helper()
+		body := []byte(payload)
+		if compress {
+			var compressed bytes.Buffer
+			writer := gzip.NewWriter(&compressed)
+			if _, err := writer.Write(body); err != nil {
+				t.Fatal(err)
+			}
+			if err := writer.Close(); err != nil {
+				t.Fatal(err)
+			}
+			body = compressed.Bytes()
+		}
+		req, err := http.NewRequest(http.MethodPost, "https://pwpush.invalid/p.json", bytes.NewReader(body))
+		if err != nil {
+			t.Fatal(err)
+		}
+		req.Header.Set("Content-Type", "application/json")
+		if compress {
+			req.Header.Set("Content-Encoding", "gzip")
+		}
+		resp, err := httpClient.Do(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer resp.Body.Close()
+		var secret client.Push
+		if err := json.NewDecoder(resp.Body).Decode(&secret); err != nil {
+			t.Fatal(err)
+		}
+		if resp.StatusCode != http.StatusCreated || !strings.HasPrefix(secret.ID, fakeTokenPrefix) {
+			t.Errorf("got status %d and token %q, want a %s prefix", resp.StatusCode, secret.ID, fakeTokenPrefix)
+		}
+		return secret.ID
+	}
+	push := func(payload string) string {
+		t.Helper()
+		return pushWith(httpClient, payload, false)
+	}
+	if first, again := push(`{"payload":"one"}`), push(`{"payload":"one"}`); first != again {
+		t.Errorf("got tokens %q and %q for the same push", first, again)
+	}
+	if one, two := push(`{"payload":"one"}`), push(`{"payload":"two"}`); one == two {
+		t.Errorf("got token %q for different pushes", one)
+	}
+	// Compressed pushes are decompressed, and get the token of the same
+	// push sent uncompressed.
+	if plain, compressed := push(`{"payload":"one"}`), pushWith(httpClient, `{"payload":"one"}`, true); plain != compressed {
+		t.Errorf("got tokens %q and %q for the same push compressed", plain, compressed)
+	}
+	// Tokens only depend on the seed, not on the run of the provider.
+	if again := pushWith(&http.Client{Transport: newFakeTransport("")}, `{"payload":"one"}`, false); again != push(`{"payload":"one"}`) {
+		t.Errorf("got token %q from another fake service without a seed", again)
+	}
+	seeded := &http.Client{Transport: newFakeTransport("seed")}
+	if one, again := pushWith(seeded, `{"payload":"one"}`, false), pushWith(&http.Client{Transport: newFakeTransport("seed")}, `{"payload":"one"}`, false); one != again || one == push(`{"payload":"one"}`) {
+		t.Errorf("got tokens %q and %q with the same seed, want them to differ from the default key", one, again)
+	}
+
+	// Tokens are keyed, so that payloads cannot be confirmed by hashing
+	// guesses.
+	body := []byte(`{"payload":"one"}`)
+	if fakeToken([]byte("one key"), "https://pwpush.invalid/p.json", body) == fakeToken([]byte("other key"), "https://pwpush.invalid/p.json", body) {
+		t.Errorf("got the same token with different keys")
+	}
+
+	req, err := http.NewRequest(http.MethodDelete, "https://pwpush.invalid/pwpush/p/fake-token.json", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var expired client.Push
+	if err := json.NewDecoder(resp.Body).Decode(&expired); err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK || expired.ID != "fake-token" || !expired.Expired {
+		t.Errorf("expire: got status %d and push %+v", resp.StatusCode, expired)
+	}
+
+	for path, want := range map[string]int{
+		"/api/v1/version.json":  http.StatusOK,
+		"/p/active.json?page=1": http.StatusOK,
+		"/qr/expired.json":      http.StatusOK,
+		"/p/token/audit.json":   http.StatusOK,
+		"/unknown.json":         http.StatusNotFound,
+	} {
+		resp, err := httpClient.Get("https://pwpush.invalid" + path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		resp.Body.Close()
+		if resp.StatusCode != want {
+			t.Errorf("%s: got status %d, want %d", path, resp.StatusCode, want)
+		}
+	}
+}
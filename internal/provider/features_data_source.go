@@ -0,0 +1,157 @@
+// Copyright (c) Plex, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package provider
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/hashicorp/terraform-plugin-framework-timeouts/datasource/timeouts"
+	"github.com/hashicorp/terraform-plugin-framework/datasource"
+	"github.com/hashicorp/terraform-plugin-framework/datasource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+// Ensure provider defined types fully satisfy framework interfaces.
+var _ datasource.DataSource = &FeaturesDataSource{}
+
+// proEdition is the edition reported by instances with the Pro features,
+// such as custom tokens and delivery, enabled.
+const proEdition = "pro"
+
+func NewFeaturesDataSource() datasource.DataSource {
+	return &FeaturesDataSource{}
+}
+
+// FeaturesDataSource defines the data source implementation.
+type FeaturesDataSource struct {
+	providerData ProviderData
+}
+
+// FeaturesDataSourceModel describes the data source data model.
+type FeaturesDataSourceModel struct {
+	Text         types.Bool     `tfsdk:"text"`
+	File         types.Bool     `tfsdk:"file"`
+	Url          types.Bool     `tfsdk:"url"`
+	Qr           types.Bool     `tfsdk:"qr"`
+	CustomTokens types.Bool     `tfsdk:"custom_tokens"`
+	Delivery     types.Bool     `tfsdk:"delivery"`
+	Edition      types.String   `tfsdk:"edition"`
+	Timeouts     timeouts.Value `tfsdk:"timeouts"`
+}
+
+func (d *FeaturesDataSource) Metadata(ctx context.Context, req datasource.MetadataRequest, resp *datasource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_features"
+}
+
+func (d *FeaturesDataSource) Schema(ctx context.Context, req datasource.SchemaRequest, resp *datasource.SchemaResponse) {
+	resp.Schema = schema.Schema{
+		// This description is used by the documentation generator and the language server.
+		MarkdownDescription: "The push kinds and features enabled on the pwpusher service. A push kind is enabled when the service routes its endpoints, instances can disable file and URL pushes",
+
+		Attributes: map[string]schema.Attribute{
+			"text": schema.BoolAttribute{
+				MarkdownDescription: "If text pushes are enabled",
+				Computed:            true,
+			},
+			"file": schema.BoolAttribute{
+				MarkdownDescription: "If file pushes are enabled",
+				Computed:            true,
+			},
+			"url": schema.BoolAttribute{
+				MarkdownDescription: "If URL pushes are enabled",
+				Computed:            true,
+			},
+			"qr": schema.BoolAttribute{
+				MarkdownDescription: "If QR code pushes are enabled",
+				Computed:            true,
+			},
+			"custom_tokens": schema.BoolAttribute{
+				MarkdownDescription: "If pushes can be created with custom tokens",
+				Computed:            true,
+			},
+			"delivery": schema.BoolAttribute{
+				MarkdownDescription: "If the service can deliver pushes to recipients",
+				Computed:            true,
+			},
+			"edition": schema.StringAttribute{
+				MarkdownDescription: "The edition of the pwpusher app, such as `oss` or `pro`",
+				Computed:            true,
+			},
+		},
+
+		Blocks: map[string]schema.Block{
+			"timeouts": timeouts.Block(ctx),
+		},
+	}
+}
+
+func (d *FeaturesDataSource) Configure(ctx context.Context, req datasource.ConfigureRequest, resp *datasource.ConfigureResponse) {
+	// Prevent panic if the provider has not been configured.
+	if req.ProviderData == nil {
+		return
+	}
+
+	providerData, ok := req.ProviderData.(ProviderData)
+
+	if !ok {
+		resp.Diagnostics.AddError(
+			"Unexpected Data Source Configure Type",
+			fmt.Sprintf("Expected ProviderData, got: %T. Please report this issue to the provider developers.", req.ProviderData),
+		)
+
+		return
+	}
+
+	d.providerData = providerData
+}
+
+func (d *FeaturesDataSource) Read(ctx context.Context, req datasource.ReadRequest, resp *datasource.ReadResponse) {
+	var data FeaturesDataSourceModel
+
+	// Read Terraform configuration data into the model
+	resp.Diagnostics.Append(req.Config.Get(ctx, &data)...)
+
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	readTimeout, diags := data.Timeouts.Read(ctx, defaultDataSourceReadTimeout)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	ctx, cancel := context.WithTimeout(ctx, readTimeout)
+	defer cancel()
+
+	ctx, op := d.providerData.operationContext(ctx, logSubsystemDataSources, "pwpusher_features read")
+	defer op.end(&resp.Diagnostics)
+
+	kinds := map[string]*types.Bool{
+		"/p":  &data.Text,
+		"/f":  &data.File,
+		"/r":  &data.Url,
+		"/qr": &data.Qr,
+	}
+	for prefix, enabled := range kinds {
+		exists, err := d.providerData.routeExists(ctx, prefix+"/active.json")
+		if err != nil {
+			resp.Diagnostics.AddError("Client Error", fmt.Sprintf("Unable to detect enabled push kinds, got error: %s", err))
+			return
+		}
+		*enabled = types.BoolValue(exists)
+	}
+
+	version, err := d.providerData.serverVersion(ctx)
+	if err != nil {
+		resp.Diagnostics.AddError("Client Error", fmt.Sprintf("Unable to read version, got error: %s", err))
+		return
+	}
+	data.Edition = types.StringValue(version.Edition)
+	data.CustomTokens = types.BoolValue(version.Edition == proEdition)
+	data.Delivery = types.BoolValue(version.Edition == proEdition)
+
+	// Save data into Terraform state
+	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+}
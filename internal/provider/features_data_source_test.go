@@ -0,0 +1,32 @@
+// Copyright (c) Plex, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package provider
+
+import (
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-testing/helper/resource"
+)
+
+func TestAccFeaturesDataSource(t *testing.T) {
+	resource.Test(t, resource.TestCase{
+		PreCheck:                 func() { testAccPreCheck(t) },
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			// Read testing
+			{
+				Config: testAccFeaturesDataSourceConfig,
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("data.pwpusher_features.test", "text", "true"),
+					resource.TestCheckResourceAttrSet("data.pwpusher_features.test", "file"),
+					resource.TestCheckResourceAttrSet("data.pwpusher_features.test", "edition"),
+				),
+			},
+		},
+	})
+}
+
+const testAccFeaturesDataSourceConfig = `
+data "pwpusher_features" "test" {}
+`
@@ -0,0 +1,92 @@
+// Copyright (c) Plex, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package provider
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-framework/function"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+// Ensure provider defined types fully satisfy framework interfaces.
+var _ function.Function = &FormatEnvFunction{}
+
+var (
+	// envKeyPattern matches the keys that are valid environment variable
+	// names.
+	envKeyPattern = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+	// envBareValuePattern matches the values that can be written without
+	// quotes.
+	envBareValuePattern = regexp.MustCompile(`^[A-Za-z0-9_@%+=:,./-]*$`)
+
+	envValueEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "$", `\$`, "`", "\\`", "\n", `\n`, "\r", `\r`)
+)
+
+func NewFormatEnvFunction() function.Function {
+	return &FormatEnvFunction{}
+}
+
+// FormatEnvFunction defines the function implementation.
+type FormatEnvFunction struct{}
+
+func (f *FormatEnvFunction) Metadata(ctx context.Context, req function.MetadataRequest, resp *function.MetadataResponse) {
+	resp.Name = "format_env"
+}
+
+func (f *FormatEnvFunction) Definition(ctx context.Context, req function.DefinitionRequest, resp *function.DefinitionResponse) {
+	resp.Definition = function.Definition{
+		Summary:             "Format a map as a dotenv file",
+		MarkdownDescription: "Formats a map of secrets as dotenv style `KEY=value` lines sorted by key, so the result is stable across runs. Values with characters other than letters, digits and `_@%+=:,./-` are double quoted with `\\`, `\"`, `$`, backticks and line breaks escaped. Keys must be valid environment variable names",
+		Parameters: []function.Parameter{
+			function.MapParameter{
+				Name:                "values",
+				MarkdownDescription: "The secrets to format",
+				ElementType:         types.StringType,
+			},
+		},
+		Return: function.StringReturn{},
+	}
+}
+
+func (f *FormatEnvFunction) Run(ctx context.Context, req function.RunRequest, resp *function.RunResponse) {
+	var values map[string]string
+
+	resp.Error = function.ConcatFuncErrors(req.Arguments.Get(ctx, &values))
+
+	if resp.Error != nil {
+		return
+	}
+
+	keys := make([]string, 0, len(values))
+	for key := range values {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	// Keys are checked in order, so that the same map always reports the
+	// same invalid key.
+	for _, key := range keys {
+		if !envKeyPattern.MatchString(key) {
+			resp.Error = function.NewArgumentFuncError(0, fmt.Sprintf("%q is not a valid environment variable name", key))
+			return
+		}
+	}
+
+	var env strings.Builder
+	for _, key := range keys {
+		value := values[key]
+		if !envBareValuePattern.MatchString(value) {
+			value = `"` + envValueEscaper.Replace(value) + `"`
+		}
+		env.WriteString(key + "=" + value + "\n")
+	}
+
+	resp.Error = function.ConcatFuncErrors(resp.Result.Set(ctx, env.String()))
+}
@@ -0,0 +1,65 @@
+// Copyright (c) Plex, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package provider
+
+import (
+	"regexp"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-testing/helper/resource"
+	"github.com/hashicorp/terraform-plugin-testing/knownvalue"
+	"github.com/hashicorp/terraform-plugin-testing/statecheck"
+	"github.com/hashicorp/terraform-plugin-testing/tfversion"
+)
+
+func TestFormatEnvFunction(t *testing.T) {
+	resource.UnitTest(t, resource.TestCase{
+		TerraformVersionChecks: []tfversion.TerraformVersionCheck{
+			tfversion.SkipBelow(tfversion.Version1_8_0),
+		},
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: `
+output "test" {
+  value = provider::pwpusher::format_env({
+    DB_USER     = "admin"
+    DB_PASSWORD = "p@ss word$1"
+    API_URL     = "https://example.com/v1"
+  })
+}
+`,
+				ConfigStateChecks: []statecheck.StateCheck{
+					statecheck.ExpectKnownOutputValue("test", knownvalue.StringExact(
+						"API_URL=https://example.com/v1\nDB_PASSWORD=\"p@ss word\\$1\"\nDB_USER=admin\n",
+					)),
+				},
+			},
+			{
+				Config: `
+output "test" {
+  value = provider::pwpusher::format_env({
+    "DB-USER" = "admin"
+  })
+}
+`,
+				ExpectError: regexp.MustCompile("is not a valid environment"),
+			},
+			// The first invalid key in order is reported, whatever the order
+			// of the map.
+			{
+				Config: `
+output "test" {
+  value = provider::pwpusher::format_env({
+    "Z-KEY" = "z"
+    "A-KEY" = "a"
+    "M-KEY" = "m"
+  })
+}
+`,
+				ExpectError: regexp.MustCompile(`"A-KEY" is not a valid environment`),
+			},
+		},
+	})
+}
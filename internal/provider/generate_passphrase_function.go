@@ -0,0 +1,87 @@
+// Copyright (c) Plex, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package provider
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-framework/function"
+	"github.com/sethvargo/go-diceware/diceware"
+)
+
+// Ensure provider defined types fully satisfy framework interfaces.
+var _ function.Function = &GeneratePassphraseFunction{}
+
+const maxPassphraseWords = 32
+
+func NewGeneratePassphraseFunction() function.Function {
+	return &GeneratePassphraseFunction{}
+}
+
+// GeneratePassphraseFunction defines the function implementation.
+type GeneratePassphraseFunction struct{}
+
+func (f *GeneratePassphraseFunction) Metadata(ctx context.Context, req function.MetadataRequest, resp *function.MetadataResponse) {
+	resp.Name = "generate_passphrase"
+}
+
+func (f *GeneratePassphraseFunction) Definition(ctx context.Context, req function.DefinitionRequest, resp *function.DefinitionResponse) {
+	resp.Definition = function.Definition{
+		Summary:             "Generate a random word-based passphrase",
+		MarkdownDescription: "Generates a memorable passphrase of words from the [EFF large wordlist](https://www.eff.org/deeplinks/2016/07/new-wordlists-random-passphrases), about 12.9 bits of entropy per word. Like `generate_password`, the passphrase is derived from a seed and the same seed always yields the same passphrase: pass a long random secret marked sensitive, and change it to rotate the passphrase",
+		Parameters: []function.Parameter{
+			function.StringParameter{
+				Name:                "seed",
+				MarkdownDescription: fmt.Sprintf("The seed the passphrase is derived from, at least %d characters long, ", minSeedLength) + seedDescription,
+			},
+			function.Int64Parameter{
+				Name:                "words",
+				MarkdownDescription: fmt.Sprintf("The number of words of the passphrase, at most %d", maxPassphraseWords),
+			},
+			function.StringParameter{
+				Name:                "separator",
+				MarkdownDescription: "The separator placed between the words, such as `-`",
+			},
+		},
+		Return: function.StringReturn{},
+	}
+}
+
+func (f *GeneratePassphraseFunction) Run(ctx context.Context, req function.RunRequest, resp *function.RunResponse) {
+	var seed, separator string
+	var words int64
+
+	resp.Error = function.ConcatFuncErrors(req.Arguments.Get(ctx, &seed, &words, &separator))
+
+	if resp.Error != nil {
+		return
+	}
+
+	if resp.Error = checkSeed(0, seed); resp.Error != nil {
+		return
+	}
+	if words < 1 || words > maxPassphraseWords {
+		resp.Error = function.NewArgumentFuncError(1, fmt.Sprintf("words must be between 1 and %d", maxPassphraseWords))
+		return
+	}
+
+	resp.Error = function.ConcatFuncErrors(resp.Result.Set(ctx, generatePassphrase(newSeededRand(seed, "generate_passphrase"), int(words), separator)))
+}
+
+// generatePassphrase picks count words from the EFF large wordlist by
+// rolling dice with r.
+func generatePassphrase(r *seededRand, count int, separator string) string {
+	wordList := diceware.WordListEffLarge()
+	words := make([]string, count)
+	for i := range words {
+		roll := 0
+		for d := 0; d < wordList.Digits(); d++ {
+			roll = roll*10 + r.intn(6) + 1
+		}
+		words[i] = wordList.WordAt(roll)
+	}
+	return strings.Join(words, separator)
+}
@@ -0,0 +1,61 @@
+// Copyright (c) Plex, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package provider
+
+import (
+	"regexp"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-testing/helper/resource"
+	"github.com/hashicorp/terraform-plugin-testing/knownvalue"
+	"github.com/hashicorp/terraform-plugin-testing/statecheck"
+	"github.com/hashicorp/terraform-plugin-testing/tfversion"
+)
+
+func TestGeneratePassphraseFunction(t *testing.T) {
+	resource.UnitTest(t, resource.TestCase{
+		TerraformVersionChecks: []tfversion.TerraformVersionCheck{
+			tfversion.SkipBelow(tfversion.Version1_8_0),
+		},
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: `
+locals {
+  passphrase = provider::pwpusher::generate_passphrase("Xq3v9LmT2rWz8KpB", 5, "-")
+}
+
+output "test" {
+  value = {
+    words  = length(split("-", local.passphrase))
+    stable = local.passphrase == provider::pwpusher::generate_passphrase("Xq3v9LmT2rWz8KpB", 5, "-")
+  }
+}
+`,
+				ConfigStateChecks: []statecheck.StateCheck{
+					statecheck.ExpectKnownOutputValue("test", knownvalue.ObjectExact(map[string]knownvalue.Check{
+						"words":  knownvalue.Int64Exact(5),
+						"stable": knownvalue.Bool(true),
+					})),
+				},
+			},
+			{
+				Config: `
+output "test" {
+  value = provider::pwpusher::generate_passphrase("Xq3v9LmT2rWz8KpB", 0, " ")
+}
+`,
+				ExpectError: regexp.MustCompile("words must be between 1 and 32"),
+			},
+			{
+				Config: `
+output "test" {
+  value = provider::pwpusher::generate_passphrase("aaaaaaaaaaaaaaaaaaaa", 4, "-")
+}
+`,
+				ExpectError: regexp.MustCompile("seed must be at least 16 characters long"),
+			},
+		},
+	})
+}
@@ -0,0 +1,134 @@
+// Copyright (c) Plex, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package provider
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-framework/function"
+)
+
+// Ensure provider defined types fully satisfy framework interfaces.
+var _ function.Function = &GeneratePasswordFunction{}
+
+const (
+	lowerChars = "abcdefghijklmnopqrstuvwxyz"
+	upperChars = "ABCDEFGHIJKLMNOPQRSTUVWXYZ"
+	digitChars = "0123456789"
+
+	maxPasswordLength = 1024
+)
+
+// passwordCharsets maps the charset names accepted by generate_password to
+// the character classes a password of that charset draws from.
+var passwordCharsets = map[string][]string{
+	"alphanumeric": {lowerChars, upperChars, digitChars},
+	"alpha":        {lowerChars, upperChars},
+	"lower":        {lowerChars},
+	"upper":        {upperChars},
+	"numeric":      {digitChars},
+	"hex":          {"0123456789abcdef"},
+}
+
+func NewGeneratePasswordFunction() function.Function {
+	return &GeneratePasswordFunction{}
+}
+
+// GeneratePasswordFunction defines the function implementation.
+type GeneratePasswordFunction struct{}
+
+func (f *GeneratePasswordFunction) Metadata(ctx context.Context, req function.MetadataRequest, resp *function.MetadataResponse) {
+	resp.Name = "generate_password"
+}
+
+func (f *GeneratePasswordFunction) Definition(ctx context.Context, req function.DefinitionRequest, resp *function.DefinitionResponse) {
+	resp.Definition = function.Definition{
+		Summary:             "Generate a random password",
+		MarkdownDescription: "Generates a random password from a seed. Terraform requires functions to return the same result for the same arguments, so the same seed always yields the same password: pass a long random secret marked sensitive, such as a `sensitive` input variable, and change it to rotate the password. Provided it is long enough, the password contains at least one character of every class of the charset and at least one of the symbols",
+		Parameters: []function.Parameter{
+			function.StringParameter{
+				Name:                "seed",
+				MarkdownDescription: fmt.Sprintf("The seed the password is derived from, at least %d characters long, ", minSeedLength) + seedDescription,
+			},
+			function.Int64Parameter{
+				Name:                "length",
+				MarkdownDescription: fmt.Sprintf("The length of the password, at most %d", maxPasswordLength),
+			},
+			function.StringParameter{
+				Name:                "charset",
+				MarkdownDescription: "The characters to draw from, one of " + charsetNames(),
+			},
+			function.StringParameter{
+				Name:                "symbols",
+				MarkdownDescription: "The symbols to draw from in addition to the charset, such as `!@#$%`, or an empty string for none",
+			},
+		},
+		Return: function.StringReturn{},
+	}
+}
+
+func (f *GeneratePasswordFunction) Run(ctx context.Context, req function.RunRequest, resp *function.RunResponse) {
+	var seed, charset, symbols string
+	var length int64
+
+	resp.Error = function.ConcatFuncErrors(req.Arguments.Get(ctx, &seed, &length, &charset, &symbols))
+
+	if resp.Error != nil {
+		return
+	}
+
+	if resp.Error = checkSeed(0, seed); resp.Error != nil {
+		return
+	}
+	if length < 1 || length > maxPasswordLength {
+		resp.Error = function.NewArgumentFuncError(1, fmt.Sprintf("length must be between 1 and %d", maxPasswordLength))
+		return
+	}
+	classes, ok := passwordCharsets[charset]
+	if !ok {
+		resp.Error = function.NewArgumentFuncError(2, fmt.Sprintf("charset must be one of %s, got %q", charsetNames(), charset))
+		return
+	}
+	for _, c := range symbols {
+		if c < '!' || c > '~' {
+			resp.Error = function.NewArgumentFuncError(3, fmt.Sprintf("symbols must be printable ASCII characters, got %q", c))
+			return
+		}
+	}
+	if symbols != "" {
+		classes = append(append([]string{}, classes...), symbols)
+	}
+
+	resp.Error = function.ConcatFuncErrors(resp.Result.Set(ctx, generatePassword(newSeededRand(seed, "generate_password"), int(length), classes)))
+}
+
+// generatePassword draws length characters from the union of classes, with
+// at least one character of each class when length allows it.
+func generatePassword(r *seededRand, length int, classes []string) string {
+	all := strings.Join(classes, "")
+	password := make([]byte, 0, length)
+	if length >= len(classes) {
+		for _, class := range classes {
+			password = append(password, class[r.intn(len(class))])
+		}
+	}
+	for len(password) < length {
+		password = append(password, all[r.intn(len(all))])
+	}
+	r.shuffle(password)
+	return string(password)
+}
+
+// charsetNames returns the accepted charset names for use in messages.
+func charsetNames() string {
+	names := make([]string, 0, len(passwordCharsets))
+	for name := range passwordCharsets {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return strings.Join(names, ", ")
+}
@@ -0,0 +1,67 @@
+// Copyright (c) Plex, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package provider
+
+import (
+	"regexp"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-testing/helper/resource"
+	"github.com/hashicorp/terraform-plugin-testing/knownvalue"
+	"github.com/hashicorp/terraform-plugin-testing/statecheck"
+	"github.com/hashicorp/terraform-plugin-testing/tfversion"
+)
+
+func TestGeneratePasswordFunction(t *testing.T) {
+	resource.UnitTest(t, resource.TestCase{
+		TerraformVersionChecks: []tfversion.TerraformVersionCheck{
+			tfversion.SkipBelow(tfversion.Version1_8_0),
+		},
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: `
+locals {
+  password = provider::pwpusher::generate_password("Xq3v9LmT2rWz8KpB", 24, "alphanumeric", "!#%")
+}
+
+output "test" {
+  value = {
+    length  = length(local.password)
+    stable  = local.password == provider::pwpusher::generate_password("Xq3v9LmT2rWz8KpB", 24, "alphanumeric", "!#%")
+    symbol  = can(regex("[!#%]", local.password))
+    digit   = can(regex("[0-9]", local.password))
+    changed = local.password != provider::pwpusher::generate_password("Nf7cYh4sGd1eJa6U", 24, "alphanumeric", "!#%")
+  }
+}
+`,
+				ConfigStateChecks: []statecheck.StateCheck{
+					statecheck.ExpectKnownOutputValue("test", knownvalue.ObjectExact(map[string]knownvalue.Check{
+						"length":  knownvalue.Int64Exact(24),
+						"stable":  knownvalue.Bool(true),
+						"symbol":  knownvalue.Bool(true),
+						"digit":   knownvalue.Bool(true),
+						"changed": knownvalue.Bool(true),
+					})),
+				},
+			},
+			{
+				Config: `
+output "test" {
+  value = provider::pwpusher::generate_password("Xq3v9LmT2rWz8KpB", 8, "emoji", "")
+}
+`,
+				ExpectError: regexp.MustCompile("charset must be one of"),
+			},
+			{
+				Config: `
+output "test" {
+  value = provider::pwpusher::generate_password("aaaaaaaaaaaaaaaaaaaa", 24, "alphanumeric", "")
+}
+`,
+				ExpectError: regexp.MustCompile("seed must be at least 16 characters long"),
+			},
+		},
+	})
+}
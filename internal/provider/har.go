@@ -0,0 +1,262 @@
+// Copyright (c) Plex, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package provider
+
+import (
+	"encoding/json"
+	"errors"
+	"io/fs"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"terraform-provider-pwpusher/internal/client"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+)
+
+// harVersion is the version of the HAR format of the captures.
+const harVersion = "1.2"
+
+// harFile is a capture in the HAR format, see
+// http://www.softwareishard.com/blog/har-12-spec/.
+type harFile struct {
+	Log harLog `json:"log"`
+}
+
+type harLog struct {
+	Version string     `json:"version"`
+	Creator harCreator `json:"creator"`
+	Entries []harEntry `json:"entries"`
+}
+
+type harCreator struct {
+	Name    string `json:"name"`
+	Version string `json:"version"`
+}
+
+// harEntry is a request of the capture and its response. Requests the
+// service did not answer have a response with the status 0 and the error in
+// the _error field.
+type harEntry struct {
+	StartedDateTime string      `json:"startedDateTime"`
+	Time            float64     `json:"time"`
+	Request         harRequest  `json:"request"`
+	Response        harResponse `json:"response"`
+	Cache           struct{}    `json:"cache"`
+	Timings         harTimings  `json:"timings"`
+	Error           string      `json:"_error,omitempty"`
+}
+
+type harRequest struct {
+	Method      string       `json:"method"`
+	URL         string       `json:"url"`
+	HTTPVersion string       `json:"httpVersion"`
+	Cookies     []harNameVal `json:"cookies"`
+	Headers     []harNameVal `json:"headers"`
+	QueryString []harNameVal `json:"queryString"`
+	PostData    *harPostData `json:"postData,omitempty"`
+	HeadersSize int          `json:"headersSize"`
+	BodySize    int          `json:"bodySize"`
+}
+
+type harResponse struct {
+	Status      int          `json:"status"`
+	StatusText  string       `json:"statusText"`
+	HTTPVersion string       `json:"httpVersion"`
+	Cookies     []harNameVal `json:"cookies"`
+	Headers     []harNameVal `json:"headers"`
+	Content     harContent   `json:"content"`
+	RedirectURL string       `json:"redirectURL"`
+	HeadersSize int          `json:"headersSize"`
+	BodySize    int          `json:"bodySize"`
+}
+
+type harNameVal struct {
+	Name  string `json:"name"`
+	Value string `json:"value"`
+}
+
+type harPostData struct {
+	MimeType string `json:"mimeType"`
+	Text     string `json:"text"`
+}
+
+type harContent struct {
+	Size     int    `json:"size"`
+	MimeType string `json:"mimeType"`
+	Text     string `json:"text"`
+}
+
+type harTimings struct {
+	Send    float64 `json:"send"`
+	Wait    float64 `json:"wait"`
+	Receive float64 `json:"receive"`
+}
+
+// harCapture appends the entries of the requests of the provider to the HAR
+// file at path. The provider instances of a process capturing to the same
+// file share it, so that their entries are not lost.
+type harCapture struct {
+	mu      sync.Mutex
+	path    string
+	creator harCreator
+}
+
+// harCaptures are the captures of the process by path.
+var harCaptures = struct {
+	mu       sync.Mutex
+	captures map[string]*harCapture
+}{captures: map[string]*harCapture{}}
+
+// openHARCapture returns the capture to the file at path of the provider of
+// version, checking that the file is a capture when it exists already.
+func openHARCapture(path, version string) (*harCapture, error) {
+	harCaptures.mu.Lock()
+	defer harCaptures.mu.Unlock()
+	if capture, ok := harCaptures.captures[path]; ok {
+		return capture, nil
+	}
+	capture := &harCapture{path: path, creator: harCreator{Name: "terraform-provider-pwpusher", Version: version}}
+	if _, err := capture.read(); err != nil {
+		return nil, err
+	}
+	harCaptures.captures[path] = capture
+	return capture, nil
+}
+
+// read returns the capture in the file, empty when there is none yet.
+func (c *harCapture) read() (harFile, error) {
+	file := harFile{Log: harLog{Version: harVersion, Creator: c.creator, Entries: []harEntry{}}}
+	data, err := os.ReadFile(c.path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return file, nil
+	}
+	if err != nil {
+		return file, err
+	}
+	if err := json.Unmarshal(data, &file); err != nil {
+		return file, errors.New("the file exists and is not a HAR capture: " + err.Error())
+	}
+	return file, nil
+}
+
+// add appends entry to the file. The earlier runs of Terraform, such as the
+// plan of an apply, keep their entries.
+func (c *harCapture) add(entry harEntry) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	file, err := c.read()
+	if err != nil {
+		return err
+	}
+	file.Log.Entries = append(file.Log.Entries, entry)
+	data, err := json.MarshalIndent(file, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	// Replacing the file at once never leaves a truncated capture behind.
+	// Captures hold the tokens of pushes, so they are only readable by
+	// their owner.
+	temp, err := os.CreateTemp(filepath.Dir(c.path), filepath.Base(c.path)+".*")
+	if err != nil {
+		return err
+	}
+	if _, err := temp.Write(data); err != nil {
+		temp.Close()
+		os.Remove(temp.Name())
+		return err
+	}
+	if err := temp.Close(); err != nil {
+		os.Remove(temp.Name())
+		return err
+	}
+	return os.Rename(temp.Name(), c.path)
+}
+
+// harTransport records the requests sent over the network and their
+// responses to capture, redacted as debug_http logs them.
+type harTransport struct {
+	capture *harCapture
+	next    http.RoundTripper
+}
+
+func (t *harTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	requestData, _ := requestBody(req)
+	start := time.Now()
+	res, err := t.next.RoundTrip(req)
+	elapsed := float64(time.Since(start).Microseconds()) / 1000
+
+	entry := harEntry{
+		StartedDateTime: start.UTC().Format(time.RFC3339Nano),
+		Time:            elapsed,
+		Request: harRequest{
+			Method:      req.Method,
+			URL:         req.URL.String(),
+			HTTPVersion: req.Proto,
+			Cookies:     []harNameVal{},
+			Headers:     harHeaders(redactHeaders(req.Header)),
+			QueryString: []harNameVal{},
+			HeadersSize: -1,
+			BodySize:    len(requestData),
+		},
+		Timings: harTimings{Wait: elapsed},
+	}
+	for name, values := range req.URL.Query() {
+		for _, value := range values {
+			entry.Request.QueryString = append(entry.Request.QueryString, harNameVal{Name: name, Value: value})
+		}
+	}
+	sort.Slice(entry.Request.QueryString, func(i, j int) bool {
+		return entry.Request.QueryString[i].Name < entry.Request.QueryString[j].Name
+	})
+	if len(requestData) > 0 {
+		entry.Request.PostData = &harPostData{MimeType: req.Header.Get("Content-Type"), Text: redactBody(req.Header, requestData)}
+	}
+
+	if err != nil {
+		entry.Response = harResponse{Cookies: []harNameVal{}, Headers: []harNameVal{}, HeadersSize: -1, BodySize: -1}
+		entry.Error = err.Error()
+	} else {
+		responseData, _ := responseBody(res)
+		mimeType := res.Header.Get("Content-Type")
+		if mimeType == "" {
+			mimeType = "application/octet-stream"
+		}
+		entry.Response = harResponse{
+			Status:      res.StatusCode,
+			StatusText:  http.StatusText(res.StatusCode),
+			HTTPVersion: res.Proto,
+			Cookies:     []harNameVal{},
+			Headers:     harHeaders(redactHeaders(res.Header)),
+			Content:     harContent{Size: len(responseData), MimeType: mimeType, Text: redactBody(res.Header, responseData)},
+			RedirectURL: res.Header.Get("Location"),
+			HeadersSize: -1,
+			BodySize:    len(responseData),
+		}
+	}
+
+	// The capture is best effort, it must not fail the requests it records.
+	if captureErr := t.capture.add(entry); captureErr != nil {
+		tflog.SubsystemWarn(req.Context(), client.LogSubsystem, "Unable to record the request in the HAR capture", map[string]interface{}{
+			"har_path": t.capture.path,
+			"error":    captureErr.Error(),
+		})
+	}
+	return res, err
+}
+
+// harHeaders returns header as HAR name and value pairs, sorted by name.
+func harHeaders(header http.Header) []harNameVal {
+	pairs := []harNameVal{}
+	for _, name := range sortedKeys(header) {
+		for _, value := range header[name] {
+			pairs = append(pairs, harNameVal{Name: name, Value: value})
+		}
+	}
+	return pairs
+}
@@ -0,0 +1,113 @@
+// Copyright (c) Plex, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package provider
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func readHARFile(t *testing.T, path string) harFile {
+	t.Helper()
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var file harFile
+	if err := json.Unmarshal(data, &file); err != nil {
+		t.Fatal(err)
+	}
+	return file
+}
+
+func TestHARTransport(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusCreated)
+		_, _ = w.Write([]byte(`{"url_token":"token1","payload":"top secret"}`))
+	}))
+	defer server.Close()
+
+	harPath := filepath.Join(t.TempDir(), "capture.har")
+	capture, err := openHARCapture(harPath, "1.2.3")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if again, _ := openHARCapture(harPath, "1.2.3"); again != capture {
+		t.Error("got another capture of the same file")
+	}
+	httpClient := &http.Client{Transport: &harTransport{capture: capture, next: http.DefaultTransport}}
+
+	req, err := http.NewRequest(http.MethodPost, server.URL+"/p.json?locale=fr", strings.NewReader(`{"push":{"payload":"top secret"}}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("X-User-Token", "api-token")
+	res, err := httpClient.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	body, _ := io.ReadAll(res.Body)
+	res.Body.Close()
+	if string(body) != `{"url_token":"token1","payload":"top secret"}` {
+		t.Errorf("got response body %s", body)
+	}
+
+	// A later run of Terraform, in another process, adds its requests.
+	later := &harCapture{path: harPath}
+	closed := httptest.NewServer(http.NotFoundHandler())
+	closed.Close()
+	if _, err := (&http.Client{Transport: &harTransport{capture: later, next: http.DefaultTransport}}).Get(closed.URL + "/api/v1/version.json"); err == nil {
+		t.Fatal("expected an error from a closed server")
+	}
+
+	data, err := os.ReadFile(harPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(string(data), "top secret") || strings.Contains(string(data), "api-token") {
+		t.Errorf("got secrets in the capture: %s", data)
+	}
+	if info, err := os.Stat(harPath); err != nil || info.Mode().Perm() != 0o600 {
+		t.Errorf("got file mode %v, %v, want 0600", info.Mode().Perm(), err)
+	}
+
+	file := readHARFile(t, harPath)
+	if file.Log.Version != harVersion || file.Log.Creator.Version != "1.2.3" {
+		t.Errorf("got log %+v", file.Log)
+	}
+	if len(file.Log.Entries) != 2 {
+		t.Fatalf("got %d entries, want 2", len(file.Log.Entries))
+	}
+	created, failed := file.Log.Entries[0], file.Log.Entries[1]
+	if created.Request.Method != http.MethodPost || created.Request.PostData == nil || created.Request.PostData.Text != `{"push":{"payload":"[REDACTED]"}}` {
+		t.Errorf("got request %+v", created.Request)
+	}
+	if len(created.Request.QueryString) != 1 || created.Request.QueryString[0] != (harNameVal{Name: "locale", Value: "fr"}) {
+		t.Errorf("got query string %v", created.Request.QueryString)
+	}
+	if created.Response.Status != http.StatusCreated || created.Response.Content.Text != `{"payload":"[REDACTED]","url_token":"token1"}` {
+		t.Errorf("got response %+v", created.Response)
+	}
+	if failed.Response.Status != 0 || failed.Error == "" || failed.Request.PostData != nil {
+		t.Errorf("got entry %+v", failed)
+	}
+}
+
+func TestOpenHARCaptureInvalidFile(t *testing.T) {
+	harPath := filepath.Join(t.TempDir(), "capture.har")
+	if err := os.WriteFile(harPath, []byte("not a capture"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := openHARCapture(harPath, "1.2.3"); err == nil {
+		t.Fatal("expected an error for a file that is not a capture")
+	}
+}
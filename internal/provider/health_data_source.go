@@ -0,0 +1,144 @@
+// Copyright (c) Plex, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package provider
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/hashicorp/terraform-plugin-framework-timeouts/datasource/timeouts"
+	"github.com/hashicorp/terraform-plugin-framework/datasource"
+	"github.com/hashicorp/terraform-plugin-framework/datasource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+)
+
+// Ensure provider defined types fully satisfy framework interfaces.
+var _ datasource.DataSource = &HealthDataSource{}
+
+func NewHealthDataSource() datasource.DataSource {
+	return &HealthDataSource{}
+}
+
+// HealthDataSource defines the data source implementation.
+type HealthDataSource struct {
+	providerData ProviderData
+}
+
+// HealthDataSourceModel describes the data source data model.
+type HealthDataSourceModel struct {
+	Url                types.String   `tfsdk:"url"`
+	Healthy            types.Bool     `tfsdk:"healthy"`
+	Message            types.String   `tfsdk:"message"`
+	ApplicationVersion types.String   `tfsdk:"application_version"`
+	ApiVersion         types.String   `tfsdk:"api_version"`
+	Edition            types.String   `tfsdk:"edition"`
+	Timeouts           timeouts.Value `tfsdk:"timeouts"`
+}
+
+func (d *HealthDataSource) Metadata(ctx context.Context, req datasource.MetadataRequest, resp *datasource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_health"
+}
+
+func (d *HealthDataSource) Schema(ctx context.Context, req datasource.SchemaRequest, resp *datasource.SchemaResponse) {
+	resp.Schema = schema.Schema{
+		// This description is used by the documentation generator and the language server.
+		MarkdownDescription: "Checks that the pwpusher service is reachable. An unreachable service does not fail the read, instead `healthy` is `false` and `message` explains why, so that modules can fail with a precondition",
+
+		Attributes: map[string]schema.Attribute{
+			"url": schema.StringAttribute{
+				MarkdownDescription: "The URL of the pwpusher service that was checked",
+				Computed:            true,
+			},
+			"healthy": schema.BoolAttribute{
+				MarkdownDescription: "If the pwpusher service responded to the version request",
+				Computed:            true,
+			},
+			"message": schema.StringAttribute{
+				MarkdownDescription: "The reason the service is not healthy, empty when it is",
+				Computed:            true,
+			},
+			"application_version": schema.StringAttribute{
+				MarkdownDescription: "The version of the pwpusher app",
+				Computed:            true,
+			},
+			"api_version": schema.StringAttribute{
+				MarkdownDescription: "The version of the pwpusher API",
+				Computed:            true,
+			},
+			"edition": schema.StringAttribute{
+				MarkdownDescription: "The edition of the pwpusher app, such as `oss` or `pro`",
+				Computed:            true,
+			},
+		},
+
+		Blocks: map[string]schema.Block{
+			"timeouts": timeouts.Block(ctx),
+		},
+	}
+}
+
+func (d *HealthDataSource) Configure(ctx context.Context, req datasource.ConfigureRequest, resp *datasource.ConfigureResponse) {
+	// Prevent panic if the provider has not been configured.
+	if req.ProviderData == nil {
+		return
+	}
+
+	providerData, ok := req.ProviderData.(ProviderData)
+
+	if !ok {
+		resp.Diagnostics.AddError(
+			"Unexpected Data Source Configure Type",
+			fmt.Sprintf("Expected ProviderData, got: %T. Please report this issue to the provider developers.", req.ProviderData),
+		)
+
+		return
+	}
+
+	d.providerData = providerData
+}
+
+func (d *HealthDataSource) Read(ctx context.Context, req datasource.ReadRequest, resp *datasource.ReadResponse) {
+	var data HealthDataSourceModel
+
+	// Read Terraform configuration data into the model
+	resp.Diagnostics.Append(req.Config.Get(ctx, &data)...)
+
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	readTimeout, diags := data.Timeouts.Read(ctx, defaultDataSourceReadTimeout)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	ctx, cancel := context.WithTimeout(ctx, readTimeout)
+	defer cancel()
+
+	ctx, op := d.providerData.operationContext(ctx, logSubsystemDataSources, "pwpusher_health read")
+	defer op.end(&resp.Diagnostics)
+
+	data.Url = d.providerData.url
+	data.Healthy = types.BoolValue(true)
+	data.Message = types.StringValue("")
+
+	// Unlike the other uses of the version, the check reaches the service on
+	// every read rather than reusing what the provider remembers.
+	version, err := d.providerData.apiClient().Version(ctx)
+	if err != nil {
+		tflog.SubsystemWarn(ctx, logSubsystemDataSources, "pwpusher service is not healthy", map[string]interface{}{
+			"error": err.Error(),
+		})
+		data.Healthy = types.BoolValue(false)
+		data.Message = types.StringValue(fmt.Sprintf("Unable to reach %s: %s", d.providerData.url.ValueString(), err))
+	}
+
+	data.ApplicationVersion = types.StringValue(version.ApplicationVersion)
+	data.ApiVersion = types.StringValue(version.ApiVersion)
+	data.Edition = types.StringValue(version.Edition)
+
+	// Save data into Terraform state
+	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+}
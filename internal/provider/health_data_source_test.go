@@ -0,0 +1,32 @@
+// Copyright (c) Plex, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package provider
+
+import (
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-testing/helper/resource"
+)
+
+func TestAccHealthDataSource(t *testing.T) {
+	resource.Test(t, resource.TestCase{
+		PreCheck:                 func() { testAccPreCheck(t) },
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			// Read testing
+			{
+				Config: testAccHealthDataSourceConfig,
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("data.pwpusher_health.test", "healthy", "true"),
+					resource.TestCheckResourceAttr("data.pwpusher_health.test", "message", ""),
+					resource.TestCheckResourceAttrSet("data.pwpusher_health.test", "application_version"),
+				),
+			},
+		},
+	})
+}
+
+const testAccHealthDataSourceConfig = `
+data "pwpusher_health" "test" {}
+`
@@ -0,0 +1,225 @@
+// Copyright (c) Plex, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package provider
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/json"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"net/url"
+	"strings"
+	"terraform-provider-pwpusher/internal/client"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+)
+
+// redactedHTTPValue replaces the secrets of the requests and responses the
+// provider logs or captures.
+const redactedHTTPValue = "[REDACTED]"
+
+// maxDebugBodyBytes is the size of the longest body logged, longer ones are
+// truncated.
+const maxDebugBodyBytes = 64 << 10
+
+// secretFields are the JSON fields and form keys of request and response
+// bodies holding secrets: the payloads and passphrases of pushes, in both
+// APIs, and the credentials of the OAuth2 token endpoint.
+var secretFields = map[string]bool{
+	"payload":       true,
+	"passphrase":    true,
+	"password":      true,
+	"api_token":     true,
+	"client_secret": true,
+	"access_token":  true,
+	"refresh_token": true,
+	"id_token":      true,
+}
+
+// secretHeaderWords are the words of the names of headers holding
+// credentials, such as Authorization, X-User-Token and Cookie, including
+// custom headers of the provider.
+var secretHeaderWords = []string{"auth", "token", "secret", "key", "cookie", "password", "session"}
+
+// redactHeaders returns a copy of header with the values of the headers that
+// may hold credentials redacted.
+func redactHeaders(header http.Header) http.Header {
+	redacted := make(http.Header, len(header))
+	for name, values := range header {
+		lower := strings.ToLower(name)
+		secret := false
+		for _, word := range secretHeaderWords {
+			if strings.Contains(lower, word) {
+				secret = true
+				break
+			}
+		}
+		if !secret {
+			redacted[name] = append([]string(nil), values...)
+			continue
+		}
+		redacted[name] = make([]string, len(values))
+		for i := range values {
+			redacted[name][i] = redactedHTTPValue
+		}
+	}
+	return redacted
+}
+
+// redactBody returns body, sent or received with header, with its secret
+// fields redacted. Bodies whose secrets cannot be told apart, because they
+// are neither JSON, forms nor text, are redacted entirely.
+func redactBody(header http.Header, body []byte) string {
+	if len(body) == 0 {
+		return ""
+	}
+	if strings.EqualFold(header.Get("Content-Encoding"), "gzip") {
+		reader, err := gzip.NewReader(bytes.NewReader(body))
+		if err != nil {
+			return redactedHTTPValue
+		}
+		body, err = io.ReadAll(reader)
+		if err != nil {
+			return redactedHTTPValue
+		}
+	}
+
+	mediaType, _, _ := mime.ParseMediaType(header.Get("Content-Type"))
+	switch {
+	case mediaType == "application/json" || strings.HasSuffix(mediaType, "+json"):
+		var value any
+		if err := json.Unmarshal(body, &value); err != nil {
+			return redactedHTTPValue
+		}
+		redacted, err := json.Marshal(redactJSON(value))
+		if err != nil {
+			return redactedHTTPValue
+		}
+		return string(redacted)
+	case mediaType == "application/x-www-form-urlencoded":
+		form, err := url.ParseQuery(string(body))
+		if err != nil {
+			return redactedHTTPValue
+		}
+		for key := range form {
+			if secretFields[strings.ToLower(key)] {
+				form[key] = []string{redactedHTTPValue}
+			}
+		}
+		return form.Encode()
+	case strings.HasPrefix(mediaType, "text/"):
+		// The error pages of the service and of proxies in front of it.
+		return string(body)
+	}
+	return redactedHTTPValue
+}
+
+// redactJSON returns value, a decoded JSON value, with the secret fields of
+// its objects redacted. Secret fields holding objects, like the password of
+// the legacy API, have their own secret fields redacted.
+func redactJSON(value any) any {
+	switch value := value.(type) {
+	case map[string]any:
+		for key, field := range value {
+			switch field.(type) {
+			case map[string]any, []any:
+				value[key] = redactJSON(field)
+			default:
+				if secretFields[strings.ToLower(key)] && field != nil {
+					value[key] = redactedHTTPValue
+				}
+			}
+		}
+	case []any:
+		for i := range value {
+			value[i] = redactJSON(value[i])
+		}
+	}
+	return value
+}
+
+// requestBody returns a copy of the body of req, leaving req untouched, and
+// whether it could be read again.
+func requestBody(req *http.Request) ([]byte, bool) {
+	if req.Body == nil || req.Body == http.NoBody {
+		return nil, true
+	}
+	if req.GetBody == nil {
+		return nil, false
+	}
+	body, err := req.GetBody()
+	if err != nil {
+		return nil, false
+	}
+	defer body.Close()
+	data, err := io.ReadAll(body)
+	return data, err == nil
+}
+
+// responseBody reads the body of res, up to the limit of the size of the
+// responses of the client, and replaces it with one reading the same bytes.
+func responseBody(res *http.Response) ([]byte, error) {
+	data, err := io.ReadAll(io.LimitReader(res.Body, client.DefaultMaxResponseBytes+1))
+	res.Body = struct {
+		io.Reader
+		io.Closer
+	}{io.MultiReader(bytes.NewReader(data), res.Body), res.Body}
+	return data, err
+}
+
+// truncateBody returns body cut at maxDebugBodyBytes.
+func truncateBody(body string) string {
+	if len(body) <= maxDebugBodyBytes {
+		return body
+	}
+	return fmt.Sprintf("%s... (%d bytes truncated)", body[:maxDebugBodyBytes], len(body)-maxDebugBodyBytes)
+}
+
+// debugTransport logs the requests sent over the network and their
+// responses, with their headers and bodies redacted, to the client
+// subsystem at the debug level.
+type debugTransport struct {
+	next http.RoundTripper
+}
+
+func (t *debugTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	ctx := req.Context()
+	fields := map[string]interface{}{
+		"method":  req.Method,
+		"url":     req.URL.String(),
+		"headers": redactHeaders(req.Header),
+	}
+	if body, ok := requestBody(req); ok {
+		fields["body"] = truncateBody(redactBody(req.Header, body))
+	} else {
+		fields["body"] = "(unavailable)"
+	}
+	tflog.SubsystemDebug(ctx, client.LogSubsystem, "Sending pwpusher HTTP request", fields)
+
+	start := time.Now()
+	res, err := t.next.RoundTrip(req)
+	fields = map[string]interface{}{
+		"method":      req.Method,
+		"url":         req.URL.String(),
+		"duration_ms": time.Since(start).Milliseconds(),
+	}
+	if err != nil {
+		fields["error"] = err.Error()
+		tflog.SubsystemDebug(ctx, client.LogSubsystem, "pwpusher HTTP request failed", fields)
+		return res, err
+	}
+	fields["status"] = res.StatusCode
+	fields["headers"] = redactHeaders(res.Header)
+	body, bodyErr := responseBody(res)
+	if bodyErr != nil {
+		fields["body_error"] = bodyErr.Error()
+	}
+	fields["body"] = truncateBody(redactBody(res.Header, body))
+	tflog.SubsystemDebug(ctx, client.LogSubsystem, "Received pwpusher HTTP response", fields)
+	return res, nil
+}
@@ -0,0 +1,162 @@
+// Copyright (c) Plex, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package provider
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-log/tflogtest"
+)
+
+func TestRedactBody(t *testing.T) {
+	var compressed bytes.Buffer
+	writer := gzip.NewWriter(&compressed)
+	_, _ = writer.Write([]byte(`{"push":{"payload":"top secret"}}`))
+	writer.Close()
+
+	for name, test := range map[string]struct {
+		header http.Header
+		body   string
+		want   string
+	}{
+		"current": {
+			header: http.Header{"Content-Type": {"application/json"}},
+			body:   `{"push":{"payload":"top secret","passphrase":"open sesame","expire_after_views":5,"name":"db"}}`,
+			want:   `{"push":{"expire_after_views":5,"name":"db","passphrase":"[REDACTED]","payload":"[REDACTED]"}}`,
+		},
+		"legacy": {
+			header: http.Header{"Content-Type": {"application/json; charset=utf-8"}},
+			body:   `{"password":{"payload":"top secret","retrieval_step":true}}`,
+			want:   `{"password":{"payload":"[REDACTED]","retrieval_step":true}}`,
+		},
+		"list": {
+			header: http.Header{"Content-Type": {"application/json"}},
+			body:   `[{"url_token":"token1","payload":"top secret"},{"url_token":"token2","payload":null}]`,
+			want:   `[{"payload":"[REDACTED]","url_token":"token1"},{"payload":null,"url_token":"token2"}]`,
+		},
+		"gzip": {
+			header: http.Header{"Content-Type": {"application/json"}, "Content-Encoding": {"gzip"}},
+			body:   compressed.String(),
+			want:   `{"push":{"payload":"[REDACTED]"}}`,
+		},
+		"form": {
+			header: http.Header{"Content-Type": {"application/x-www-form-urlencoded"}},
+			body:   "client_id=terraform&client_secret=hunter2&grant_type=client_credentials",
+			want:   "client_id=terraform&client_secret=%5BREDACTED%5D&grant_type=client_credentials",
+		},
+		"text": {
+			header: http.Header{"Content-Type": {"text/html"}},
+			body:   "<h1>Bad Gateway</h1>",
+			want:   "<h1>Bad Gateway</h1>",
+		},
+		"invalid json": {
+			header: http.Header{"Content-Type": {"application/json"}},
+			body:   `{"payload":"top secret"`,
+			want:   redactedHTTPValue,
+		},
+		"binary": {
+			header: http.Header{"Content-Type": {"application/octet-stream"}},
+			body:   "top secret",
+			want:   redactedHTTPValue,
+		},
+		"empty": {
+			header: http.Header{},
+			want:   "",
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			if got := redactBody(test.header, []byte(test.body)); got != test.want {
+				t.Errorf("got %s, want %s", got, test.want)
+			}
+		})
+	}
+}
+
+func TestRedactHeaders(t *testing.T) {
+	header := http.Header{
+		"Authorization":   {"Basic dXNlcjpwYXNz"},
+		"X-User-Email":    {"user@example.com"},
+		"X-User-Token":    {"api-token"},
+		"Cookie":          {"_session=abc"},
+		"X-Api-Key":       {"key"},
+		"Content-Type":    {"application/json"},
+		"X-Request-Id":    {"abc123"},
+		"Accept-Language": {"fr"},
+	}
+	redacted := redactHeaders(header)
+	for _, name := range []string{"Authorization", "X-User-Token", "Cookie", "X-Api-Key"} {
+		if got := redacted.Get(name); got != redactedHTTPValue {
+			t.Errorf("got %s %q, want it redacted", name, got)
+		}
+	}
+	for _, name := range []string{"X-User-Email", "Content-Type", "X-Request-Id", "Accept-Language"} {
+		if got := redacted.Get(name); got != header.Get(name) {
+			t.Errorf("got %s %q, want %q", name, got, header.Get(name))
+		}
+	}
+	if header.Get("Authorization") == redactedHTTPValue {
+		t.Error("redacted the headers in place")
+	}
+}
+
+func TestDebugTransport(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		if !strings.Contains(string(body), "top secret") {
+			t.Errorf("got request body %s", body)
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("Set-Cookie", "_session=abc")
+		w.WriteHeader(http.StatusCreated)
+		_, _ = w.Write([]byte(`{"url_token":"token1","payload":"top secret"}`))
+	}))
+	defer server.Close()
+
+	var logs bytes.Buffer
+	ctx := withLogSubsystems(tflogtest.RootLogger(context.Background(), &logs))
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, server.URL+"/p.json", strings.NewReader(`{"push":{"payload":"top secret"}}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("X-User-Token", "api-token")
+
+	res, err := (&http.Client{Transport: &debugTransport{next: http.DefaultTransport}}).Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	body, err := io.ReadAll(res.Body)
+	res.Body.Close()
+	if err != nil || string(body) != `{"url_token":"token1","payload":"top secret"}` {
+		t.Errorf("got response body %s, %v", body, err)
+	}
+
+	output := logs.String()
+	if strings.Contains(output, "api-token") || strings.Contains(output, "_session=abc") {
+		t.Errorf("got credentials in the logs: %s", output)
+	}
+	entries, err := tflogtest.MultilineJSONDecode(&logs)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 2 {
+		t.Fatalf("got %d log entries, want 2", len(entries))
+	}
+	if got := entries[0]["body"]; got != `{"push":{"payload":"[REDACTED]"}}` {
+		t.Errorf("got request body %v", got)
+	}
+	if got := entries[1]["body"]; got != `{"payload":"[REDACTED]","url_token":"token1"}` {
+		t.Errorf("got response body %v", got)
+	}
+	if got := entries[1]["status"]; got != float64(http.StatusCreated) {
+		t.Errorf("got status %v", got)
+	}
+}
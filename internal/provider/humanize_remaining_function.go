@@ -0,0 +1,84 @@
+// Copyright (c) Plex, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package provider
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-framework/function"
+)
+
+// Ensure provider defined types fully satisfy framework interfaces.
+var _ function.Function = &HumanizeRemainingFunction{}
+
+func NewHumanizeRemainingFunction() function.Function {
+	return &HumanizeRemainingFunction{}
+}
+
+// HumanizeRemainingFunction defines the function implementation.
+type HumanizeRemainingFunction struct{}
+
+func (f *HumanizeRemainingFunction) Metadata(ctx context.Context, req function.MetadataRequest, resp *function.MetadataResponse) {
+	resp.Name = "humanize_remaining"
+}
+
+func (f *HumanizeRemainingFunction) Definition(ctx context.Context, req function.DefinitionRequest, resp *function.DefinitionResponse) {
+	resp.Definition = function.Definition{
+		Summary:             "Describe the remaining lifetime of a push",
+		MarkdownDescription: "Turns the days and views remaining of a push into a readable string such as `3 days or 2 views left`. Either argument may be null to leave it out",
+		Parameters: []function.Parameter{
+			function.Int64Parameter{
+				Name:                "days_remaining",
+				MarkdownDescription: "The `days_remaining` of the push",
+				AllowNullValue:      true,
+			},
+			function.Int64Parameter{
+				Name:                "views_remaining",
+				MarkdownDescription: "The `views_remaining` of the push",
+				AllowNullValue:      true,
+			},
+		},
+		Return: function.StringReturn{},
+	}
+}
+
+func (f *HumanizeRemainingFunction) Run(ctx context.Context, req function.RunRequest, resp *function.RunResponse) {
+	var days, views *int64
+
+	resp.Error = function.ConcatFuncErrors(req.Arguments.Get(ctx, &days, &views))
+
+	if resp.Error != nil {
+		return
+	}
+
+	if days == nil && views == nil {
+		resp.Error = function.NewFuncError("at least one of days_remaining and views_remaining must be set")
+		return
+	}
+
+	resp.Error = function.ConcatFuncErrors(resp.Result.Set(ctx, humanizeRemaining(days, views)))
+}
+
+// humanizeRemaining describes the remaining days and views, leaving out the
+// nil ones.
+func humanizeRemaining(days, views *int64) string {
+	var parts []string
+	if days != nil {
+		parts = append(parts, pluralize(*days, "day"))
+	}
+	if views != nil {
+		parts = append(parts, pluralize(*views, "view"))
+	}
+	return strings.Join(parts, " or ") + " left"
+}
+
+// pluralize formats count followed by noun, in plural unless count is one.
+func pluralize(count int64, noun string) string {
+	if count == 1 {
+		return fmt.Sprintf("%d %s", count, noun)
+	}
+	return fmt.Sprintf("%d %ss", count, noun)
+}
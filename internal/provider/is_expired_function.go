@@ -0,0 +1,103 @@
+// Copyright (c) Plex, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package provider
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-framework/function"
+)
+
+// Ensure provider defined types fully satisfy framework interfaces.
+var _ function.Function = &IsExpiredFunction{}
+
+func NewIsExpiredFunction() function.Function {
+	return &IsExpiredFunction{}
+}
+
+// IsExpiredFunction defines the function implementation.
+type IsExpiredFunction struct{}
+
+func (f *IsExpiredFunction) Metadata(ctx context.Context, req function.MetadataRequest, resp *function.MetadataResponse) {
+	resp.Name = "is_expired"
+}
+
+func (f *IsExpiredFunction) Definition(ctx context.Context, req function.DefinitionRequest, resp *function.DefinitionResponse) {
+	resp.Definition = function.Definition{
+		Summary:             "Check whether a push is expired at a given time",
+		MarkdownDescription: "Returns whether a push is expired at the given time, either because the service already expired it or because its `expire_after_days` have elapsed by then. Functions cannot read the clock, pass `plantimestamp()` to check against the time of the plan. Expiry by views cannot be predicted and is only reflected through `expired_on`",
+		Parameters: []function.Parameter{
+			function.StringParameter{
+				Name:                "expired_on",
+				MarkdownDescription: "The `expired_on` timestamp of the push, null or empty when it has not expired",
+				AllowNullValue:      true,
+			},
+			function.StringParameter{
+				Name:                "created_at",
+				MarkdownDescription: "The `created_at` timestamp of the push",
+			},
+			function.Int64Parameter{
+				Name:                "expire_after_days",
+				MarkdownDescription: "The `expire_after_days` of the push",
+			},
+			function.StringParameter{
+				Name:                "at",
+				MarkdownDescription: "The RFC 3339 timestamp to check the expiry at",
+			},
+		},
+		Return: function.BoolReturn{},
+	}
+}
+
+func (f *IsExpiredFunction) Run(ctx context.Context, req function.RunRequest, resp *function.RunResponse) {
+	var expiredOn *string
+	var createdAt, rawAt string
+	var days int64
+
+	resp.Error = function.ConcatFuncErrors(req.Arguments.Get(ctx, &expiredOn, &createdAt, &days, &rawAt))
+
+	if resp.Error != nil {
+		return
+	}
+
+	at, err := time.Parse(time.RFC3339, rawAt)
+	if err != nil {
+		resp.Error = function.NewArgumentFuncError(3, fmt.Sprintf("at must be an RFC 3339 timestamp: %s", err))
+		return
+	}
+	if expiredOn != nil && *expiredOn != "" {
+		expired, err := time.Parse(time.RFC3339, *expiredOn)
+		if err != nil {
+			resp.Error = function.NewArgumentFuncError(0, fmt.Sprintf("expired_on must be an RFC 3339 timestamp: %s", err))
+			return
+		}
+		if !at.Before(expired) {
+			resp.Error = function.ConcatFuncErrors(resp.Result.Set(ctx, true))
+			return
+		}
+	}
+
+	expiry, funcErr := pushExpiry(createdAt, days, 1)
+	if funcErr != nil {
+		resp.Error = funcErr
+		return
+	}
+
+	resp.Error = function.ConcatFuncErrors(resp.Result.Set(ctx, !at.Before(expiry)))
+}
+
+// pushExpiry returns the time a push created at createdAt expires after
+// days. argument is the position of the created_at argument for errors.
+func pushExpiry(createdAt string, days int64, argument int64) (time.Time, *function.FuncError) {
+	created, err := time.Parse(time.RFC3339, createdAt)
+	if err != nil {
+		return time.Time{}, function.NewArgumentFuncError(argument, fmt.Sprintf("created_at must be an RFC 3339 timestamp: %s", err))
+	}
+	if days < 0 {
+		return time.Time{}, function.NewArgumentFuncError(argument+1, "expire_after_days must not be negative")
+	}
+	return created.AddDate(0, 0, int(days)), nil
+}
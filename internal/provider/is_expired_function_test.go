@@ -0,0 +1,44 @@
+// Copyright (c) Plex, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package provider
+
+import (
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-testing/helper/resource"
+	"github.com/hashicorp/terraform-plugin-testing/knownvalue"
+	"github.com/hashicorp/terraform-plugin-testing/statecheck"
+	"github.com/hashicorp/terraform-plugin-testing/tfversion"
+)
+
+func TestIsExpiredFunction(t *testing.T) {
+	resource.UnitTest(t, resource.TestCase{
+		TerraformVersionChecks: []tfversion.TerraformVersionCheck{
+			tfversion.SkipBelow(tfversion.Version1_8_0),
+		},
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: `
+output "active" {
+  value = provider::pwpusher::is_expired(null, "2024-05-01T12:00:00.000Z", 7, "2024-05-08T11:59:59Z")
+}
+
+output "elapsed" {
+  value = provider::pwpusher::is_expired("", "2024-05-01T12:00:00.000Z", 7, "2024-05-08T12:00:00Z")
+}
+
+output "expired_by_views" {
+  value = provider::pwpusher::is_expired("2024-05-02T08:00:00.000Z", "2024-05-01T12:00:00.000Z", 7, "2024-05-03T00:00:00Z")
+}
+`,
+				ConfigStateChecks: []statecheck.StateCheck{
+					statecheck.ExpectKnownOutputValue("active", knownvalue.Bool(false)),
+					statecheck.ExpectKnownOutputValue("elapsed", knownvalue.Bool(true)),
+					statecheck.ExpectKnownOutputValue("expired_by_views", knownvalue.Bool(true)),
+				},
+			},
+		},
+	})
+}
@@ -0,0 +1,205 @@
+// Copyright (c) Plex, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package provider
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	"github.com/hashicorp/terraform-plugin-framework-timeouts/datasource/timeouts"
+	"github.com/hashicorp/terraform-plugin-framework/datasource"
+	"github.com/hashicorp/terraform-plugin-framework/datasource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+// Ensure provider defined types fully satisfy framework interfaces.
+var _ datasource.DataSource = &LocalesDataSource{}
+
+// defaultLocale is the locale the pwpusher app falls back to.
+const defaultLocale = "en"
+
+// Sources of the locales of the pwpusher_locales data source.
+const (
+	localesSourceServer  = "server"
+	localesSourceBuiltin = "builtin"
+)
+
+// supportedLocales are the UI locales shipped with the pwpusher app, keyed by
+// locale code with the native name of the language as value. They are the
+// fallback for services whose home page has no language menu to read their
+// locales from, and for the validations made before any request.
+var supportedLocales = map[string]string{
+	"ca":    "Català",
+	"cs":    "Čeština",
+	"da":    "Dansk",
+	"de":    "Deutsch",
+	"en":    "English",
+	"en-GB": "English (UK)",
+	"es":    "Español",
+	"eu":    "Euskara",
+	"fi":    "Suomi",
+	"fr":    "Français",
+	"hi":    "हिन्दी",
+	"hu":    "Magyar",
+	"id":    "Indonesia",
+	"is":    "Íslenska",
+	"it":    "Italiano",
+	"ja":    "日本語",
+	"ko":    "한국어",
+	"lv":    "Latviski",
+	"nl":    "Nederlands",
+	"no":    "Norsk",
+	"pl":    "Polski",
+	"pt-BR": "Português",
+	"pt-PT": "Português (Portugal)",
+	"ro":    "Română",
+	"ru":    "Русский",
+	"sk":    "Slovenčina",
+	"sr":    "Srpski",
+	"sv":    "Svenska",
+	"th":    "ไทย",
+	"uk":    "Українська",
+	"ur":    "اردو",
+	"zh-CN": "中文",
+}
+
+// checkLocale returns an error for the attribute at attrPath when locale is
+// not one of locales.
+func checkLocale(attrPath path.Path, locale string, locales map[string]string) diag.Diagnostics {
+	var diags diag.Diagnostics
+	if _, ok := locales[locale]; !ok {
+		diags.AddAttributeError(
+			attrPath,
+			"Unsupported Locale",
+			fmt.Sprintf("The pwpusher service does not support the locale %q, the pwpusher_locales data source lists the supported ones.", locale),
+		)
+	}
+	return diags
+}
+
+func NewLocalesDataSource() datasource.DataSource {
+	return &LocalesDataSource{}
+}
+
+// LocalesDataSource defines the data source implementation.
+type LocalesDataSource struct {
+	providerData ProviderData
+}
+
+// LocalesDataSourceModel describes the data source data model.
+type LocalesDataSourceModel struct {
+	Locales       []types.String          `tfsdk:"locales"`
+	Names         map[string]types.String `tfsdk:"names"`
+	DefaultLocale types.String            `tfsdk:"default_locale"`
+	Source        types.String            `tfsdk:"source"`
+	Timeouts      timeouts.Value          `tfsdk:"timeouts"`
+}
+
+func (d *LocalesDataSource) Metadata(ctx context.Context, req datasource.MetadataRequest, resp *datasource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_locales"
+}
+
+func (d *LocalesDataSource) Schema(ctx context.Context, req datasource.SchemaRequest, resp *datasource.SchemaResponse) {
+	resp.Schema = schema.Schema{
+		// This description is used by the documentation generator and the language server.
+		MarkdownDescription: "The UI locales of the pwpusher service of the provider, read from the language menu of its home page. Services without one, such as behind a custom front page, get the locales shipped with the pwpusher app instead, as `source` tells. A locale is selected by appending `?locale=<code>` to a push URL",
+
+		Attributes: map[string]schema.Attribute{
+			"locales": schema.ListAttribute{
+				MarkdownDescription: "The supported locale codes, sorted alphabetically",
+				ElementType:         types.StringType,
+				Computed:            true,
+			},
+			"names": schema.MapAttribute{
+				MarkdownDescription: "The native name of the language of each supported locale code",
+				ElementType:         types.StringType,
+				Computed:            true,
+			},
+			"default_locale": schema.StringAttribute{
+				MarkdownDescription: "The locale used when none is requested",
+				Computed:            true,
+			},
+			"source": schema.StringAttribute{
+				MarkdownDescription: "Where the locales come from, `" + localesSourceServer + "` when the service listed them, `" + localesSourceBuiltin + "` for the locales shipped with the pwpusher app",
+				Computed:            true,
+			},
+		},
+
+		Blocks: map[string]schema.Block{
+			"timeouts": timeouts.Block(ctx),
+		},
+	}
+}
+
+func (d *LocalesDataSource) Configure(ctx context.Context, req datasource.ConfigureRequest, resp *datasource.ConfigureResponse) {
+	// Prevent panic if the provider has not been configured.
+	if req.ProviderData == nil {
+		return
+	}
+
+	providerData, ok := req.ProviderData.(ProviderData)
+
+	if !ok {
+		resp.Diagnostics.AddError(
+			"Unexpected Data Source Configure Type",
+			fmt.Sprintf("Expected ProviderData, got: %T. Please report this issue to the provider developers.", req.ProviderData),
+		)
+
+		return
+	}
+
+	d.providerData = providerData
+}
+
+func (d *LocalesDataSource) Read(ctx context.Context, req datasource.ReadRequest, resp *datasource.ReadResponse) {
+	var data LocalesDataSourceModel
+
+	// Read Terraform configuration data into the model
+	resp.Diagnostics.Append(req.Config.Get(ctx, &data)...)
+
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	readTimeout, diags := data.Timeouts.Read(ctx, defaultDataSourceReadTimeout)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	ctx, cancel := context.WithTimeout(ctx, readTimeout)
+	defer cancel()
+
+	ctx, op := d.providerData.operationContext(ctx, logSubsystemDataSources, "pwpusher_locales read")
+	defer op.end(&resp.Diagnostics)
+
+	locales, listed, err := d.providerData.serverLocales(ctx)
+	if err != nil {
+		resp.Diagnostics.AddError("Client Error", fmt.Sprintf("Unable to read the locales of the pwpusher service, got error: %s", err))
+		return
+	}
+	data.Source = types.StringValue(localesSourceBuiltin)
+	if listed {
+		data.Source = types.StringValue(localesSourceServer)
+	}
+
+	codes := make([]string, 0, len(locales))
+	for code := range locales {
+		codes = append(codes, code)
+	}
+	sort.Strings(codes)
+
+	data.Locales = make([]types.String, 0, len(codes))
+	data.Names = make(map[string]types.String, len(codes))
+	for _, code := range codes {
+		data.Locales = append(data.Locales, types.StringValue(code))
+		data.Names[code] = types.StringValue(locales[code])
+	}
+	data.DefaultLocale = types.StringValue(defaultLocale)
+
+	// Save data into Terraform state
+	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+}
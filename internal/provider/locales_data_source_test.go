@@ -0,0 +1,97 @@
+// Copyright (c) Plex, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package provider
+
+import (
+	"regexp"
+	"terraform-provider-pwpusher/internal/client/clienttest"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-testing/helper/resource"
+)
+
+func TestAccLocalesDataSource(t *testing.T) {
+	resource.Test(t, resource.TestCase{
+		PreCheck:                 func() { testAccPreCheck(t) },
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			// Read testing
+			{
+				Config: testAccLocalesDataSourceConfig,
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("data.pwpusher_locales.test", "default_locale", "en"),
+					resource.TestCheckTypeSetElemAttr("data.pwpusher_locales.test", "locales.*", "fr"),
+					resource.TestCheckResourceAttr("data.pwpusher_locales.test", "names.de", "Deutsch"),
+				),
+			},
+			// Read with a configured timeout
+			{
+				Config: testAccLocalesDataSourceTimeoutsConfig,
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("data.pwpusher_locales.test", "timeouts.read", "30s"),
+				),
+			},
+		},
+	})
+}
+
+func TestLocalesDataSource(t *testing.T) {
+	server := clienttest.NewServer()
+	defer server.Close()
+	server.Locales = map[string]string{"en": "English", "tlh": "tlhIngan Hol"}
+
+	resource.UnitTest(t, resource.TestCase{
+		ProtoV6ProviderFactories: testFakeClientProviderFactories(server),
+		Steps: []resource.TestStep{
+			{
+				Config: testFakeClientProviderConfig(server) + testAccLocalesDataSourceConfig,
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("data.pwpusher_locales.test", "source", "server"),
+					resource.TestCheckResourceAttr("data.pwpusher_locales.test", "locales.#", "2"),
+					resource.TestCheckResourceAttr("data.pwpusher_locales.test", "names.tlh", "tlhIngan Hol"),
+				),
+			},
+			// Locales of the service only are accepted.
+			{
+				Config: testFakeClientProviderConfig(server) + `
+resource "pwpusher_text" "test" {
+  password = "secret"
+  locale   = "fr"
+}
+`,
+				ExpectError: regexp.MustCompile(`Unsupported Locale`),
+			},
+		},
+	})
+}
+
+func TestLocalesDataSourceBuiltin(t *testing.T) {
+	server := clienttest.NewServer()
+	defer server.Close()
+
+	resource.UnitTest(t, resource.TestCase{
+		ProtoV6ProviderFactories: testFakeClientProviderFactories(server),
+		Steps: []resource.TestStep{
+			{
+				Config: testFakeClientProviderConfig(server) + testAccLocalesDataSourceConfig,
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("data.pwpusher_locales.test", "source", "builtin"),
+					resource.TestCheckResourceAttr("data.pwpusher_locales.test", "names.de", "Deutsch"),
+				),
+			},
+		},
+	})
+}
+
+const testAccLocalesDataSourceConfig = `
+data "pwpusher_locales" "test" {}
+`
+
+const testAccLocalesDataSourceTimeoutsConfig = `
+data "pwpusher_locales" "test" {
+  timeouts {
+    read = "30s"
+  }
+}
+`
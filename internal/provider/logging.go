@@ -0,0 +1,33 @@
+// Copyright (c) Plex, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package provider
+
+import (
+	"context"
+	"terraform-provider-pwpusher/internal/client"
+
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+)
+
+// The tflog subsystems of the resources and data sources of the provider.
+const (
+	logSubsystemTextResource = "text_resource"
+	logSubsystemDataSources  = "datasources"
+)
+
+// logSubsystems lists the tflog subsystems of the provider, the requests of
+// the client included.
+var logSubsystems = []string{client.LogSubsystem, logSubsystemTextResource, logSubsystemDataSources}
+
+// withLogSubsystems returns a context with the subsystems of the provider,
+// carrying the fields of its root logger such as the request ID. Their levels
+// default to that of the provider and are set with the
+// TF_LOG_PROVIDER_PWPUSHER_<SUBSYSTEM> environment variables, such as
+// TF_LOG_PROVIDER_PWPUSHER_CLIENT=TRACE.
+func withLogSubsystems(ctx context.Context) context.Context {
+	for _, subsystem := range logSubsystems {
+		ctx = tflog.NewSubsystem(ctx, subsystem, tflog.WithRootFields(), tflog.WithLevelFromEnv("TF_LOG_PROVIDER_PWPUSHER", subsystem))
+	}
+	return ctx
+}
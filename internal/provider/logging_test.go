@@ -0,0 +1,71 @@
+// Copyright (c) Plex, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package provider
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"terraform-provider-pwpusher/internal/client"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+	"github.com/hashicorp/terraform-plugin-log/tflogtest"
+)
+
+func TestLogSubsystems(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"url_token":"token1","note":"top secret"}`))
+	}))
+	defer server.Close()
+
+	var logs bytes.Buffer
+	ctx, requestID := withRequestID(tflogtest.RootLogger(context.Background(), &logs))
+	ctx = withLogSubsystems(ctx)
+	ctx = maskSecrets(ctx, "top secret")
+
+	if _, err := client.New(server.Client(), server.URL, "").GetPush(ctx, "token1"); err != nil {
+		t.Fatal(err)
+	}
+	tflog.SubsystemInfo(ctx, logSubsystemTextResource, "pushed top secret")
+
+	entries, err := tflogtest.MultilineJSONDecode(&logs)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 2 {
+		t.Fatalf("got %d log entries, want 2: %v", len(entries), entries)
+	}
+	request, created := entries[0], entries[1]
+	if request["@module"] != "provider.client" || created["@module"] != "provider.text_resource" {
+		t.Errorf("got modules %v and %v", request["@module"], created["@module"])
+	}
+	for name, want := range map[string]interface{}{
+		"request_id": requestID,
+		"operation":  "GetPush",
+		"token":      "token1",
+		"status":     float64(http.StatusOK),
+	} {
+		if request[name] != want {
+			t.Errorf("got %s %v, want %v", name, request[name], want)
+		}
+	}
+	if _, ok := request["duration_ms"]; !ok {
+		t.Error("got no duration_ms field")
+	}
+	if created["request_id"] != requestID || strings.Contains(created["@message"].(string), "top secret") {
+		t.Errorf("got entry %v", created)
+	}
+	// Response bodies are not logged.
+	for _, entry := range entries {
+		for name, value := range entry {
+			if s, ok := value.(string); ok && strings.Contains(s, "top secret") {
+				t.Errorf("got %s %q", name, s)
+			}
+		}
+	}
+}
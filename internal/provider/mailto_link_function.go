@@ -0,0 +1,93 @@
+// Copyright (c) Plex, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package provider
+
+import (
+	"context"
+	"net/url"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-framework/function"
+)
+
+// Ensure provider defined types fully satisfy framework interfaces.
+var _ function.Function = &MailtoLinkFunction{}
+
+const (
+	defaultMailtoSubject      = "A secret has been shared with you"
+	defaultMailtoInstructions = "Open the link below to retrieve the secret. The link expires after a limited time or number of views, so retrieve it soon and store it somewhere safe."
+)
+
+func NewMailtoLinkFunction() function.Function {
+	return &MailtoLinkFunction{}
+}
+
+// MailtoLinkFunction defines the function implementation.
+type MailtoLinkFunction struct{}
+
+func (f *MailtoLinkFunction) Metadata(ctx context.Context, req function.MetadataRequest, resp *function.MetadataResponse) {
+	resp.Name = "mailto_link"
+}
+
+func (f *MailtoLinkFunction) Definition(ctx context.Context, req function.DefinitionRequest, resp *function.DefinitionResponse) {
+	resp.Definition = function.Definition{
+		Summary:             "Build a mailto link delivering a push",
+		MarkdownDescription: "Builds a `mailto:` URL that opens an email with the subject and a body made of the retrieval instructions followed by the push URL. The subject defaults to `" + defaultMailtoSubject + "` and the instructions to `" + defaultMailtoInstructions + "`",
+		Parameters: []function.Parameter{
+			function.StringParameter{
+				Name:                "to",
+				MarkdownDescription: "The recipient addresses, separated by commas, or an empty string to pick them in the mail client",
+			},
+			function.StringParameter{
+				Name:                "url",
+				MarkdownDescription: "The URL of the push",
+			},
+			function.StringParameter{
+				Name:                "subject",
+				MarkdownDescription: "The subject of the email, or null for the default",
+				AllowNullValue:      true,
+			},
+			function.StringParameter{
+				Name:                "instructions",
+				MarkdownDescription: "The instructions preceding the URL in the body, or null for the default",
+				AllowNullValue:      true,
+			},
+		},
+		Return: function.StringReturn{},
+	}
+}
+
+func (f *MailtoLinkFunction) Run(ctx context.Context, req function.RunRequest, resp *function.RunResponse) {
+	var to, pushURL string
+	var subject, instructions *string
+
+	resp.Error = function.ConcatFuncErrors(req.Arguments.Get(ctx, &to, &pushURL, &subject, &instructions))
+
+	if resp.Error != nil {
+		return
+	}
+
+	if subject == nil {
+		subject = new(string)
+		*subject = defaultMailtoSubject
+	}
+	if instructions == nil {
+		instructions = new(string)
+		*instructions = defaultMailtoInstructions
+	}
+
+	link := "mailto:" + mailtoEscape(to) +
+		"?subject=" + mailtoEscape(*subject) +
+		"&body=" + mailtoEscape(*instructions+"\r\n\r\n"+pushURL)
+
+	resp.Error = function.ConcatFuncErrors(resp.Result.Set(ctx, link))
+}
+
+// mailtoEscape percent-encodes s for a mailto URL, which unlike form
+// encoding does not turn spaces into plus signs.
+func mailtoEscape(s string) string {
+	escaped := strings.ReplaceAll(url.QueryEscape(s), "+", "%20")
+	// Keep the address separator and the at sign readable.
+	return strings.NewReplacer("%2C", ",", "%40", "@").Replace(escaped)
+}
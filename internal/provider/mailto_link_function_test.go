@@ -0,0 +1,36 @@
+// Copyright (c) Plex, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package provider
+
+import (
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-testing/helper/resource"
+	"github.com/hashicorp/terraform-plugin-testing/knownvalue"
+	"github.com/hashicorp/terraform-plugin-testing/statecheck"
+	"github.com/hashicorp/terraform-plugin-testing/tfversion"
+)
+
+func TestMailtoLinkFunction(t *testing.T) {
+	resource.UnitTest(t, resource.TestCase{
+		TerraformVersionChecks: []tfversion.TerraformVersionCheck{
+			tfversion.SkipBelow(tfversion.Version1_8_0),
+		},
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: `
+output "test" {
+  value = provider::pwpusher::mailto_link("a@example.com,b@example.com", "https://pwpush.com/p/abc123", "Your login", "Open this & log in:")
+}
+`,
+				ConfigStateChecks: []statecheck.StateCheck{
+					statecheck.ExpectKnownOutputValue("test", knownvalue.StringExact(
+						"mailto:a@example.com,b@example.com?subject=Your%20login&body=Open%20this%20%26%20log%20in%3A%0D%0A%0D%0Ahttps%3A%2F%2Fpwpush.com%2Fp%2Fabc123",
+					)),
+				},
+			},
+		},
+	})
+}
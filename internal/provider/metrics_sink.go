@@ -0,0 +1,225 @@
+// Copyright (c) Plex, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package provider
+
+import (
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"terraform-provider-pwpusher/internal/client"
+	"time"
+)
+
+// requestFailed reports whether a request answered with status failed, by
+// not being answered or with a server error, as the circuit breaker counts
+// failures.
+func requestFailed(status int) bool {
+	return status == 0 || status >= http.StatusInternalServerError
+}
+
+// recorders tells several recorders of the requests of a client.
+type recorders []client.RequestRecorder
+
+func (r recorders) RecordRequest(operation string, duration time.Duration, status int) {
+	for _, recorder := range r {
+		recorder.RecordRequest(operation, duration, status)
+	}
+}
+
+// statsdSink sends the metrics of requests to a statsd server as they are
+// made: the counters pwpusher.requests.<operation> and
+// pwpusher.errors.<operation>, and the timer
+// pwpusher.request_duration.<operation>.
+type statsdSink struct {
+	conn io.Writer
+}
+
+// statsdSinks are the sinks sending metrics by server address, so that the
+// provider instances of a process configured with the same address share
+// its socket.
+var statsdSinks = struct {
+	mu    sync.Mutex
+	sinks map[string]*statsdSink
+}{sinks: map[string]*statsdSink{}}
+
+// newStatsdSink returns a sink sending metrics over UDP to address, opening
+// a socket unless a provider instance of the process already did. The socket
+// stays open for as long as the provider runs.
+func newStatsdSink(address string) (*statsdSink, error) {
+	statsdSinks.mu.Lock()
+	defer statsdSinks.mu.Unlock()
+	if sink, ok := statsdSinks.sinks[address]; ok {
+		return sink, nil
+	}
+	conn, err := net.Dial("udp", address)
+	if err != nil {
+		return nil, err
+	}
+	sink := &statsdSink{conn: conn}
+	statsdSinks.sinks[address] = sink
+	return sink, nil
+}
+
+func (s *statsdSink) RecordRequest(operation string, duration time.Duration, status int) {
+	lines := []string{
+		fmt.Sprintf("pwpusher.requests.%s:1|c", operation),
+		fmt.Sprintf("pwpusher.request_duration.%s:%d|ms", operation, duration.Milliseconds()),
+	}
+	if requestFailed(status) {
+		lines = append(lines, fmt.Sprintf("pwpusher.errors.%s:1|c", operation))
+	}
+	// Metrics are best effort, an unreachable server must not fail the
+	// requests they describe. A datagram per request keeps under the MTU.
+	_, _ = s.conn.Write([]byte(strings.Join(lines, "\n")))
+}
+
+// prometheusBuckets are the upper bounds in seconds of the buckets of the
+// histogram of the durations of requests.
+var prometheusBuckets = []float64{0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10, 30}
+
+// prometheusSink keeps the metrics of requests for Prometheus to scrape: the
+// counters pwpusher_requests_total by operation and status and
+// pwpusher_request_errors_total by operation, and the histogram
+// pwpusher_request_duration_seconds by operation.
+type prometheusSink struct {
+	mu        sync.Mutex
+	requests  map[prometheusRequestKey]int
+	errors    map[string]int
+	durations map[string]*prometheusHistogram
+}
+
+// prometheusRequestKey is the labels of pwpusher_requests_total.
+type prometheusRequestKey struct {
+	operation string
+	status    int
+}
+
+// prometheusHistogram is the histogram of the durations of the requests of an
+// operation, with a count for each of prometheusBuckets.
+type prometheusHistogram struct {
+	buckets []int
+	sum     float64
+	count   int
+}
+
+// prometheusSinks are the sinks serving metrics by listen address, so that
+// the provider instances of a process configured with the same address share
+// its listener.
+var prometheusSinks = struct {
+	mu    sync.Mutex
+	sinks map[string]*prometheusSink
+}{sinks: map[string]*prometheusSink{}}
+
+// servePrometheusSink returns the sink serving metrics at /metrics on
+// address, listening on it unless a provider instance of the process already
+// does. The listener stays open for as long as the provider runs.
+func servePrometheusSink(address string) (*prometheusSink, error) {
+	prometheusSinks.mu.Lock()
+	defer prometheusSinks.mu.Unlock()
+	if sink, ok := prometheusSinks.sinks[address]; ok {
+		return sink, nil
+	}
+
+	listener, err := net.Listen("tcp", address)
+	if err != nil {
+		return nil, err
+	}
+	sink := &prometheusSink{
+		requests:  map[prometheusRequestKey]int{},
+		errors:    map[string]int{},
+		durations: map[string]*prometheusHistogram{},
+	}
+	mux := http.NewServeMux()
+	mux.Handle("/metrics", sink)
+	server := &http.Server{Handler: mux, ReadHeaderTimeout: 10 * time.Second}
+	go func() { _ = server.Serve(listener) }()
+	prometheusSinks.sinks[address] = sink
+	return sink, nil
+}
+
+func (s *prometheusSink) RecordRequest(operation string, duration time.Duration, status int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.requests[prometheusRequestKey{operation: operation, status: status}]++
+	if requestFailed(status) {
+		s.errors[operation]++
+	}
+	histogram, ok := s.durations[operation]
+	if !ok {
+		histogram = &prometheusHistogram{buckets: make([]int, len(prometheusBuckets))}
+		s.durations[operation] = histogram
+	}
+	seconds := duration.Seconds()
+	for i, bound := range prometheusBuckets {
+		if seconds <= bound {
+			histogram.buckets[i]++
+		}
+	}
+	histogram.sum += seconds
+	histogram.count++
+}
+
+// ServeHTTP writes the metrics in the Prometheus text format.
+func (s *prometheusSink) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+	_, _ = io.WriteString(w, s.text())
+}
+
+// text returns the metrics in the Prometheus text format, sorted so that
+// scrapes are stable.
+func (s *prometheusSink) text() string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var b strings.Builder
+
+	b.WriteString("# HELP pwpusher_requests_total Requests sent to the pwpusher service, by client operation and response status, 0 when unanswered.\n")
+	b.WriteString("# TYPE pwpusher_requests_total counter\n")
+	keys := make([]prometheusRequestKey, 0, len(s.requests))
+	for key := range s.requests {
+		keys = append(keys, key)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		if keys[i].operation != keys[j].operation {
+			return keys[i].operation < keys[j].operation
+		}
+		return keys[i].status < keys[j].status
+	})
+	for _, key := range keys {
+		fmt.Fprintf(&b, "pwpusher_requests_total{operation=%q,status=\"%d\"} %d\n", key.operation, key.status, s.requests[key])
+	}
+
+	b.WriteString("# HELP pwpusher_request_errors_total Requests to the pwpusher service that were unanswered or answered with a server error.\n")
+	b.WriteString("# TYPE pwpusher_request_errors_total counter\n")
+	for _, operation := range sortedKeys(s.errors) {
+		fmt.Fprintf(&b, "pwpusher_request_errors_total{operation=%q} %d\n", operation, s.errors[operation])
+	}
+
+	b.WriteString("# HELP pwpusher_request_duration_seconds Durations of the requests to the pwpusher service, retries included.\n")
+	b.WriteString("# TYPE pwpusher_request_duration_seconds histogram\n")
+	for _, operation := range sortedKeys(s.durations) {
+		histogram := s.durations[operation]
+		for i, bound := range prometheusBuckets {
+			fmt.Fprintf(&b, "pwpusher_request_duration_seconds_bucket{operation=%q,le=%q} %d\n", operation, strconv.FormatFloat(bound, 'g', -1, 64), histogram.buckets[i])
+		}
+		fmt.Fprintf(&b, "pwpusher_request_duration_seconds_bucket{operation=%q,le=\"+Inf\"} %d\n", operation, histogram.count)
+		fmt.Fprintf(&b, "pwpusher_request_duration_seconds_sum{operation=%q} %s\n", operation, strconv.FormatFloat(histogram.sum, 'g', -1, 64))
+		fmt.Fprintf(&b, "pwpusher_request_duration_seconds_count{operation=%q} %d\n", operation, histogram.count)
+	}
+	return b.String()
+}
+
+// sortedKeys returns the keys of m in order.
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,88 @@
+// Copyright (c) Plex, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package provider
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestPrometheusSink(t *testing.T) {
+	sink := &prometheusSink{
+		requests:  map[prometheusRequestKey]int{},
+		errors:    map[string]int{},
+		durations: map[string]*prometheusHistogram{},
+	}
+	sink.RecordRequest("Version", 20*time.Millisecond, http.StatusOK)
+	sink.RecordRequest("Version", 2*time.Second, http.StatusBadGateway)
+	sink.RecordRequest("CreatePush", time.Minute, 0)
+
+	rec := httptest.NewRecorder()
+	sink.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+	if got := rec.Header().Get("Content-Type"); !strings.HasPrefix(got, "text/plain; version=0.0.4") {
+		t.Errorf("got content type %q", got)
+	}
+	body := rec.Body.String()
+	for _, want := range []string{
+		"# TYPE pwpusher_requests_total counter\n",
+		`pwpusher_requests_total{operation="CreatePush",status="0"} 1` + "\n",
+		`pwpusher_requests_total{operation="Version",status="200"} 1` + "\n",
+		`pwpusher_requests_total{operation="Version",status="502"} 1` + "\n",
+		`pwpusher_request_errors_total{operation="CreatePush"} 1` + "\n",
+		`pwpusher_request_errors_total{operation="Version"} 1` + "\n",
+		"# TYPE pwpusher_request_duration_seconds histogram\n",
+		`pwpusher_request_duration_seconds_bucket{operation="Version",le="0.05"} 1` + "\n",
+		`pwpusher_request_duration_seconds_bucket{operation="Version",le="2.5"} 2` + "\n",
+		`pwpusher_request_duration_seconds_bucket{operation="CreatePush",le="30"} 0` + "\n",
+		`pwpusher_request_duration_seconds_bucket{operation="CreatePush",le="+Inf"} 1` + "\n",
+		`pwpusher_request_duration_seconds_sum{operation="Version"} 2.02` + "\n",
+		`pwpusher_request_duration_seconds_count{operation="Version"} 2` + "\n",
+	} {
+		if !strings.Contains(body, want) {
+			t.Errorf("missing %q in:\n%s", want, body)
+		}
+	}
+}
+
+func TestStatsdSink(t *testing.T) {
+	var sent []string
+	sink := &statsdSink{conn: writerFunc(func(p []byte) (int, error) {
+		sent = append(sent, string(p))
+		return len(p), nil
+	})}
+	sink.RecordRequest("Version", 20*time.Millisecond, http.StatusOK)
+	sink.RecordRequest("ListPushes", time.Second, http.StatusServiceUnavailable)
+
+	want := []string{
+		"pwpusher.requests.Version:1|c\npwpusher.request_duration.Version:20|ms",
+		"pwpusher.requests.ListPushes:1|c\npwpusher.request_duration.ListPushes:1000|ms\npwpusher.errors.ListPushes:1|c",
+	}
+	if strings.Join(sent, "\n\n") != strings.Join(want, "\n\n") {
+		t.Errorf("got datagrams %q, want %q", sent, want)
+	}
+}
+
+func TestNewStatsdSinkShared(t *testing.T) {
+	first, err := newStatsdSink("127.0.0.1:8125")
+	if err != nil {
+		t.Fatal(err)
+	}
+	again, err := newStatsdSink("127.0.0.1:8125")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if first != again {
+		t.Error("got another sink for the same address, want its socket shared")
+	}
+}
+
+// writerFunc is an io.Writer calling itself.
+type writerFunc func(p []byte) (int, error)
+
+func (f writerFunc) Write(p []byte) (int, error) {
+	return f(p)
+}
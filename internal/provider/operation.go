@@ -0,0 +1,60 @@
+// Copyright (c) Plex, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package provider
+
+import (
+	"context"
+	"terraform-provider-pwpusher/internal/client"
+
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+)
+
+// operation is an operation of a resource or data source of the provider in
+// progress, such as "pwpusher_text create".
+type operation struct {
+	ctx       context.Context
+	subsystem string
+	name      string
+	requestID string
+	metrics   *client.Metrics
+	redaction logRedaction
+	tracer    *tracer
+	span      *span
+}
+
+// operationContext returns the context of the operation name, logging to
+// subsystem, and the operation. Its requests and logs carry a request ID and
+// are counted in request metrics and traced, and its logs are redacted. The
+// caller must defer end with the diagnostics of the operation.
+func (d ProviderData) operationContext(ctx context.Context, subsystem, name string) (context.Context, *operation) {
+	ctx, requestID := withRequestID(ctx)
+	ctx = withLogSubsystems(ctx)
+	ctx, metrics := withRequestMetrics(ctx)
+	ctx = d.redaction.context(ctx)
+	ctx, span := d.tracer.start(ctx, name, spanKindInternal)
+	span.setAttribute("pwpusher.request_id", requestID)
+	return ctx, &operation{ctx: ctx, subsystem: subsystem, name: name, requestID: requestID, metrics: metrics, redaction: d.redaction, tracer: d.tracer, span: span}
+}
+
+// withSecrets returns ctx with its logs masking each of secrets, such as the
+// payload and passphrase of a push, which are also scrubbed from the
+// diagnostics of the operation.
+func (o *operation) withSecrets(ctx context.Context, secrets ...string) context.Context {
+	o.redaction = o.redaction.withSecrets(secrets...)
+	return maskSecrets(ctx, secrets...)
+}
+
+// end redacts diags, adds the request ID to their errors, logs the request
+// metrics of the operation and exports its spans.
+func (o *operation) end(diags *diag.Diagnostics) {
+	*diags = o.redaction.diagnostics(*diags)
+	logRequestMetrics(o.ctx, o.subsystem, o.name, o.metrics)
+	failure := ""
+	if errs := diags.Errors(); len(errs) > 0 {
+		failure = errs[0].Summary()
+	}
+	o.span.finish(failure)
+	o.tracer.flush(o.ctx, o.subsystem)
+	*diags = withRequestIDDetail(*diags, o.requestID)
+}
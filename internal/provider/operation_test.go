@@ -0,0 +1,38 @@
+// Copyright (c) Plex, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package provider
+
+import (
+	"context"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+)
+
+func TestOperationContext(t *testing.T) {
+	providerData := ProviderData{redaction: logRedaction{patterns: []*regexp.Regexp{regexp.MustCompile(`tok_[a-z]+`)}}}
+	ctx, op := providerData.operationContext(context.Background(), logSubsystemDataSources, "pwpusher_test read")
+	requestID, _ := ctx.Value(requestIDKey{}).(string)
+	if requestID == "" || requestID != op.requestID {
+		t.Errorf("got request ID %q in the context, want %q", requestID, op.requestID)
+	}
+	_ = op.withSecrets(ctx, "hunter2")
+
+	var diags diag.Diagnostics
+	diags.AddError("Client Error", "Unable to push hunter2 with tok_abc.")
+	op.end(&diags)
+
+	detail := diags[0].Detail()
+	if strings.Contains(detail, "hunter2") || strings.Contains(detail, "tok_abc") {
+		t.Errorf("got unredacted detail %q", detail)
+	}
+	if !strings.Contains(detail, op.requestID) {
+		t.Errorf("got detail %q, want the request ID %s", detail, op.requestID)
+	}
+	if providerData.redaction.secrets != nil {
+		t.Errorf("the secrets of the operation leaked into the provider redaction: %q", providerData.redaction.secrets)
+	}
+}
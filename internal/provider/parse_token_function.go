@@ -0,0 +1,120 @@
+// Copyright (c) Plex, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package provider
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-framework/attr"
+	"github.com/hashicorp/terraform-plugin-framework/function"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+// Ensure provider defined types fully satisfy framework interfaces.
+var _ function.Function = &ParseTokenFunction{}
+
+// pushKindPaths maps the leading path segment of a push URL to the kind of
+// the push.
+var pushKindPaths = map[string]string{
+	"p":  "text",
+	"f":  "file",
+	"r":  "url",
+	"qr": "qr",
+}
+
+// retrievalStepSegment is the path segment appended to a push URL to show
+// the retrieval step before revealing the push.
+const retrievalStepSegment = "r"
+
+func NewParseTokenFunction() function.Function {
+	return &ParseTokenFunction{}
+}
+
+// ParseTokenFunction defines the function implementation.
+type ParseTokenFunction struct{}
+
+// ParsedToken is the result of the parse_token function.
+type ParsedToken struct {
+	Token         string `tfsdk:"token"`
+	Kind          string `tfsdk:"kind"`
+	RetrievalStep bool   `tfsdk:"retrieval_step"`
+	Locale        string `tfsdk:"locale"`
+}
+
+func (f *ParseTokenFunction) Metadata(ctx context.Context, req function.MetadataRequest, resp *function.MetadataResponse) {
+	resp.Name = "parse_token"
+}
+
+func (f *ParseTokenFunction) Definition(ctx context.Context, req function.DefinitionRequest, resp *function.DefinitionResponse) {
+	resp.Definition = function.Definition{
+		Summary:             "Parse the token out of a push URL",
+		MarkdownDescription: "Returns the token, kind, retrieval step and locale of a pwpusher push URL. The URL may be hosted under a path prefix and carry the locale either as a `locale` query parameter or as a path segment. `locale` is empty when the URL does not select one",
+		Parameters: []function.Parameter{
+			function.StringParameter{
+				Name:                "url",
+				MarkdownDescription: "The push URL to parse",
+			},
+		},
+		Return: function.ObjectReturn{
+			AttributeTypes: map[string]attr.Type{
+				"token":          types.StringType,
+				"kind":           types.StringType,
+				"retrieval_step": types.BoolType,
+				"locale":         types.StringType,
+			},
+		},
+	}
+}
+
+func (f *ParseTokenFunction) Run(ctx context.Context, req function.RunRequest, resp *function.RunResponse) {
+	var rawURL string
+
+	resp.Error = function.ConcatFuncErrors(req.Arguments.Get(ctx, &rawURL))
+
+	if resp.Error != nil {
+		return
+	}
+
+	parsed, err := parsePushURL(rawURL)
+	if err != nil {
+		resp.Error = function.NewArgumentFuncError(0, err.Error())
+		return
+	}
+
+	resp.Error = function.ConcatFuncErrors(resp.Result.Set(ctx, parsed))
+}
+
+// parsePushURL extracts the push details from a push URL.
+func parsePushURL(rawURL string) (ParsedToken, error) {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return ParsedToken{}, fmt.Errorf("invalid push URL: %s", err)
+	}
+
+	segments := strings.Split(strings.Trim(u.Path, "/"), "/")
+	for i := 0; i+1 < len(segments); i++ {
+		kind, ok := pushKindPaths[segments[i]]
+		if !ok || segments[i+1] == "" {
+			continue
+		}
+
+		parsed := ParsedToken{
+			Token:         segments[i+1],
+			Kind:          kind,
+			RetrievalStep: i+2 < len(segments) && segments[i+2] == retrievalStepSegment,
+			Locale:        u.Query().Get("locale"),
+		}
+		if parsed.Locale == "" && i > 0 {
+			if _, ok := supportedLocales[segments[i-1]]; ok {
+				parsed.Locale = segments[i-1]
+			}
+		}
+		return parsed, nil
+	}
+
+	return ParsedToken{}, fmt.Errorf("%q is not a push URL, expected a path such as /p/<token>", rawURL)
+}
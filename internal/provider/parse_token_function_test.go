@@ -0,0 +1,63 @@
+// Copyright (c) Plex, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package provider
+
+import (
+	"regexp"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-testing/helper/resource"
+	"github.com/hashicorp/terraform-plugin-testing/knownvalue"
+	"github.com/hashicorp/terraform-plugin-testing/statecheck"
+	"github.com/hashicorp/terraform-plugin-testing/tfversion"
+)
+
+func TestParseTokenFunction(t *testing.T) {
+	resource.UnitTest(t, resource.TestCase{
+		TerraformVersionChecks: []tfversion.TerraformVersionCheck{
+			tfversion.SkipBelow(tfversion.Version1_8_0),
+		},
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: `
+output "test" {
+  value = provider::pwpusher::parse_token("https://pwpush.com/p/abc123/r?locale=fr")
+}
+`,
+				ConfigStateChecks: []statecheck.StateCheck{
+					statecheck.ExpectKnownOutputValue("test", knownvalue.ObjectExact(map[string]knownvalue.Check{
+						"token":          knownvalue.StringExact("abc123"),
+						"kind":           knownvalue.StringExact("text"),
+						"retrieval_step": knownvalue.Bool(true),
+						"locale":         knownvalue.StringExact("fr"),
+					})),
+				},
+			},
+			{
+				Config: `
+output "test" {
+  value = provider::pwpusher::parse_token("https://intranet.example.com/pwpush/de/f/xyz789")
+}
+`,
+				ConfigStateChecks: []statecheck.StateCheck{
+					statecheck.ExpectKnownOutputValue("test", knownvalue.ObjectExact(map[string]knownvalue.Check{
+						"token":          knownvalue.StringExact("xyz789"),
+						"kind":           knownvalue.StringExact("file"),
+						"retrieval_step": knownvalue.Bool(false),
+						"locale":         knownvalue.StringExact("de"),
+					})),
+				},
+			},
+			{
+				Config: `
+output "test" {
+  value = provider::pwpusher::parse_token("https://pwpush.com/about")
+}
+`,
+				ExpectError: regexp.MustCompile("is not a push"),
+			},
+		},
+	})
+}
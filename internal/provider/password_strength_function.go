@@ -0,0 +1,127 @@
+// Copyright (c) Plex, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package provider
+
+import (
+	"context"
+	"math"
+	"unicode"
+
+	"github.com/hashicorp/terraform-plugin-framework/attr"
+	"github.com/hashicorp/terraform-plugin-framework/function"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+// Ensure provider defined types fully satisfy framework interfaces.
+var _ function.Function = &PasswordStrengthFunction{}
+
+// strengthRatings are the ratings of the password_strength score, indexed by
+// score, along with the minimum entropy in bits needed to reach them.
+var strengthRatings = []struct {
+	rating     string
+	minEntropy float64
+}{
+	{"very_weak", 0},
+	{"weak", 28},
+	{"fair", 36},
+	{"strong", 60},
+	{"very_strong", 128},
+}
+
+func NewPasswordStrengthFunction() function.Function {
+	return &PasswordStrengthFunction{}
+}
+
+// PasswordStrengthFunction defines the function implementation.
+type PasswordStrengthFunction struct{}
+
+// PasswordStrength is the result of the password_strength function.
+type PasswordStrength struct {
+	Entropy float64 `tfsdk:"entropy"`
+	Score   int64   `tfsdk:"score"`
+	Rating  string  `tfsdk:"rating"`
+}
+
+func (f *PasswordStrengthFunction) Metadata(ctx context.Context, req function.MetadataRequest, resp *function.MetadataResponse) {
+	resp.Name = "password_strength"
+}
+
+func (f *PasswordStrengthFunction) Definition(ctx context.Context, req function.DefinitionRequest, resp *function.DefinitionResponse) {
+	resp.Definition = function.Definition{
+		Summary:             "Estimate the strength of a password",
+		MarkdownDescription: "Estimates the entropy of a password or passphrase in bits from its length and the classes of characters it uses, counting immediately repeated characters once. `score` ranges from `0` to `4` with the matching `rating` `very_weak`, `weak` (28 bits), `fair` (36 bits), `strong` (60 bits) or `very_strong` (128 bits). The estimate assumes randomly chosen characters, so it overrates dictionary words",
+		Parameters: []function.Parameter{
+			function.StringParameter{
+				Name:                "value",
+				MarkdownDescription: "The password or passphrase to rate",
+			},
+		},
+		Return: function.ObjectReturn{
+			AttributeTypes: map[string]attr.Type{
+				"entropy": types.Float64Type,
+				"score":   types.Int64Type,
+				"rating":  types.StringType,
+			},
+		},
+	}
+}
+
+func (f *PasswordStrengthFunction) Run(ctx context.Context, req function.RunRequest, resp *function.RunResponse) {
+	var value string
+
+	resp.Error = function.ConcatFuncErrors(req.Arguments.Get(ctx, &value))
+
+	if resp.Error != nil {
+		return
+	}
+
+	resp.Error = function.ConcatFuncErrors(resp.Result.Set(ctx, passwordStrength(value)))
+}
+
+// passwordStrength rates value.
+func passwordStrength(value string) PasswordStrength {
+	var lower, upper, digit, symbol, other bool
+	var length int
+	var previous rune = -1
+	for _, c := range value {
+		switch {
+		case c >= 'a' && c <= 'z':
+			lower = true
+		case c >= 'A' && c <= 'Z':
+			upper = true
+		case c >= '0' && c <= '9':
+			digit = true
+		case c <= unicode.MaxASCII:
+			symbol = true
+		default:
+			other = true
+		}
+		if c != previous {
+			length++
+		}
+		previous = c
+	}
+
+	pool := 0
+	for _, class := range []struct {
+		used bool
+		size int
+	}{{lower, 26}, {upper, 26}, {digit, 10}, {symbol, 33}, {other, 100}} {
+		if class.used {
+			pool += class.size
+		}
+	}
+
+	strength := PasswordStrength{Rating: strengthRatings[0].rating}
+	if pool > 0 {
+		strength.Entropy = math.Round(float64(length)*math.Log2(float64(pool))*100) / 100
+	}
+	for score, rating := range strengthRatings {
+		if strength.Entropy >= rating.minEntropy {
+			strength.Score = int64(score)
+			strength.Rating = rating.rating
+		}
+	}
+	return strength
+}
@@ -0,0 +1,47 @@
+// Copyright (c) Plex, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package provider
+
+import (
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-testing/helper/resource"
+	"github.com/hashicorp/terraform-plugin-testing/knownvalue"
+	"github.com/hashicorp/terraform-plugin-testing/statecheck"
+	"github.com/hashicorp/terraform-plugin-testing/tfversion"
+)
+
+func TestPasswordStrengthFunction(t *testing.T) {
+	resource.UnitTest(t, resource.TestCase{
+		TerraformVersionChecks: []tfversion.TerraformVersionCheck{
+			tfversion.SkipBelow(tfversion.Version1_8_0),
+		},
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: `
+output "weak" {
+  value = provider::pwpusher::password_strength("aaaaaaaa")
+}
+
+output "strong" {
+  value = provider::pwpusher::password_strength("Tr0ub4dor&3")
+}
+`,
+				ConfigStateChecks: []statecheck.StateCheck{
+					statecheck.ExpectKnownOutputValue("weak", knownvalue.ObjectExact(map[string]knownvalue.Check{
+						"entropy": knownvalue.Float64Exact(4.7),
+						"score":   knownvalue.Int64Exact(0),
+						"rating":  knownvalue.StringExact("very_weak"),
+					})),
+					statecheck.ExpectKnownOutputValue("strong", knownvalue.ObjectExact(map[string]knownvalue.Check{
+						"entropy": knownvalue.Float64Exact(72.27),
+						"score":   knownvalue.Int64Exact(3),
+						"rating":  knownvalue.StringExact("strong"),
+					})),
+				},
+			},
+		},
+	})
+}
@@ -0,0 +1,225 @@
+// Copyright (c) Plex, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package provider
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"regexp"
+	"strings"
+	"terraform-provider-pwpusher/internal/client"
+
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/provider/schema"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+// PolicyModel describes the policy block of the provider.
+type PolicyModel struct {
+	RequirePassphrase   types.Bool   `tfsdk:"require_passphrase"`
+	MaxExpireAfterDays  types.Int64  `tfsdk:"max_expire_after_days"`
+	MaxExpireAfterViews types.Int64  `tfsdk:"max_expire_after_views"`
+	DeniedPayloads      types.List   `tfsdk:"denied_payloads"`
+	DeniedPayloadAction types.String `tfsdk:"denied_payload_action"`
+}
+
+// Actions of the denied_payloads policy on matching payloads.
+const (
+	denyActionError = "error"
+	denyActionWarn  = "warn"
+)
+
+// pushPolicy are the rules every push of the provider must follow,
+// enforced when pushes are planned.
+type pushPolicy struct {
+	requirePassphrase bool
+	// maxExpireAfterDays and maxExpireAfterViews cap the expiration
+	// settings of pushes, zero for no cap.
+	maxExpireAfterDays  int32
+	maxExpireAfterViews int32
+	// deniedPayloads match payloads that must not be pushed, which fail
+	// the push unless warnOnDenied is set.
+	deniedPayloads []*regexp.Regexp
+	warnOnDenied   bool
+}
+
+// policyBlock returns the schema of the policy block of the provider.
+func policyBlock() schema.Block {
+	return schema.SingleNestedBlock{
+		MarkdownDescription: "Rules every push of the provider must follow, such as the security baseline of an organization. Pushes breaking them fail at plan time",
+		Attributes: map[string]schema.Attribute{
+			"require_passphrase": schema.BoolAttribute{
+				MarkdownDescription: "Require every push to be protected by a passphrase, its own `passphrase` or the `default_passphrase` of the provider. Defaults to `false`",
+				Optional:            true,
+			},
+			"max_expire_after_days": schema.Int64Attribute{
+				MarkdownDescription: fmt.Sprintf("The largest `expire_after_days` a push may have. Pushes that do not set it get the default of the service, checked at plan time as the %d days of the pwpusher app and again once the push is created, which expires pushes breaking the cap", appDefaultExpireAfterDays),
+				Optional:            true,
+			},
+			"max_expire_after_views": schema.Int64Attribute{
+				MarkdownDescription: fmt.Sprintf("The largest `expire_after_views` a push may have. Pushes that do not set it get the default of the service, checked at plan time as the %d views of the pwpusher app and again once the push is created, which expires pushes breaking the cap", appDefaultExpireAfterViews),
+				Optional:            true,
+			},
+			"denied_payloads": schema.ListAttribute{
+				MarkdownDescription: "Regular expressions, in the syntax of Go, matching payloads that must not be pushed, typically secrets of the wrong class such as `-----BEGIN [A-Z ]*PRIVATE KEY-----` for private keys or `AKIA[0-9A-Z]{16}` for AWS access keys",
+				ElementType:         types.StringType,
+				Optional:            true,
+			},
+			"denied_payload_action": schema.StringAttribute{
+				MarkdownDescription: fmt.Sprintf("What happens to pushes whose payload matches `denied_payloads`, `%s` to fail them or `%s` to only warn. Defaults to `%s`", denyActionError, denyActionWarn, denyActionError),
+				Optional:            true,
+			},
+		},
+	}
+}
+
+// newPushPolicy returns the policy of a policy block, which may be nil.
+func newPushPolicy(ctx context.Context, policy *PolicyModel) (pushPolicy, diag.Diagnostics) {
+	var diags diag.Diagnostics
+	if policy == nil {
+		return pushPolicy{}, diags
+	}
+
+	for name, value := range map[string]types.Int64{
+		"max_expire_after_days":  policy.MaxExpireAfterDays,
+		"max_expire_after_views": policy.MaxExpireAfterViews,
+	} {
+		if !value.IsNull() && (value.ValueInt64() < 1 || value.ValueInt64() > math.MaxInt32) {
+			diags.AddAttributeError(
+				path.Root("policy").AtName(name),
+				"Invalid Policy",
+				fmt.Sprintf("The %s attribute must be a positive number.", name),
+			)
+		}
+	}
+
+	result := pushPolicy{
+		requirePassphrase:   policy.RequirePassphrase.ValueBool(),
+		maxExpireAfterDays:  int32(policy.MaxExpireAfterDays.ValueInt64()),
+		maxExpireAfterViews: int32(policy.MaxExpireAfterViews.ValueInt64()),
+	}
+
+	var patterns []string
+	if !policy.DeniedPayloads.IsNull() {
+		diags.Append(policy.DeniedPayloads.ElementsAs(ctx, &patterns, false)...)
+	}
+	for i, pattern := range patterns {
+		denied, err := regexp.Compile(pattern)
+		if err != nil {
+			diags.AddAttributeError(
+				path.Root("policy").AtName("denied_payloads").AtListIndex(i),
+				"Invalid Policy",
+				fmt.Sprintf("Unable to parse the regular expression %q, got error: %s", pattern, err),
+			)
+			continue
+		}
+		result.deniedPayloads = append(result.deniedPayloads, denied)
+	}
+
+	switch action := stringValueOrDefault(policy.DeniedPayloadAction, denyActionError); action {
+	case denyActionError:
+	case denyActionWarn:
+		result.warnOnDenied = true
+	default:
+		diags.AddAttributeError(
+			path.Root("policy").AtName("denied_payload_action"),
+			"Invalid Policy",
+			fmt.Sprintf("The denied_payload_action attribute must be one of `%s`, got %q.", strings.Join([]string{denyActionError, denyActionWarn}, "`, `"), action),
+		)
+	}
+
+	return result, diags
+}
+
+// checkPush returns an error for each rule of the policy the configured push
+// breaks. The provider data and the pwpusher app supply the defaults the push
+// inherits.
+func (p pushPolicy) checkPush(config TextResourceModel, providerData ProviderData) diag.Diagnostics {
+	var diags diag.Diagnostics
+	if p.requirePassphrase && config.Passphrase == nil && providerData.defaultPassphrase == nil {
+		diags.AddAttributeError(
+			path.Root("passphrase"),
+			"Policy Violation",
+			"The require_passphrase policy of the provider requires every push to have a passphrase. Set passphrase, or default_passphrase on the provider.",
+		)
+	}
+
+	for _, limit := range []struct {
+		name  string
+		value types.Int32
+		def   int32
+		max   int32
+	}{
+		{"expire_after_days", config.ExpireAfterDays, appDefaultExpireAfterDays, p.maxExpireAfterDays},
+		{"expire_after_views", config.ExpireAfterViews, appDefaultExpireAfterViews, p.maxExpireAfterViews},
+	} {
+		// Unknown values are checked once they are known.
+		if limit.max == 0 || limit.value.IsUnknown() {
+			continue
+		}
+		value := limit.def
+		if !limit.value.IsNull() {
+			value = limit.value.ValueInt32()
+		}
+		if value > limit.max {
+			diags.AddAttributeError(
+				path.Root(limit.name),
+				"Policy Violation",
+				fmt.Sprintf("The max_%s policy of the provider allows at most %d, the push would have %d. Set %s to at most %d.", limit.name, limit.max, value, limit.name, limit.max),
+			)
+		}
+	}
+
+	// A payload only known at apply time is checked when it is pushed.
+	if !config.Password.IsUnknown() {
+		diags.Append(p.checkPayload(config.Password.ValueString())...)
+	}
+	return diags
+}
+
+// checkCreated returns an error for each expiration cap of the policy the
+// push created by the service breaks, such as a push that got defaults of
+// the service larger than those of the app.
+func (p pushPolicy) checkCreated(push client.Push) diag.Diagnostics {
+	var diags diag.Diagnostics
+	for _, limit := range []struct {
+		name  string
+		value int
+		max   int32
+	}{
+		{"expire_after_days", push.ExpireAfterDays, p.maxExpireAfterDays},
+		{"expire_after_views", push.ExpireAfterViews, p.maxExpireAfterViews},
+	} {
+		if limit.max != 0 && limit.value > int(limit.max) {
+			diags.AddAttributeError(
+				path.Root(limit.name),
+				"Policy Violation",
+				fmt.Sprintf("The max_%s policy of the provider allows at most %d, the service created the push with %d, so it was expired. Set %s to at most %d.", limit.name, limit.max, limit.value, limit.name, limit.max),
+			)
+		}
+	}
+	return diags
+}
+
+// checkPayload returns a diagnostic when payload matches the denied_payloads
+// policy, an error unless the policy only warns. The payload is never part of
+// the message.
+func (p pushPolicy) checkPayload(payload string) diag.Diagnostics {
+	var diags diag.Diagnostics
+	for _, denied := range p.deniedPayloads {
+		if !denied.MatchString(payload) {
+			continue
+		}
+		detail := fmt.Sprintf("The denied_payloads policy of the provider does not allow pushing payloads matching %q.", denied)
+		if p.warnOnDenied {
+			diags.AddAttributeWarning(path.Root("password"), "Denied Payload", detail+" Make sure this is the secret you mean to share.")
+		} else {
+			diags.AddAttributeError(path.Root("password"), "Policy Violation", detail)
+		}
+		break
+	}
+	return diags
+}
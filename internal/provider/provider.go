@@ -5,19 +5,57 @@ package provider
 
 import (
 	"context"
+	"errors"
+	"fmt"
+	"math"
+	"net"
 	"net/http"
+	"net/url"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"terraform-provider-pwpusher/internal/client"
+	"time"
 
 	"github.com/hashicorp/terraform-plugin-framework/datasource"
+	"github.com/hashicorp/terraform-plugin-framework/diag"
 	"github.com/hashicorp/terraform-plugin-framework/function"
+	"github.com/hashicorp/terraform-plugin-framework/path"
 	"github.com/hashicorp/terraform-plugin-framework/provider"
 	"github.com/hashicorp/terraform-plugin-framework/provider/schema"
 	"github.com/hashicorp/terraform-plugin-framework/resource"
 	"github.com/hashicorp/terraform-plugin-framework/types"
+	"golang.org/x/net/http/httpguts"
+	"golang.org/x/oauth2/clientcredentials"
+	"golang.org/x/time/rate"
+)
+
+// defaultRequestTimeout is the default of the request_timeout attribute.
+const defaultRequestTimeout = "30s"
+
+// defaultReadCacheTTL is the default of the read_cache_ttl attribute.
+const defaultReadCacheTTL = "10s"
+
+// The defaults of the circuit_breaker_threshold and circuit_breaker_cooldown
+// attributes.
+const (
+	defaultCircuitBreakerThreshold = 5
+	defaultCircuitBreakerCooldown  = "30s"
 )
 
 // Ensure PwPusherProvider satisfies various provider interfaces.
 var _ provider.Provider = &PwPusherProvider{}
 var _ provider.ProviderWithFunctions = &PwPusherProvider{}
+var _ provider.ProviderWithValidateConfig = &PwPusherProvider{}
+
+// serviceEndpoints maps the names accepted by the endpoint attribute to the
+// URLs of the hosted pwpusher services.
+var serviceEndpoints = map[string]string{
+	"pwpush.com":     "https://pwpush.com",
+	"eu.pwpush.com":  "https://eu.pwpush.com",
+	"oss.pwpush.com": "https://oss.pwpush.com",
+}
 
 // PwPusherProvider defines the provider implementation.
 type PwPusherProvider struct {
@@ -25,16 +63,181 @@ type PwPusherProvider struct {
 	// provider is built and ran locally, and "test" when running acceptance
 	// testing.
 	version string
+
+	// newPushClient returns the client of the API of a service, nil for a
+	// client.Client. Tests set it to use a fake service.
+	newPushClient func(httpClient *http.Client, serviceURL, accountID string) client.PushClient
 }
 
 // PwPusherProviderModel describes the provider data model.
 type PwPusherProviderModel struct {
-	Url types.String `tfsdk:"url"`
+	Endpoint                types.String  `tfsdk:"endpoint"`
+	FallbackUrls            types.List    `tfsdk:"fallback_urls"`
+	Url                     types.String  `tfsdk:"url"`
+	Email                   types.String  `tfsdk:"email"`
+	ApiToken                types.String  `tfsdk:"api_token"`
+	ApiTokenKeychain        types.String  `tfsdk:"api_token_keychain"`
+	AccountId               types.String  `tfsdk:"account_id"`
+	CliConfigFile           types.String  `tfsdk:"cli_config_file"`
+	CredentialsFile         types.String  `tfsdk:"credentials_file"`
+	CredentialsCommand      types.List    `tfsdk:"credentials_command"`
+	Username                types.String  `tfsdk:"username"`
+	Password                types.String  `tfsdk:"password"`
+	CookieJar               types.Bool    `tfsdk:"cookie_jar"`
+	Cookies                 types.Map     `tfsdk:"cookies"`
+	Headers                 types.Map     `tfsdk:"headers"`
+	UserAgentSuffix         types.String  `tfsdk:"user_agent_suffix"`
+	AcceptLanguage          types.String  `tfsdk:"accept_language"`
+	CaCertPem               types.String  `tfsdk:"ca_cert_pem"`
+	CaCertFile              types.String  `tfsdk:"ca_cert_file"`
+	ClientCertPem           types.String  `tfsdk:"client_cert_pem"`
+	ClientCertFile          types.String  `tfsdk:"client_cert_file"`
+	ClientKeyPem            types.String  `tfsdk:"client_key_pem"`
+	ClientKeyFile           types.String  `tfsdk:"client_key_file"`
+	InsecureSkipTlsVerify   types.Bool    `tfsdk:"insecure_skip_tls_verify"`
+	TlsMinVersion           types.String  `tfsdk:"tls_min_version"`
+	TlsCipherSuites         types.List    `tfsdk:"tls_cipher_suites"`
+	TlsPinnedPublicKeys     types.List    `tfsdk:"tls_pinned_public_keys"`
+	StrictTls               types.Bool    `tfsdk:"strict_tls"`
+	TlsKeylogFile           types.String  `tfsdk:"tls_keylog_file"`
+	ProxyUrl                types.String  `tfsdk:"proxy_url"`
+	ProxyUsername           types.String  `tfsdk:"proxy_username"`
+	ProxyPassword           types.String  `tfsdk:"proxy_password"`
+	UnixSocket              types.String  `tfsdk:"unix_socket"`
+	DialAddress             types.String  `tfsdk:"dial_address"`
+	DnsServer               types.String  `tfsdk:"dns_server"`
+	IpFamily                types.String  `tfsdk:"ip_family"`
+	FollowRedirects         types.String  `tfsdk:"follow_redirects"`
+	RequestTimeout          types.String  `tfsdk:"request_timeout"`
+	ReadCacheTtl            types.String  `tfsdk:"read_cache_ttl"`
+	CircuitBreakerThreshold types.Int64   `tfsdk:"circuit_breaker_threshold"`
+	CircuitBreakerCooldown  types.String  `tfsdk:"circuit_breaker_cooldown"`
+	DisableHttp2            types.Bool    `tfsdk:"disable_http2"`
+	MaxIdleConnsPerHost     types.Int64   `tfsdk:"max_idle_conns_per_host"`
+	IdleConnTimeout         types.String  `tfsdk:"idle_conn_timeout"`
+	ReadTimeout             types.String  `tfsdk:"read_timeout"`
+	WriteTimeout            types.String  `tfsdk:"write_timeout"`
+	RequestsPerSecond       types.Float64 `tfsdk:"requests_per_second"`
+	MaxConcurrentRequests   types.Int64   `tfsdk:"max_concurrent_requests"`
+	MaxResponseBytes        types.Int64   `tfsdk:"max_response_bytes"`
+	CompressRequests        types.Bool    `tfsdk:"compress_requests"`
+	SkipHealthCheck         types.Bool    `tfsdk:"skip_health_check"`
+	DryRun                  types.Bool    `tfsdk:"dry_run"`
+	Fake                    types.Bool    `tfsdk:"fake"`
+	FakeSeed                types.String  `tfsdk:"fake_seed"`
+	StrictDecoding          types.Bool    `tfsdk:"strict_decoding"`
+	DebugHttp               types.Bool    `tfsdk:"debug_http"`
+	RequireHttps            types.Bool    `tfsdk:"require_https"`
+	ApiCompatibility        types.String  `tfsdk:"api_compatibility"`
+	DefaultPassphrase       types.String  `tfsdk:"default_passphrase"`
+	DefaultLocale           types.String  `tfsdk:"default_locale"`
+	NamePrefix              types.String  `tfsdk:"name_prefix"`
+	AuditLogPath            types.String  `tfsdk:"audit_log_path"`
+	HarPath                 types.String  `tfsdk:"har_path"`
+	MetricsStatsdAddress    types.String  `tfsdk:"metrics_statsd_address"`
+	MetricsListenAddress    types.String  `tfsdk:"metrics_listen_address"`
+	OtlpTracesEndpoint      types.String  `tfsdk:"otlp_traces_endpoint"`
+	LogRedactionPatterns    types.List    `tfsdk:"log_redaction_patterns"`
+	OAuth2                  *OAuth2Model  `tfsdk:"oauth2"`
+	Retries                 *RetriesModel `tfsdk:"retries"`
+	Policy                  *PolicyModel  `tfsdk:"policy"`
+}
+
+// RetriesModel describes the retries block of the provider and resources.
+type RetriesModel struct {
+	MaxAttempts        types.Int64  `tfsdk:"max_attempts"`
+	MinBackoff         types.String `tfsdk:"min_backoff"`
+	MaxBackoff         types.String `tfsdk:"max_backoff"`
+	RetryOn            types.List   `tfsdk:"retry_on"`
+	MaxRetryAfter      types.String `tfsdk:"max_retry_after"`
+	MaintenanceTimeout types.String `tfsdk:"maintenance_timeout"`
+}
+
+// OAuth2Model describes the oauth2 block of the provider.
+type OAuth2Model struct {
+	TokenUrl     types.String `tfsdk:"token_url"`
+	ClientId     types.String `tfsdk:"client_id"`
+	ClientSecret types.String `tfsdk:"client_secret"`
+	Scopes       types.List   `tfsdk:"scopes"`
 }
 
 type ProviderData struct {
 	client *http.Client
-	url    types.String
+	// anonymousClient sends requests without the credentials, cookies and
+	// headers of the provider, for services other than the provider's.
+	anonymousClient *http.Client
+	url             types.String
+	email           string
+	// fingerprintKey keys the fingerprints of the pushes of the user of
+	// email, see pushFingerprint.
+	fingerprintKey []byte
+	// accountID selects the Pro account of the requests, empty for the
+	// default account of the user.
+	accountID string
+	// defaultPassphrase protects the pushes that do not set their own
+	// passphrase, nil for none.
+	defaultPassphrase *string
+	// defaultLocale is the locale of the URLs of pushes that do not set
+	// their own, empty for none.
+	defaultLocale string
+	// namePrefix is prepended to the names of authenticated pushes.
+	namePrefix string
+	// auditLog records the pushes created and destroyed, nil for none.
+	auditLog  *auditLog
+	redaction logRedaction
+	// dryRun simulates the creation of pushes instead of creating them.
+	dryRun bool
+	// fake answers every request locally, see fakeTransport.
+	fake bool
+	// strictDecoding fails on unknown fields of the responses of the
+	// service.
+	strictDecoding bool
+	// maxResponseBytes is the size of the largest response body to read,
+	// 0 for the default of the client.
+	maxResponseBytes int64
+	// compressRequests compresses large request bodies with gzip.
+	compressRequests bool
+	// breaker stops the requests to failing services, nil for none.
+	breaker *client.Breaker
+	// recorder is told of every request, for the metrics sinks of the
+	// provider, nil for none.
+	recorder client.RequestRecorder
+	// tracer traces the operations and requests, nil for none.
+	tracer *tracer
+	// cache keeps the responses of the reads of the dashboards.
+	cache        *client.Cache
+	retries      retryPolicy
+	policy       pushPolicy
+	requireHttps bool
+	api          client.API
+	// apiDetection detects the API of the service on first use, nil when
+	// the provider selects one.
+	apiDetection *apiDetection
+	// serverInfo remembers the version and routes of the service, nil to
+	// look them up on every use.
+	serverInfo *serverInfo
+	// newPushClient is the newPushClient of the provider.
+	newPushClient func(httpClient *http.Client, serviceURL, accountID string) client.PushClient
+}
+
+// withEndpoint returns a copy of d that sends requests to the service at
+// endpoint instead, anonymously unless it is the service of the provider.
+func (d ProviderData) withEndpoint(attrPath path.Path, endpoint string) (ProviderData, diag.Diagnostics) {
+	serviceURL, diags := checkServiceURL(attrPath, endpoint, d.requireHttps)
+	if diags.HasError() || serviceURL == d.url.ValueString() {
+		return d, diags
+	}
+	d.client = d.anonymousClient
+	d.url = types.StringValue(serviceURL)
+	d.email = ""
+	d.accountID = ""
+	if d.apiDetection != nil {
+		d.apiDetection = &apiDetection{}
+	}
+	if d.serverInfo != nil {
+		d.serverInfo = &serverInfo{}
+	}
+	return d, diags
 }
 
 func (p *PwPusherProvider) Metadata(ctx context.Context, req provider.MetadataRequest, resp *provider.MetadataResponse) {
@@ -45,14 +248,378 @@ func (p *PwPusherProvider) Metadata(ctx context.Context, req provider.MetadataRe
 func (p *PwPusherProvider) Schema(ctx context.Context, req provider.SchemaRequest, resp *provider.SchemaResponse) {
 	resp.Schema = schema.Schema{
 		Attributes: map[string]schema.Attribute{
+			"endpoint": schema.StringAttribute{
+				MarkdownDescription: "A shorthand for the URL of a hosted pwpusher service, one of " + endpointNames() + ". Conflicts with `url`",
+				Optional:            true,
+			},
 			"url": schema.StringAttribute{
-				MarkdownDescription: "The URL for the pwpusher service",
+				MarkdownDescription: "The URL for the pwpusher service, which may include a path prefix such as `https://intranet.example.com/pwpush` for instances hosted below one. Defaults to the `PWPUSH_URL` environment variable, then the URL of the pwpush CLI configuration, or `https://pwpush.com` when unset",
+				Optional:            true,
+			},
+			"fallback_urls": schema.ListAttribute{
+				MarkdownDescription: "The URLs of other ingress points of the same service, tried in order when a request that only reads fails to connect to `url`, for highly available self-hosted instances. Requests that create or delete pushes are never sent to them, so that a push is not created twice",
+				ElementType:         types.StringType,
+				Optional:            true,
+			},
+			"email": schema.StringAttribute{
+				MarkdownDescription: "The email address of the pwpusher account to authenticate as. Must be set together with `api_token`. Defaults to the `PWPUSH_EMAIL` environment variable, then the email of the pwpush CLI configuration",
+				Optional:            true,
+			},
+			"api_token": schema.StringAttribute{
+				MarkdownDescription: "The API token of the pwpusher account to authenticate as, found on the API token page of the account. Authenticated pushes show up on the dashboard and in the audit logs of the account. Defaults to the `PWPUSH_API_TOKEN` environment variable, then the token of the pwpush CLI configuration",
+				Optional:            true,
+				Sensitive:           true,
+			},
+			"api_token_keychain": schema.StringAttribute{
+				MarkdownDescription: "The service name the API token is stored under in the OS credential store, the macOS Keychain, the Windows Credential Manager or the Secret Service on Linux, with `email` as account. The token is read from it when `api_token` is not set, so that workstations keep no plaintext token. On macOS, store it with `security add-generic-password -s <service> -a <email> -w`",
+				Optional:            true,
+			},
+			"account_id": schema.StringAttribute{
+				MarkdownDescription: "The ID of the pwpush.com Pro account to create pushes in and read them from, for users who belong to several accounts. Requires `email` and `api_token`. Defaults to the `PWPUSH_ACCOUNT_ID` environment variable, or the default account of the user",
+				Optional:            true,
+			},
+			"cli_config_file": schema.StringAttribute{
+				MarkdownDescription: "The configuration file of the pwpush CLI to read the URL, email and token from when `url` or `email` and `api_token` are not set, so that users of both do not maintain their credentials twice. The email and token are only read when the URL of the file is the URL of the service, or not set. Defaults to `pwpush/config.ini` in the user configuration directory, such as `~/.config`, and is skipped when that file does not exist",
+				Optional:            true,
+			},
+			"credentials_file": schema.StringAttribute{
+				MarkdownDescription: "A JSON file with the `url`, `email` and `token` of the service, all optional, to read them from when `url` or `email` and `api_token` are not set, which keeps secrets out of the configuration and the environment. The email and token are only read when the `url` of the file is the URL of the service, or not set. It takes precedence over the pwpush CLI configuration. Otherwise the credentials are read from the entry of the host of `url` in the `~/.netrc` file, or the file set by the `NETRC` environment variable, with the email as login and the token as password. Its `default` entry is ignored",
+				Optional:            true,
+			},
+			"credentials_command": schema.ListAttribute{
+				MarkdownDescription: "A program and its arguments to run, without a shell, to get the `url`, `email` and `token` of the service as a JSON object on its standard output, all optional, when `url` or `email` and `api_token` are not set. This integrates vaults and issuers of short-lived tokens. The email and token are only read when the `url` of the output is the URL of the service, or not set. It takes precedence over `credentials_file` and runs for at most " + credentialsCommandTimeout.String(),
+				ElementType:         types.StringType,
+				Optional:            true,
+			},
+			"username": schema.StringAttribute{
+				MarkdownDescription: "The username for HTTP Basic authentication, for self-hosted instances behind a reverse proxy that requires it. Must be set together with `password`. Defaults to the `PWPUSH_USERNAME` environment variable",
+				Optional:            true,
+			},
+			"password": schema.StringAttribute{
+				MarkdownDescription: "The password for HTTP Basic authentication. Defaults to the `PWPUSH_PASSWORD` environment variable",
+				Optional:            true,
+				Sensitive:           true,
+			},
+			"cookie_jar": schema.BoolAttribute{
+				MarkdownDescription: "Keep the cookies set by the server and send them back on later requests, for instances behind a session-based single sign-on front door. Enabled when `cookies` is set",
+				Optional:            true,
+			},
+			"cookies": schema.MapAttribute{
+				MarkdownDescription: "Cookies to send to the service by name, such as the session cookie of a single sign-on front door",
+				ElementType:         types.StringType,
+				Optional:            true,
+				Sensitive:           true,
+			},
+			"headers": schema.MapAttribute{
+				MarkdownDescription: "Headers to send with every request to the service by name, such as `CF-Access-Client-Id` or internal routing headers. The authentication headers set by the provider take precedence",
+				ElementType:         types.StringType,
+				Optional:            true,
+				Sensitive:           true,
+			},
+			"user_agent_suffix": schema.StringAttribute{
+				MarkdownDescription: "Text appended to the `User-Agent` header of requests, `terraform-provider-pwpusher/<version> (terraform)`, so that operators of the service can tell apart the traffic of different pipelines",
+				Optional:            true,
+			},
+			"accept_language": schema.StringAttribute{
+				MarkdownDescription: "The `Accept-Language` header of requests, such as `de` or `fr-CA, fr;q=0.8`, so that the error messages of the service shown in diagnostics are in the language of the operator. Defaults to `default_locale`",
+				Optional:            true,
+			},
+			"ca_cert_pem": schema.StringAttribute{
+				MarkdownDescription: "PEM encoded CA certificates to verify the certificate of the service with, instead of the system trust store, for self-hosted instances with a private CA. Conflicts with `ca_cert_file`",
+				Optional:            true,
+			},
+			"ca_cert_file": schema.StringAttribute{
+				MarkdownDescription: "The path to a file of PEM encoded CA certificates, like `ca_cert_pem`",
+				Optional:            true,
+			},
+			"client_cert_pem": schema.StringAttribute{
+				MarkdownDescription: "The PEM encoded client certificate to present to instances that require mutual TLS. Must be set together with `client_key_pem` or `client_key_file`. Conflicts with `client_cert_file`",
+				Optional:            true,
+			},
+			"client_cert_file": schema.StringAttribute{
+				MarkdownDescription: "The path to the PEM encoded client certificate, like `client_cert_pem`",
+				Optional:            true,
+			},
+			"client_key_pem": schema.StringAttribute{
+				MarkdownDescription: "The PEM encoded private key of the client certificate. Conflicts with `client_key_file`",
+				Optional:            true,
+				Sensitive:           true,
+			},
+			"client_key_file": schema.StringAttribute{
+				MarkdownDescription: "The path to the PEM encoded private key of the client certificate, like `client_key_pem`",
+				Optional:            true,
+			},
+			"insecure_skip_tls_verify": schema.BoolAttribute{
+				MarkdownDescription: "Accept any certificate presented by the service. **This exposes the secrets sent to the service to anyone on the network path**, only use it in lab environments with self-signed certificates and prefer `ca_cert_pem` otherwise. Combined with `tls_pinned_public_keys`, only the pinned keys are accepted",
+				Optional:            true,
+			},
+			"tls_min_version": schema.StringAttribute{
+				MarkdownDescription: "The minimum TLS version of connections to the service, one of " + tlsVersionNames() + ". Defaults to `1.2`",
+				Optional:            true,
+			},
+			"tls_cipher_suites": schema.ListAttribute{
+				MarkdownDescription: "The cipher suites allowed for TLS 1.2 connections by their IANA name, such as `TLS_ECDHE_ECDSA_WITH_AES_256_GCM_SHA384`. TLS 1.3 cipher suites are not configurable. Defaults to the secure cipher suites of Go",
+				ElementType:         types.StringType,
+				Optional:            true,
+			},
+			"tls_pinned_public_keys": schema.ListAttribute{
+				MarkdownDescription: "Pins of the public keys the service may present, as `sha256/` followed by the base64 encoded SHA-256 digest of the DER encoded SubjectPublicKeyInfo. Connections are only accepted when the certificate chain, as verified up to a trusted CA, contains one of the keys. With `insecure_skip_tls_verify`, the chain is not verified and the certificate of the service itself must have one of the keys, which secures services with self-signed certificates. This protects the secrets against interception with a certificate from a rogue CA. Include a backup pin to be able to rotate keys",
+				ElementType:         types.StringType,
+				Optional:            true,
+			},
+			"strict_tls": schema.BoolAttribute{
+				MarkdownDescription: "Restrict connections to the service to TLS 1.2 with FIPS 140-3 approved cipher suites and curves, and require an `https` URL. The cipher suites of TLS 1.3 cannot be restricted, so it is not used in this mode",
+				Optional:            true,
+			},
+			"tls_keylog_file": schema.StringAttribute{
+				MarkdownDescription: "**For debugging only.** A file to append the TLS session keys of connections to the service to, in the format of the `SSLKEYLOGFILE` of curl and browsers, so that a packet capture can be decrypted with Wireshark to debug connections to a self-hosted service. Anyone with the file and a capture can read the secrets pushed, so delete it once done. Conflicts with `strict_tls`",
+				Optional:            true,
+			},
+			"proxy_url": schema.StringAttribute{
+				MarkdownDescription: "The URL of the proxy to connect to the service through, with an `http`, `https` or `socks5` scheme. Hosts listed in the `NO_PROXY` environment variable are still reached directly. Defaults to the `HTTPS_PROXY` or `HTTP_PROXY` environment variable matching the scheme of the service URL",
+				Optional:            true,
+			},
+			"proxy_username": schema.StringAttribute{
+				MarkdownDescription: "The username to authenticate to the proxy with, using Basic authentication. Must be set together with `proxy_password`. Credentials can also be included in the proxy URL. NTLM and Negotiate proxy authentication are not supported",
+				Optional:            true,
+			},
+			"proxy_password": schema.StringAttribute{
+				MarkdownDescription: "The password to authenticate to the proxy with",
+				Optional:            true,
+				Sensitive:           true,
+			},
+			"unix_socket": schema.StringAttribute{
+				MarkdownDescription: "The path of a Unix domain socket to connect to the service through, such as the one of a sidecar, instead of the address of `url`. The URL still sets the `Host` header and the name the TLS certificate is verified for. Cannot be used together with `dial_address` or a proxy",
+				Optional:            true,
+			},
+			"dial_address": schema.StringAttribute{
+				MarkdownDescription: "The host and port to connect to the service at, such as `10.0.0.5:443`, instead of the address of `url`. The URL still sets the `Host` header and the name the TLS certificate is verified for. Cannot be used together with a proxy",
+				Optional:            true,
+			},
+			"dns_server": schema.StringAttribute{
+				MarkdownDescription: "The IP address, and optionally port, of the DNS server resolving the host of the service, for split-horizon DNS environments where the default resolver returns the address of another instance. The port defaults to `" + defaultDNSPort + "`",
+				Optional:            true,
+			},
+			"ip_family": schema.StringAttribute{
+				MarkdownDescription: "The IP version of connections to the service, one of " + ipFamilyNames() + ". Defaults to `any`",
+				Optional:            true,
+			},
+			"follow_redirects": schema.StringAttribute{
+				MarkdownDescription: "Which redirects of the service requests follow, one of " + redirectPolicyNames() + ". `same_host` refuses redirects to other hosts and from https to http, so that a misconfigured service cannot bounce payloads to an unexpected location. Defaults to `always`",
+				Optional:            true,
+			},
+			"read_cache_ttl": schema.StringAttribute{
+				MarkdownDescription: "How long to reuse the responses of the service to reads of its dashboards, such as `30s`, so that many data sources checking pushes share a single request. Older responses are revalidated with their `ETag` or `Last-Modified` header, and creating or expiring a push empties the cache. `0s` always revalidates. Defaults to `" + defaultReadCacheTTL + "`",
 				Optional:            true,
 			},
+			"circuit_breaker_threshold": schema.Int64Attribute{
+				MarkdownDescription: fmt.Sprintf("The number of consecutive requests to the service that must fail, unanswered or with a server error, for the provider to stop sending requests to it for `circuit_breaker_cooldown`. The operations of a large apply then fail right away instead of each waiting for its own timeout. `0` disables the circuit breaker. Defaults to `%d`", defaultCircuitBreakerThreshold),
+				Optional:            true,
+			},
+			"circuit_breaker_cooldown": schema.StringAttribute{
+				MarkdownDescription: "How long the provider stops sending requests to a failing service once the `circuit_breaker_threshold` is reached, such as `1m`. Defaults to `" + defaultCircuitBreakerCooldown + "`",
+				Optional:            true,
+			},
+			"disable_http2": schema.BoolAttribute{
+				MarkdownDescription: "Send requests over HTTP/1.1 only, for self-hosted services behind proxies that mishandle HTTP/2. Defaults to `false`, using HTTP/2 when the service supports it",
+				Optional:            true,
+			},
+			"max_idle_conns_per_host": schema.Int64Attribute{
+				MarkdownDescription: fmt.Sprintf("The number of idle connections to the service to keep open for reuse. Raise it along with the `-parallelism` of Terraform for large applies. Defaults to `%d`", defaultTransportMaxIdleConnsPerHost),
+				Optional:            true,
+			},
+			"idle_conn_timeout": schema.StringAttribute{
+				MarkdownDescription: "How long an idle connection to the service stays open for reuse, such as `90s`. `0s` keeps idle connections open until the service closes them. Defaults to `" + defaultTransportIdleConnTimeout + "`",
+				Optional:            true,
+			},
+			"request_timeout": schema.StringAttribute{
+				MarkdownDescription: "The maximum duration of a request to the service, including reading the response, such as `30s` or `2m`. Defaults to `" + defaultRequestTimeout + "`",
+				Optional:            true,
+			},
+			"read_timeout": schema.StringAttribute{
+				MarkdownDescription: "The maximum duration of requests that only read from the service, such as refreshes, so that they can fail fast. Defaults to `request_timeout`",
+				Optional:            true,
+			},
+			"write_timeout": schema.StringAttribute{
+				MarkdownDescription: "The maximum duration of requests that create, update or delete pushes, so that large file uploads can take longer. Defaults to `request_timeout`",
+				Optional:            true,
+			},
+			"requests_per_second": schema.Float64Attribute{
+				MarkdownDescription: "The maximum rate of requests to the service, shared by all resources and data sources, so that large `for_each` fan-outs do not trip the abuse protection of the service. Requests over the rate wait for their turn. Unlimited by default",
+				Optional:            true,
+			},
+			"max_concurrent_requests": schema.Int64Attribute{
+				MarkdownDescription: "The maximum number of requests in flight to the service at once, shared by all resources and data sources. Terraform runs up to 10 operations in parallel by default. Unlimited by default",
+				Optional:            true,
+			},
+			"max_response_bytes": schema.Int64Attribute{
+				MarkdownDescription: fmt.Sprintf("The size in bytes of the largest response body to read from the service, failing the request on larger ones, such as the HTML pages of misbehaving proxies. Defaults to `%d` (10 MiB)", client.DefaultMaxResponseBytes),
+				Optional:            true,
+			},
+			"compress_requests": schema.BoolAttribute{
+				MarkdownDescription: fmt.Sprintf("Compress request bodies of at least %d bytes with gzip, to push large payloads faster over slow links. The service must accept compressed requests, such as behind a reverse proxy decompressing them. Requests it refuses with `415 Unsupported Media Type` are sent again uncompressed. Defaults to `false`", client.CompressMinBytes),
+				Optional:            true,
+			},
+			"skip_health_check": schema.BoolAttribute{
+				MarkdownDescription: "Skip checking that the service is reachable and accepts the credentials when the provider is configured, for air-gapped workflows that only plan. Defaults to `false`",
+				Optional:            true,
+			},
+			"debug_http": schema.BoolAttribute{
+				MarkdownDescription: "Log every request sent to the service and its response, with their headers and bodies, at the debug level of the `client` log subsystem. Payloads, passphrases and credentials are replaced by `" + redactedHTTPValue + "`, as are bodies that are neither JSON, forms nor text. Defaults to the `PWPUSH_DEBUG_HTTP` environment variable, or `false`",
+				Optional:            true,
+			},
+			"dry_run": schema.BoolAttribute{
+				MarkdownDescription: "Validate pushes and simulate their creation without creating them, so that pipelines exercise configurations without minting working secret links. The URLs of simulated pushes do not work and their tokens start with `dry-run-`. Payloads are checked against the size limit of the new push form of the home page of the service, and simulated pushes get its expiration defaults. Services without one, such as behind a custom front page, get those of the pwpusher app, " + fmt.Sprintf("%d bytes, %d days and %d views", defaultMaxPayloadBytes, appDefaultExpireAfterDays, appDefaultExpireAfterViews) + ". Defaults to the `PWPUSH_DRY_RUN` environment variable, or `false`",
+				Optional:            true,
+			},
+			"fake": schema.BoolAttribute{
+				MarkdownDescription: "Answer every request locally like an empty pwpusher service would, without any network access, for module tests and ephemeral CI environments. Pushes get fake tokens starting with `fake-`, the same for the same configuration across runs, and count as viewed right away. Defaults to the `PWPUSH_FAKE` environment variable, or `false`",
+				Optional:            true,
+			},
+			"fake_seed": schema.StringAttribute{
+				MarkdownDescription: "A secret keying the tokens of the pushes of the `fake` mode, so that their payloads cannot be confirmed by hashing guesses. Tokens are the same across runs with the same seed. Defaults to the `PWPUSH_FAKE_SEED` environment variable, or a key built into the provider",
+				Optional:            true,
+				Sensitive:           true,
+			},
+			"strict_decoding": schema.BoolAttribute{
+				MarkdownDescription: "**For debugging only.** Fail on fields of the responses of the service that the provider does not know, to catch changes of the API of new releases of the service before they go unnoticed. Defaults to the `PWPUSH_STRICT_DECODING` environment variable, or `false`, ignoring unknown fields",
+				Optional:            true,
+			},
+			"require_https": schema.BoolAttribute{
+				MarkdownDescription: "Refuse `http` service URLs, over which secrets would cross the network unencrypted. URLs of the local host are always allowed. Defaults to `true`",
+				Optional:            true,
+			},
+			"api_compatibility": schema.StringAttribute{
+				MarkdownDescription: "The API of the pwpush release the service runs, one of " + apiCompatibilityNames() + ". Self-hosted services running releases older than 1.0 need `legacy`, which nests the fields of new pushes under `password` and does not support passphrases. Both create pushes at the same paths, only their payloads differ. Defaults to `auto`, which detects the API of the service on first use by whether it has the `/api/v1` endpoints of 1.0",
+				Optional:            true,
+			},
+			"default_passphrase": schema.StringAttribute{
+				MarkdownDescription: "The passphrase recipients must enter to view the pushes that do not set their own `passphrase`, to protect every push of the provider. Defaults to the `PWPUSH_DEFAULT_PASSPHRASE` environment variable",
+				Optional:            true,
+				Sensitive:           true,
+			},
+			"default_locale": schema.StringAttribute{
+				MarkdownDescription: "The locale of the URLs of the pushes that do not set their own `locale`, one of the codes of the `pwpusher_locales` data source. Defaults to the locale of the browser of the recipient",
+				Optional:            true,
+			},
+			"name_prefix": schema.StringAttribute{
+				MarkdownDescription: "Prepended to the `name` of every authenticated push, such as `terraform/`, so that the pushes of Terraform can be told apart and filtered in the dashboard. Pushes without a `name` are named after the prefix alone. Defaults to the `PWPUSH_NAME_PREFIX` environment variable",
+				Optional:            true,
+			},
+			"audit_log_path": schema.StringAttribute{
+				MarkdownDescription: "A file every push created, or expired when it is destroyed, appends a JSON record to, with its time, resource type, token and expiration settings but never its payload, for ingestion by a SIEM. The tokens give access to the pushes, so the file is only readable by its owner. Defaults to the `PWPUSH_AUDIT_LOG_PATH` environment variable",
+				Optional:            true,
+			},
+			"har_path": schema.StringAttribute{
+				MarkdownDescription: "A [HAR](http://www.softwareishard.com/blog/har-12-spec/) file every request sent to the service and its response are recorded to, to attach to reports of issues with specific versions of the service. Payloads, passphrases and credentials are replaced by `" + redactedHTTPValue + "`, as with `debug_http`. Runs of Terraform add their requests to the file, delete it to start a new capture. The tokens of pushes are not redacted, so the file is only readable by its owner. Defaults to the `PWPUSH_HAR_PATH` environment variable",
+				Optional:            true,
+			},
+			"metrics_statsd_address": schema.StringAttribute{
+				MarkdownDescription: "The host and port of a statsd server to send metrics of the requests to the service to over UDP while the provider runs, such as `127.0.0.1:8125`: the counters `pwpusher.requests.<operation>` and `pwpusher.errors.<operation>` and the timer `pwpusher.request_duration.<operation>`. Errors are requests that were unanswered or answered with a server error. Defaults to the `PWPUSH_METRICS_STATSD_ADDRESS` environment variable",
+				Optional:            true,
+			},
+			"metrics_listen_address": schema.StringAttribute{
+				MarkdownDescription: "A local address to serve metrics of the requests to the service on at `/metrics` in the Prometheus text format while the provider runs, such as `127.0.0.1:9464`: the counters `pwpusher_requests_total` and `pwpusher_request_errors_total` and the histogram `pwpusher_request_duration_seconds`. Defaults to the `PWPUSH_METRICS_LISTEN_ADDRESS` environment variable",
+				Optional:            true,
+			},
+			"otlp_traces_endpoint": schema.StringAttribute{
+				MarkdownDescription: "The URL of an OpenTelemetry collector to export traces of the operations of the provider and of their requests to the service to, with OTLP over HTTP in its JSON encoding, such as `http://127.0.0.1:4318/v1/traces`. The spans of requests record the client operation, method, URL, response status and retry count, with the tokens of pushes replaced by `{url_token}`, and are propagated to the service in the `traceparent` header. The spans of operations are children of the span of the `TRACEPARENT` environment variable, when it is set, so that they join the trace of a CI pipeline. The spans of an operation are exported when it ends, with the headers of the `OTEL_EXPORTER_OTLP_TRACES_HEADERS` or `OTEL_EXPORTER_OTLP_HEADERS` environment variable. Defaults to the `OTEL_EXPORTER_OTLP_TRACES_ENDPOINT` environment variable, or to `/v1/traces` below `OTEL_EXPORTER_OTLP_ENDPOINT`",
+				Optional:            true,
+			},
+			"log_redaction_patterns": schema.ListAttribute{
+				MarkdownDescription: "Regular expressions, in the syntax of Go, matching text to replace with `" + redactedText + "` in the logs and diagnostics of the provider, such as the internal identifiers of an environment. Payloads and passphrases are always redacted",
+				ElementType:         types.StringType,
+				Optional:            true,
+			},
+		},
+
+		Blocks: map[string]schema.Block{
+			"oauth2": schema.SingleNestedBlock{
+				MarkdownDescription: "Obtains a bearer token with the OAuth2 client credentials flow, for instances behind a proxy that enforces OAuth2 such as oauth2-proxy or an API gateway. The token is refreshed when it expires. Conflicts with `username` and `password`",
+				Attributes: map[string]schema.Attribute{
+					"token_url": schema.StringAttribute{
+						MarkdownDescription: "The URL of the token endpoint of the authorization server",
+						Optional:            true,
+					},
+					"client_id": schema.StringAttribute{
+						MarkdownDescription: "The client ID",
+						Optional:            true,
+					},
+					"client_secret": schema.StringAttribute{
+						MarkdownDescription: "The client secret",
+						Optional:            true,
+						Sensitive:           true,
+					},
+					"scopes": schema.ListAttribute{
+						MarkdownDescription: "The scopes to request",
+						ElementType:         types.StringType,
+						Optional:            true,
+					},
+				},
+			},
+			"retries": schema.SingleNestedBlock{
+				MarkdownDescription: "Retries requests that fail with a network error or a retryable response, waiting a jittered exponential backoff between attempts. Pushes that failed after the service may have created them, such as with `504 Gateway Timeout`, are only sent again once their dashboard shows they were not created, which requires authenticating, so that retries never push a secret twice",
+				Attributes: map[string]schema.Attribute{
+					"max_attempts": schema.Int64Attribute{
+						MarkdownDescription: fmt.Sprintf("The maximum number of attempts of a request, including the first one. `1` disables retries. Defaults to `%d`", defaultRetryMaxAttempts),
+						Optional:            true,
+					},
+					"min_backoff": schema.StringAttribute{
+						MarkdownDescription: "The backoff after the first failed attempt, doubled after each following one. Defaults to `" + defaultRetryMinBackoff + "`",
+						Optional:            true,
+					},
+					"max_backoff": schema.StringAttribute{
+						MarkdownDescription: "The maximum backoff between two attempts. Defaults to `" + defaultRetryMaxBackoff + "`",
+						Optional:            true,
+					},
+					"retry_on": schema.ListAttribute{
+						MarkdownDescription: "The response status codes to retry. Defaults to `500`, `502`, `503` and `504`. Rate limited `429` responses are always retried",
+						ElementType:         types.Int64Type,
+						Optional:            true,
+					},
+					"max_retry_after": schema.StringAttribute{
+						MarkdownDescription: "The longest delay requested by the `Retry-After` header of a rate limited response that the provider waits for before retrying. Requests asked to wait longer fail. Defaults to `" + defaultMaxRetryAfter + "`",
+						Optional:            true,
+					},
+					"maintenance_timeout": schema.StringAttribute{
+						MarkdownDescription: "How long `503` responses, usually returned while a self-hosted instance is upgraded, are retried for regardless of `max_attempts`, with a longer backoff of up to a minute. `0s` retries them like other failures. Defaults to `" + defaultMaintenanceTime + "`",
+						Optional:            true,
+					},
+				},
+			},
+			"policy": policyBlock(),
 		},
 	}
 }
 
+func (p *PwPusherProvider) ValidateConfig(ctx context.Context, req provider.ValidateConfigRequest, resp *provider.ValidateConfigResponse) {
+	var data PwPusherProviderModel
+
+	resp.Diagnostics.Append(req.Config.Get(ctx, &data)...)
+
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	// Values that are not known yet are checked when the provider is
+	// configured instead.
+	if !data.Endpoint.IsNull() && !data.Endpoint.IsUnknown() {
+		resp.Diagnostics.Append(validateEndpoint(data)...)
+	}
+	if !data.Url.IsNull() && !data.Url.IsUnknown() {
+		if _, err := normalizeServiceURL(data.Url.ValueString()); err != nil {
+			resp.Diagnostics.AddAttributeError(path.Root("url"), "Invalid URL", err.Error())
+		}
+	}
+	if !data.DefaultLocale.IsNull() && !data.DefaultLocale.IsUnknown() {
+		resp.Diagnostics.Append(checkLocale(path.Root("default_locale"), data.DefaultLocale.ValueString(), supportedLocales)...)
+	}
+	if !data.ApiCompatibility.IsUnknown() {
+		_, _, diags := selectAPICompatibility(data.ApiCompatibility)
+		resp.Diagnostics.Append(diags...)
+	}
+}
+
 func (p *PwPusherProvider) Configure(ctx context.Context, req provider.ConfigureRequest, resp *provider.ConfigureResponse) {
 	var data PwPusherProviderModel
 
@@ -61,14 +628,495 @@ func (p *PwPusherProvider) Configure(ctx context.Context, req provider.Configure
 	if resp.Diagnostics.HasError() {
 		return
 	}
+	redaction, diags := newLogRedaction(ctx, data.LogRedactionPatterns)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	ctx = redaction.context(ctx)
+	defer func() { resp.Diagnostics = redaction.diagnostics(resp.Diagnostics) }()
+
+	if !data.Endpoint.IsNull() {
+		resp.Diagnostics.Append(validateEndpoint(data)...)
+		if resp.Diagnostics.HasError() {
+			return
+		}
+		data.Url = types.StringValue(serviceEndpoints[data.Endpoint.ValueString()])
+	}
+	data.Url = stringValueOrEnv(data.Url, "PWPUSH_URL")
+	data.Email = stringValueOrEnv(data.Email, "PWPUSH_EMAIL")
+	data.ApiToken = stringValueOrEnv(data.ApiToken, "PWPUSH_API_TOKEN")
+	resp.Diagnostics.Append(applyKeychain(&data)...)
+	resp.Diagnostics.Append(applyCredentialsCommand(ctx, &data)...)
+	resp.Diagnostics.Append(applyCredentialsFile(&data)...)
+	resp.Diagnostics.Append(applyCLIConfig(&data)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	data.AccountId = stringValueOrEnv(data.AccountId, "PWPUSH_ACCOUNT_ID")
+	data.DefaultPassphrase = stringValueOrEnv(data.DefaultPassphrase, "PWPUSH_DEFAULT_PASSPHRASE")
+	data.NamePrefix = stringValueOrEnv(data.NamePrefix, "PWPUSH_NAME_PREFIX")
+	data.AuditLogPath = stringValueOrEnv(data.AuditLogPath, "PWPUSH_AUDIT_LOG_PATH")
+	data.HarPath = stringValueOrEnv(data.HarPath, "PWPUSH_HAR_PATH")
+	data.MetricsStatsdAddress = stringValueOrEnv(data.MetricsStatsdAddress, "PWPUSH_METRICS_STATSD_ADDRESS")
+	data.MetricsListenAddress = stringValueOrEnv(data.MetricsListenAddress, "PWPUSH_METRICS_LISTEN_ADDRESS")
+	data.OtlpTracesEndpoint = otlpTracesEndpoint(data.OtlpTracesEndpoint)
+	dryRun, err := boolValueOrEnv(data.DryRun, "PWPUSH_DRY_RUN")
+	if err != nil {
+		resp.Diagnostics.AddAttributeError(path.Root("dry_run"), "Invalid Environment Variable", err.Error())
+		return
+	}
+	data.FakeSeed = stringValueOrEnv(data.FakeSeed, "PWPUSH_FAKE_SEED")
+	fake, err := boolValueOrEnv(data.Fake, "PWPUSH_FAKE")
+	if err != nil {
+		resp.Diagnostics.AddAttributeError(path.Root("fake"), "Invalid Environment Variable", err.Error())
+		return
+	}
+	strictDecoding, err := boolValueOrEnv(data.StrictDecoding, "PWPUSH_STRICT_DECODING")
+	if err != nil {
+		resp.Diagnostics.AddAttributeError(path.Root("strict_decoding"), "Invalid Environment Variable", err.Error())
+		return
+	}
+	debugHTTP, err := boolValueOrEnv(data.DebugHttp, "PWPUSH_DEBUG_HTTP")
+	if err != nil {
+		resp.Diagnostics.AddAttributeError(path.Root("debug_http"), "Invalid Environment Variable", err.Error())
+		return
+	}
+	data.Username = stringValueOrEnv(data.Username, "PWPUSH_USERNAME")
+	data.Password = stringValueOrEnv(data.Password, "PWPUSH_PASSWORD")
+
 	if data.Url.IsNull() {
 		data.Url = types.StringValue("https://pwpush.com")
 	}
+	resp.Diagnostics.Append(applyNetrc(&data)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	requireHttps := data.RequireHttps.IsNull() || data.RequireHttps.ValueBool()
+	serviceURL, diags := checkServiceURL(path.Root("url"), data.Url.ValueString(), requireHttps)
+	resp.Diagnostics.Append(diags...)
+	api, detection, diags := selectAPICompatibility(data.ApiCompatibility)
+	resp.Diagnostics.Append(diags...)
+	if !data.DefaultLocale.IsNull() {
+		resp.Diagnostics.Append(checkLocale(path.Root("default_locale"), data.DefaultLocale.ValueString(), supportedLocales)...)
+	}
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	data.Url = types.StringValue(serviceURL)
+
+	var fallbackURLs []string
+	if !data.FallbackUrls.IsNull() {
+		resp.Diagnostics.Append(data.FallbackUrls.ElementsAs(ctx, &fallbackURLs, false)...)
+	}
+	for i, fallbackURL := range fallbackURLs {
+		fallbackURLs[i], diags = checkServiceURL(path.Root("fallback_urls").AtListIndex(i), fallbackURL, requireHttps)
+		resp.Diagnostics.Append(diags...)
+	}
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	if data.Email.IsNull() != data.ApiToken.IsNull() {
+		resp.Diagnostics.AddAttributeError(
+			path.Root("api_token"),
+			"Incomplete Credentials",
+			"The email and api_token attributes must be set together to authenticate with the pwpusher service.",
+		)
+		return
+	}
+	if !data.AccountId.IsNull() && data.Email.IsNull() {
+		resp.Diagnostics.AddAttributeError(
+			path.Root("account_id"),
+			"Missing Credentials",
+			"The account_id attribute requires the email and api_token attributes, anonymous pushes do not belong to an account.",
+		)
+		return
+	}
+	if data.Username.IsNull() != data.Password.IsNull() {
+		resp.Diagnostics.AddAttributeError(
+			path.Root("password"),
+			"Incomplete Credentials",
+			"The username and password attributes must be set together to authenticate with HTTP Basic authentication.",
+		)
+		return
+	}
+	if data.OAuth2 != nil && !data.Username.IsNull() {
+		resp.Diagnostics.AddAttributeError(
+			path.Root("oauth2"),
+			"Conflicting Credentials",
+			"The oauth2 block cannot be used together with the username and password attributes, as both authenticate with the Authorization header.",
+		)
+		return
+	}
+
+	requestTimeout, err := time.ParseDuration(stringValueOrDefault(data.RequestTimeout, defaultRequestTimeout))
+	if err != nil {
+		resp.Diagnostics.AddAttributeError(path.Root("request_timeout"), "Invalid Duration", err.Error())
+		return
+	}
+	readTimeout, err := time.ParseDuration(stringValueOrDefault(data.ReadTimeout, requestTimeout.String()))
+	if err != nil {
+		resp.Diagnostics.AddAttributeError(path.Root("read_timeout"), "Invalid Duration", err.Error())
+		return
+	}
+	writeTimeout, err := time.ParseDuration(stringValueOrDefault(data.WriteTimeout, requestTimeout.String()))
+	if err != nil {
+		resp.Diagnostics.AddAttributeError(path.Root("write_timeout"), "Invalid Duration", err.Error())
+		return
+	}
+
+	readCacheTTL, err := time.ParseDuration(stringValueOrDefault(data.ReadCacheTtl, defaultReadCacheTTL))
+	if err != nil {
+		resp.Diagnostics.AddAttributeError(path.Root("read_cache_ttl"), "Invalid Duration", err.Error())
+		return
+	}
+
+	var recorder recorders
+	if !data.MetricsStatsdAddress.IsNull() {
+		sink, err := newStatsdSink(data.MetricsStatsdAddress.ValueString())
+		if err != nil {
+			resp.Diagnostics.AddAttributeError(path.Root("metrics_statsd_address"), "Invalid Metrics Address", fmt.Sprintf("Unable to send metrics to %s, got error: %s", data.MetricsStatsdAddress.ValueString(), err))
+			return
+		}
+		recorder = append(recorder, sink)
+	}
+	if !data.MetricsListenAddress.IsNull() {
+		sink, err := servePrometheusSink(data.MetricsListenAddress.ValueString())
+		if err != nil {
+			resp.Diagnostics.AddAttributeError(path.Root("metrics_listen_address"), "Invalid Metrics Address", fmt.Sprintf("Unable to serve metrics on %s, got error: %s", data.MetricsListenAddress.ValueString(), err))
+			return
+		}
+		recorder = append(recorder, sink)
+	}
+	var tracer *tracer
+	if !data.OtlpTracesEndpoint.IsNull() {
+		tracer, err = newTracer(data.OtlpTracesEndpoint.ValueString(), p.version)
+		if err != nil {
+			resp.Diagnostics.AddAttributeError(path.Root("otlp_traces_endpoint"), "Invalid Tracing Endpoint", fmt.Sprintf("Unable to export traces to %s, got error: %s", data.OtlpTracesEndpoint.ValueString(), err))
+			return
+		}
+	}
+
+	var breaker *client.Breaker
+	threshold := int64(defaultCircuitBreakerThreshold)
+	if !data.CircuitBreakerThreshold.IsNull() {
+		threshold = data.CircuitBreakerThreshold.ValueInt64()
+	}
+	if threshold < 0 {
+		resp.Diagnostics.AddAttributeError(path.Root("circuit_breaker_threshold"), "Invalid Circuit Breaker", "The circuit_breaker_threshold attribute must be at least 0.")
+		return
+	}
+	cooldown, err := time.ParseDuration(stringValueOrDefault(data.CircuitBreakerCooldown, defaultCircuitBreakerCooldown))
+	if err != nil {
+		resp.Diagnostics.AddAttributeError(path.Root("circuit_breaker_cooldown"), "Invalid Duration", err.Error())
+		return
+	}
+	if threshold > 0 {
+		breaker = client.NewBreaker(int(threshold), cooldown)
+	}
+
+	connections := connectionSettings{
+		disableHTTP2:        data.DisableHttp2.ValueBool(),
+		maxIdleConnsPerHost: defaultTransportMaxIdleConnsPerHost,
+	}
+	if !data.MaxIdleConnsPerHost.IsNull() {
+		if data.MaxIdleConnsPerHost.ValueInt64() < 1 {
+			resp.Diagnostics.AddAttributeError(path.Root("max_idle_conns_per_host"), "Invalid Connection Pool", "The max_idle_conns_per_host attribute must be at least 1.")
+			return
+		}
+		connections.maxIdleConnsPerHost = int(data.MaxIdleConnsPerHost.ValueInt64())
+	}
+	connections.idleConnTimeout, err = time.ParseDuration(stringValueOrDefault(data.IdleConnTimeout, defaultTransportIdleConnTimeout))
+	if err != nil {
+		resp.Diagnostics.AddAttributeError(path.Root("idle_conn_timeout"), "Invalid Duration", err.Error())
+		return
+	}
+
+	var limiter *rate.Limiter
+	if rps := data.RequestsPerSecond.ValueFloat64(); !data.RequestsPerSecond.IsNull() {
+		if rps <= 0 {
+			resp.Diagnostics.AddAttributeError(path.Root("requests_per_second"), "Invalid Rate", "The requests_per_second attribute must be greater than 0.")
+			return
+		}
+		// The burst allows a second worth of requests at once, and at least
+		// one request.
+		limiter = rate.NewLimiter(rate.Limit(rps), int(math.Ceil(rps)))
+	}
+
+	var sem chan struct{}
+	if !data.MaxConcurrentRequests.IsNull() {
+		if data.MaxConcurrentRequests.ValueInt64() < 1 {
+			resp.Diagnostics.AddAttributeError(path.Root("max_concurrent_requests"), "Invalid Concurrency", "The max_concurrent_requests attribute must be at least 1.")
+			return
+		}
+		sem = make(chan struct{}, data.MaxConcurrentRequests.ValueInt64())
+	}
+	if !data.MaxResponseBytes.IsNull() && data.MaxResponseBytes.ValueInt64() < 1 {
+		resp.Diagnostics.AddAttributeError(path.Root("max_response_bytes"), "Invalid Response Size Limit", "The max_response_bytes attribute must be at least 1.")
+		return
+	}
+
+	retries, diags := newRetryPolicy(ctx, defaultRetryPolicy(), data.Retries)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	policy, diags := newPushPolicy(ctx, data.Policy)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	tlsConfig, diags := newTLSConfig(ctx, data)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	if data.ProxyUsername.IsNull() != data.ProxyPassword.IsNull() {
+		resp.Diagnostics.AddAttributeError(
+			path.Root("proxy_password"),
+			"Incomplete Credentials",
+			"The proxy_username and proxy_password attributes must be set together to authenticate with the proxy.",
+		)
+		return
+	}
+	var proxyUser *url.Userinfo
+	if !data.ProxyUsername.IsNull() {
+		proxyUser = url.UserPassword(data.ProxyUsername.ValueString(), data.ProxyPassword.ValueString())
+	}
+	proxy, err := newProxyFunc(data.ProxyUrl.ValueString(), proxyUser)
+	if err != nil {
+		resp.Diagnostics.AddAttributeError(path.Root("proxy_url"), "Invalid Proxy URL", err.Error())
+		return
+	}
+	dial, diags := newDialFunc(data)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	overridesAddress := !data.UnixSocket.IsNull() || !data.DialAddress.IsNull()
+	if overridesAddress && !data.ProxyUrl.IsNull() {
+		resp.Diagnostics.AddAttributeError(
+			path.Root("proxy_url"),
+			"Conflicting Dial Configuration",
+			"The proxy_url attribute cannot be used together with unix_socket or dial_address, which set where connections go.",
+		)
+		return
+	}
+
+	var base http.RoundTripper = newFakeTransport(data.FakeSeed.ValueString())
+	if !fake {
+		if overridesAddress {
+			// Connections go to the configured address, the proxy of the
+			// environment would take them elsewhere.
+			proxy = nil
+		}
+		// The clients of the provider share the connections of the
+		// transport.
+		base = newNetworkTransport(tlsConfig, proxy, dial, connections)
+	}
+	if debugHTTP {
+		// Logging below the other transports shows every attempt with the
+		// headers it was sent with, token requests included.
+		base = &debugTransport{next: base}
+	}
+	if !data.HarPath.IsNull() {
+		capture, err := openHARCapture(data.HarPath.ValueString(), p.version)
+		if err != nil {
+			resp.Diagnostics.AddAttributeError(path.Root("har_path"), "Invalid HAR Capture", "Unable to capture requests to "+data.HarPath.ValueString()+": "+err.Error())
+			return
+		}
+		base = &harTransport{capture: capture, next: base}
+	}
+
+	transport := base
+	// The fake service has no token endpoint and accepts any request.
+	if data.OAuth2 != nil && !fake {
+		// The attributes of a block cannot be required without requiring
+		// the block itself, so they are checked here instead.
+		for name, value := range map[string]types.String{
+			"token_url":     data.OAuth2.TokenUrl,
+			"client_id":     data.OAuth2.ClientId,
+			"client_secret": data.OAuth2.ClientSecret,
+		} {
+			if value.IsNull() {
+				resp.Diagnostics.AddAttributeError(
+					path.Root("oauth2").AtName(name),
+					"Missing OAuth2 Configuration",
+					fmt.Sprintf("The %s attribute is required in the oauth2 block.", name),
+				)
+			}
+		}
+		if resp.Diagnostics.HasError() {
+			return
+		}
+
+		var scopes []string
+		resp.Diagnostics.Append(data.OAuth2.Scopes.ElementsAs(ctx, &scopes, false)...)
+		if resp.Diagnostics.HasError() {
+			return
+		}
+		config := clientcredentials.Config{
+			ClientID:     data.OAuth2.ClientId.ValueString(),
+			ClientSecret: data.OAuth2.ClientSecret.ValueString(),
+			TokenURL:     data.OAuth2.TokenUrl.ValueString(),
+			Scopes:       scopes,
+		}
+		// Tokens are requested over the same connections as the service so
+		// that they share its TLS configuration.
+		transport = newOAuth2Transport(config, &http.Client{Transport: base}, transport)
+	}
+
+	var jar http.CookieJar
+	if data.CookieJar.ValueBool() || !data.Cookies.IsNull() {
+		var cookies map[string]string
+		resp.Diagnostics.Append(data.Cookies.ElementsAs(ctx, &cookies, false)...)
+		if resp.Diagnostics.HasError() {
+			return
+		}
+		jar, err = newCookieJar(data.Url.ValueString(), cookies)
+		if err != nil {
+			resp.Diagnostics.AddAttributeError(path.Root("cookies"), "Invalid Cookies", fmt.Sprintf("Unable to create the cookie jar: %s", err))
+			return
+		}
+	}
+
+	var customHeaders map[string]string
+	resp.Diagnostics.Append(data.Headers.ElementsAs(ctx, &customHeaders, false)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	headers := http.Header{}
+	for name, value := range customHeaders {
+		if !httpguts.ValidHeaderFieldName(name) || !httpguts.ValidHeaderFieldValue(value) {
+			resp.Diagnostics.AddAttributeError(
+				path.Root("headers").AtMapKey(name),
+				"Invalid Header",
+				fmt.Sprintf("The header %q is not a valid HTTP header name and value.", name),
+			)
+			return
+		}
+		headers.Set(name, value)
+	}
+	if !data.ApiToken.IsNull() {
+		headers.Set("X-User-Email", data.Email.ValueString())
+		headers.Set("X-User-Token", data.ApiToken.ValueString())
+	}
+	if !data.Username.IsNull() {
+		headers.Set("Authorization", basicAuthorization(data.Username.ValueString(), data.Password.ValueString()))
+	}
+
+	agent := userAgent(p.version, data.UserAgentSuffix.ValueString())
+	if !httpguts.ValidHeaderFieldValue(agent) {
+		resp.Diagnostics.AddAttributeError(
+			path.Root("user_agent_suffix"),
+			"Invalid User-Agent Suffix",
+			"The user_agent_suffix attribute must be a valid HTTP header value.",
+		)
+		return
+	}
+
+	clientHeaders := http.Header{"User-Agent": {agent}}
+	if language := stringValueOrDefault(data.AcceptLanguage, data.DefaultLocale.ValueString()); language != "" {
+		if !httpguts.ValidHeaderFieldValue(language) {
+			resp.Diagnostics.AddAttributeError(
+				path.Root("accept_language"),
+				"Invalid Accept-Language",
+				"The accept_language attribute must be a valid HTTP header value.",
+			)
+			return
+		}
+		clientHeaders.Set("Accept-Language", language)
+	}
+
+	// Every client shares the same limits and connections.
+	checkRedirect, ok := redirectPolicies[stringValueOrDefault(data.FollowRedirects, followRedirectsAlways)]
+	if !ok {
+		resp.Diagnostics.AddAttributeError(
+			path.Root("follow_redirects"),
+			"Invalid Redirect Policy",
+			fmt.Sprintf("The follow_redirects attribute must be one of %s, got %q.", redirectPolicyNames(), data.FollowRedirects.ValueString()),
+		)
+		return
+	}
+
+	newClient := func(next http.RoundTripper, jar http.CookieJar) *http.Client {
+		next = &requestIDTransport{next: &headerTransport{headers: clientHeaders, next: next}}
+		return &http.Client{
+			Transport: &tracingTransport{
+				tracer: tracer,
+				next: &retryTransport{
+					policy: retries,
+					next: &failoverTransport{
+						primary:   serviceURL,
+						fallbacks: fallbackURLs,
+						next: &rateLimitTransport{
+							limiter: limiter,
+							next: &concurrencyTransport{
+								sem: sem,
+								next: &timeoutTransport{
+									read:  readTimeout,
+									write: writeTimeout,
+									next:  next,
+								},
+							},
+						},
+					},
+				},
+			},
+			CheckRedirect: checkRedirect,
+			Jar:           jar,
+		}
+	}
 
 	providerData := ProviderData{
-		client: http.DefaultClient,
-		url:    data.Url,
+		client:            newClient(&headerTransport{headers: headers, next: transport}, jar),
+		anonymousClient:   newClient(base, nil),
+		url:               data.Url,
+		email:             data.Email.ValueString(),
+		fingerprintKey:    fingerprintKey(data.ApiToken.ValueString()),
+		accountID:         data.AccountId.ValueString(),
+		defaultPassphrase: data.DefaultPassphrase.ValueStringPointer(),
+		defaultLocale:     data.DefaultLocale.ValueString(),
+		namePrefix:        data.NamePrefix.ValueString(),
+		dryRun:            dryRun,
+		fake:              fake,
+		strictDecoding:    strictDecoding,
+		maxResponseBytes:  data.MaxResponseBytes.ValueInt64(),
+		compressRequests:  data.CompressRequests.ValueBool(),
+		breaker:           breaker,
+		tracer:            tracer,
+		cache:             client.NewCache(readCacheTTL),
+		policy:            policy,
+		retries:           retries,
+		requireHttps:      requireHttps,
+		api:               api,
+		apiDetection:      detection,
+		serverInfo:        &serverInfo{},
+		redaction:         redaction,
+		newPushClient:     p.newPushClient,
+	}
+	if !data.AuditLogPath.IsNull() {
+		providerData.auditLog = &auditLog{path: data.AuditLogPath.ValueString()}
 	}
+	// An empty list in the interface would still be called for every
+	// request.
+	if len(recorder) > 0 {
+		providerData.recorder = recorder
+	}
+
+	if !data.SkipHealthCheck.ValueBool() {
+		ctx, op := providerData.operationContext(ctx, client.LogSubsystem, "provider configure")
+		diags := providerData.healthCheck(ctx)
+		op.end(&diags)
+		resp.Diagnostics.Append(diags...)
+		if resp.Diagnostics.HasError() {
+			return
+		}
+	}
+
 	resp.DataSourceData = providerData
 	resp.ResourceData = providerData
 }
@@ -80,11 +1128,35 @@ func (p *PwPusherProvider) Resources(ctx context.Context) []func() resource.Reso
 }
 
 func (p *PwPusherProvider) DataSources(ctx context.Context) []func() datasource.DataSource {
-	return []func() datasource.DataSource{}
+	return []func() datasource.DataSource{
+		NewPushViewedDataSource,
+		NewStatsDataSource,
+		NewHealthDataSource,
+		NewLocalesDataSource,
+		NewTokenInfoDataSource,
+		NewFeaturesDataSource,
+		NewPushCheckDataSource,
+	}
 }
 
 func (p *PwPusherProvider) Functions(ctx context.Context) []func() function.Function {
-	return []func() function.Function{}
+	return []func() function.Function{
+		NewParseTokenFunction,
+		NewGeneratePasswordFunction,
+		NewGeneratePassphraseFunction,
+		NewPasswordStrengthFunction,
+		NewRedactFunction,
+		NewIsExpiredFunction,
+		NewExpiryTimestampFunction,
+		NewHumanizeRemainingFunction,
+		NewValidatePayloadFunction,
+		NewShareMessageFunction,
+		NewMailtoLinkFunction,
+		NewQrDataUriFunction,
+		NewWifiQrPayloadFunction,
+		NewVcardQrPayloadFunction,
+		NewFormatEnvFunction,
+	}
 }
 
 func New(version string) func() provider.Provider {
@@ -94,3 +1166,190 @@ func New(version string) func() provider.Provider {
 		}
 	}
 }
+
+// validateEndpoint checks the endpoint attribute of data, which must be set.
+func validateEndpoint(data PwPusherProviderModel) diag.Diagnostics {
+	var diags diag.Diagnostics
+	if !data.Url.IsNull() {
+		diags.AddAttributeError(
+			path.Root("endpoint"),
+			"Conflicting Configuration",
+			"Only one of the endpoint and url attributes can be set.",
+		)
+	}
+	if _, ok := serviceEndpoints[data.Endpoint.ValueString()]; !ok {
+		diags.AddAttributeError(
+			path.Root("endpoint"),
+			"Invalid Endpoint",
+			fmt.Sprintf("The endpoint attribute must be one of %s, got %q. Set url to use another service.", endpointNames(), data.Endpoint.ValueString()),
+		)
+	}
+	return diags
+}
+
+// endpointNames returns the accepted endpoint names for use in messages.
+func endpointNames() string {
+	names := make([]string, 0, len(serviceEndpoints))
+	for name := range serviceEndpoints {
+		names = append(names, "`"+name+"`")
+	}
+	sort.Strings(names)
+	return strings.Join(names, ", ")
+}
+
+// normalizeServiceURL returns the service URL raw without trailing slashes,
+// so that API paths can be joined to it. The URL may have a path prefix for
+// instances hosted below one.
+func normalizeServiceURL(raw string) (string, error) {
+	u, err := url.Parse(raw)
+	if err != nil {
+		return "", err
+	}
+	if (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return "", fmt.Errorf("the URL of the service must be an absolute http or https URL, got %q", raw)
+	}
+	if u.RawQuery != "" || u.Fragment != "" {
+		return "", fmt.Errorf("the URL of the service must not have a query or fragment, got %q", raw)
+	}
+	u.Path = strings.TrimRight(u.Path, "/")
+	u.RawPath = ""
+	return u.String(), nil
+}
+
+// checkServiceURL returns the normalized service URL raw set with the
+// attribute at attrPath, refusing plaintext URLs when requireHttps is set.
+func checkServiceURL(attrPath path.Path, raw string, requireHttps bool) (string, diag.Diagnostics) {
+	var diags diag.Diagnostics
+	serviceURL, err := normalizeServiceURL(raw)
+	if err != nil {
+		diags.AddAttributeError(attrPath, "Invalid URL", err.Error())
+		return "", diags
+	}
+	if plaintext, err := isPlaintextURL(serviceURL); err == nil && plaintext {
+		if requireHttps {
+			diags.AddAttributeError(
+				attrPath,
+				"Plaintext Connection",
+				fmt.Sprintf("Secrets sent to %s would cross the network unencrypted. Use an https URL, or set require_https to false to allow it.", serviceURL),
+			)
+			return "", diags
+		}
+		diags.AddAttributeWarning(
+			path.Root("require_https"),
+			"Plaintext Connection",
+			fmt.Sprintf("Secrets sent to %s cross the network unencrypted because require_https is false.", serviceURL),
+		)
+	}
+	return serviceURL, diags
+}
+
+// isPlaintextURL reports whether requests to serviceURL cross the network
+// unencrypted, which connections to the local host do not.
+func isPlaintextURL(serviceURL string) (bool, error) {
+	u, err := url.Parse(serviceURL)
+	if err != nil {
+		return false, err
+	}
+	if u.Scheme != "http" {
+		return false, nil
+	}
+	if host := u.Hostname(); host == "localhost" {
+		return false, nil
+	} else if ip := net.ParseIP(host); ip != nil && ip.IsLoopback() {
+		return false, nil
+	}
+	return true, nil
+}
+
+// selectAPICompatibility returns the API selected by the api_compatibility
+// attribute, or a detection of it when it is set to auto or not set.
+func selectAPICompatibility(value types.String) (client.API, *apiDetection, diag.Diagnostics) {
+	var diags diag.Diagnostics
+	name := stringValueOrDefault(value, autoAPI)
+	if name == autoAPI {
+		return client.API{}, &apiDetection{}, diags
+	}
+	api, ok := apiCompatibilities[name]
+	if !ok {
+		diags.AddAttributeError(
+			path.Root("api_compatibility"),
+			"Invalid API Compatibility",
+			fmt.Sprintf("The api_compatibility attribute must be one of %s, got %q.", apiCompatibilityNames(), name),
+		)
+	}
+	return api, nil, diags
+}
+
+// userAgent returns the User-Agent header of requests made by the given
+// version of the provider, with an optional suffix.
+func userAgent(version, suffix string) string {
+	agent := fmt.Sprintf("terraform-provider-pwpusher/%s (terraform)", version)
+	if suffix != "" {
+		agent += " " + suffix
+	}
+	return agent
+}
+
+// stringValueOrEnv returns value, or the value of the environment variable
+// key when value is null and the variable is set to a non-empty string.
+func stringValueOrEnv(value types.String, key string) types.String {
+	if !value.IsNull() {
+		return value
+	}
+	if env := os.Getenv(key); env != "" {
+		return types.StringValue(env)
+	}
+	return value
+}
+
+// boolValueOrEnv is stringValueOrEnv for boolean attributes, returning false
+// when neither is set.
+func boolValueOrEnv(value types.Bool, key string) (bool, error) {
+	if !value.IsNull() {
+		return value.ValueBool(), nil
+	}
+	env := os.Getenv(key)
+	if env == "" {
+		return false, nil
+	}
+	parsed, err := strconv.ParseBool(env)
+	if err != nil {
+		return false, fmt.Errorf("the %s environment variable must be a boolean, got %q", key, env)
+	}
+	return parsed, nil
+}
+
+// healthCheck checks that the service is reachable and, when the provider is
+// configured with credentials, that it accepts them, so that a misconfigured
+// provider fails before any resource is touched.
+func (d ProviderData) healthCheck(ctx context.Context) diag.Diagnostics {
+	var diags diag.Diagnostics
+
+	// Older versions of the service do not have the version endpoint, any
+	// response still proves it is reachable.
+	_, err := d.serverVersion(ctx)
+	var respErr *client.ResponseError
+	if err != nil && !errors.As(err, &respErr) {
+		diags.AddAttributeError(
+			path.Root("url"),
+			"Unable to Reach pwpusher",
+			fmt.Sprintf("Unable to reach the pwpusher service at %s, got error: %s\n\nSet skip_health_check to configure the provider without reaching the service.", d.url.ValueString(), err),
+		)
+		return diags
+	}
+
+	if d.email == "" {
+		return diags
+	}
+	err = d.apiClient().CheckCredentials(ctx)
+	if respErr, ok := client.RejectedCredentials(err); ok {
+		diags.AddAttributeError(
+			path.Root("api_token"),
+			"Invalid Credentials",
+			fmt.Sprintf("The pwpusher service at %s rejected the credentials of %s: %s", d.url.ValueString(), d.email, respErr.Reason()),
+		)
+	} else if err != nil {
+		diags.AddError("Client Error", fmt.Sprintf("Unable to verify credentials, got error: %s", err))
+	}
+	return diags
+}
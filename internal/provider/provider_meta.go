@@ -0,0 +1,49 @@
+// Copyright (c) Plex, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package provider
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/provider"
+	"github.com/hashicorp/terraform-plugin-framework/provider/metaschema"
+	"github.com/hashicorp/terraform-plugin-framework/tfsdk"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+var _ provider.ProviderWithMetaSchema = &PwPusherProvider{}
+
+// ProviderMetaModel describes the provider_meta block modules set for the
+// provider.
+type ProviderMetaModel struct {
+	Module types.String `tfsdk:"module"`
+}
+
+func (p *PwPusherProvider) MetaSchema(ctx context.Context, req provider.MetaSchemaRequest, resp *provider.MetaSchemaResponse) {
+	resp.Schema = metaschema.Schema{
+		Attributes: map[string]metaschema.Attribute{
+			"module": metaschema.StringAttribute{
+				MarkdownDescription: "The name of the module creating the pushes, such as `acme/onboarding/aws`, recorded in the note of every authenticated push of the module so that it can be traced back",
+				Optional:            true,
+			},
+		},
+	}
+}
+
+// moduleNote returns the note of the pushes created by the module setting
+// meta, empty when it does not set provider_meta.
+func moduleNote(ctx context.Context, meta tfsdk.Config) (string, diag.Diagnostics) {
+	var diags diag.Diagnostics
+	if meta.Raw.IsNull() {
+		return "", diags
+	}
+	var data ProviderMetaModel
+	diags.Append(meta.Get(ctx, &data)...)
+	if data.Module.ValueString() == "" {
+		return "", diags
+	}
+	return fmt.Sprintf("Created by the %s module", data.Module.ValueString()), diags
+}
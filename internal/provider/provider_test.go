@@ -4,10 +4,24 @@
 package provider
 
 import (
+	"fmt"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"terraform-provider-pwpusher/internal/client"
+	"terraform-provider-pwpusher/internal/client/clienttest"
 	"testing"
+	"time"
 
 	"github.com/hashicorp/terraform-plugin-framework/providerserver"
+	"github.com/hashicorp/terraform-plugin-framework/types"
 	"github.com/hashicorp/terraform-plugin-go/tfprotov6"
+	"github.com/hashicorp/terraform-plugin-testing/helper/resource"
+	"github.com/hashicorp/terraform-plugin-testing/terraform"
 )
 
 // testAccProtoV6ProviderFactories are used to instantiate a provider during
@@ -18,8 +32,827 @@ var testAccProtoV6ProviderFactories = map[string]func() (tfprotov6.ProviderServe
 	"pwpusher": providerserver.NewProtocol6WithError(New("test")()),
 }
 
+// testFakeClientProviderFactories returns provider factories whose
+// resources and data sources send their requests to the fake service of
+// server, whatever service they are configured with.
+func testFakeClientProviderFactories(server *clienttest.Server) map[string]func() (tfprotov6.ProviderServer, error) {
+	return map[string]func() (tfprotov6.ProviderServer, error){
+		"pwpusher": providerserver.NewProtocol6WithError(&PwPusherProvider{
+			version: "test",
+			newPushClient: func(httpClient *http.Client, serviceURL, accountID string) client.PushClient {
+				return server.Client(accountID)
+			},
+		}),
+	}
+}
+
+// testFakeClientProviderConfig is the provider configuration of tests using
+// the fake service of server.
+func testFakeClientProviderConfig(server *clienttest.Server) string {
+	return fmt.Sprintf(`
+provider "pwpusher" {
+  url           = %q
+  require_https = false
+}
+`, server.URL)
+}
+
 func testAccPreCheck(t *testing.T) {
 	// You can add code here to run prior to any test case execution, for example assertions
 	// about the appropriate environment variables being set are common to see in a pre-check
 	// function.
 }
+
+func TestStringValueOrEnv(t *testing.T) {
+	t.Setenv("PWPUSH_TEST_VALUE", "from-env")
+
+	if got := stringValueOrEnv(types.StringValue("from-config"), "PWPUSH_TEST_VALUE"); got.ValueString() != "from-config" {
+		t.Errorf("configured value: got %q, want %q", got.ValueString(), "from-config")
+	}
+	if got := stringValueOrEnv(types.StringNull(), "PWPUSH_TEST_VALUE"); got.ValueString() != "from-env" {
+		t.Errorf("unset value: got %q, want %q", got.ValueString(), "from-env")
+	}
+	if got := stringValueOrEnv(types.StringNull(), "PWPUSH_TEST_UNSET"); !got.IsNull() {
+		t.Errorf("unset variable: got %q, want null", got.ValueString())
+	}
+}
+
+func TestBoolValueOrEnv(t *testing.T) {
+	t.Setenv("PWPUSH_TEST_VALUE", "true")
+	t.Setenv("PWPUSH_TEST_INVALID", "yes please")
+
+	if got, err := boolValueOrEnv(types.BoolValue(false), "PWPUSH_TEST_VALUE"); err != nil || got {
+		t.Errorf("configured value: got %t, %v, want false", got, err)
+	}
+	if got, err := boolValueOrEnv(types.BoolNull(), "PWPUSH_TEST_VALUE"); err != nil || !got {
+		t.Errorf("unset value: got %t, %v, want true", got, err)
+	}
+	if got, err := boolValueOrEnv(types.BoolNull(), "PWPUSH_TEST_UNSET"); err != nil || got {
+		t.Errorf("unset variable: got %t, %v, want false", got, err)
+	}
+	if _, err := boolValueOrEnv(types.BoolNull(), "PWPUSH_TEST_INVALID"); err == nil {
+		t.Error("invalid variable: expected an error")
+	}
+}
+
+func TestProviderOAuth2(t *testing.T) {
+	tokenServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.FormValue("grant_type") != "client_credentials" || r.FormValue("scope") != "pwpush" {
+			http.Error(w, "invalid request", http.StatusBadRequest)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprint(w, `{"access_token":"test-token","token_type":"Bearer","expires_in":3600}`)
+	}))
+	defer tokenServer.Close()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer test-token" {
+			http.Error(w, "unauthorized", http.StatusUnauthorized)
+			return
+		}
+		fmt.Fprint(w, `{"application_version":"1.0.0","api_version":"1.0","edition":"oss"}`)
+	}))
+	defer server.Close()
+
+	resource.UnitTest(t, resource.TestCase{
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: fmt.Sprintf(`
+provider "pwpusher" {
+  url = %q
+
+  oauth2 {
+    token_url     = %q
+    client_id     = "client"
+    client_secret = "secret"
+    scopes        = ["pwpush"]
+  }
+}
+
+data "pwpusher_health" "test" {}
+`, server.URL, tokenServer.URL),
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("data.pwpusher_health.test", "healthy", "true"),
+					resource.TestCheckResourceAttr("data.pwpusher_health.test", "application_version", "1.0.0"),
+				),
+			},
+		},
+	})
+}
+
+func TestProviderOAuth2Incomplete(t *testing.T) {
+	resource.UnitTest(t, resource.TestCase{
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: `
+provider "pwpusher" {
+  oauth2 {
+    client_id = "client"
+  }
+}
+
+data "pwpusher_locales" "test" {}
+`,
+				ExpectError: regexp.MustCompile("Missing OAuth2 Configuration"),
+			},
+		},
+	})
+}
+
+func TestProviderHeaders(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("CF-Access-Client-Id") != "client.access" {
+			http.Error(w, "forbidden", http.StatusForbidden)
+			return
+		}
+		if got, want := r.Header.Get("User-Agent"), "terraform-provider-pwpusher/test (terraform) ci/deploy"; got != want {
+			t.Errorf("got User-Agent %q, want %q", got, want)
+		}
+		fmt.Fprint(w, `{"application_version":"1.0.0","api_version":"1.0","edition":"oss"}`)
+	}))
+	defer server.Close()
+
+	resource.UnitTest(t, resource.TestCase{
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: fmt.Sprintf(`
+provider "pwpusher" {
+  url               = %q
+  user_agent_suffix = "ci/deploy"
+
+  headers = {
+    "CF-Access-Client-Id" = "client.access"
+  }
+}
+
+data "pwpusher_health" "test" {}
+`, server.URL),
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("data.pwpusher_health.test", "healthy", "true"),
+				),
+			},
+		},
+	})
+}
+
+func TestProviderProxy(t *testing.T) {
+	proxy := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// Requests sent through a proxy carry the absolute URL of the service.
+		if r.URL.String() != "http://pwpush.invalid/api/v1/version.json" || r.Header.Get("Proxy-Authorization") != basicAuthorization("proxy", "secret") {
+			http.Error(w, "bad gateway", http.StatusBadGateway)
+			return
+		}
+		fmt.Fprint(w, `{"application_version":"1.0.0","api_version":"1.0","edition":"oss"}`)
+	}))
+	defer proxy.Close()
+
+	resource.UnitTest(t, resource.TestCase{
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: fmt.Sprintf(`
+provider "pwpusher" {
+  url            = "http://pwpush.invalid"
+  require_https  = false
+  proxy_url      = %q
+  proxy_username = "proxy"
+  proxy_password = "secret"
+}
+
+data "pwpusher_health" "test" {}
+`, proxy.URL),
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("data.pwpusher_health.test", "healthy", "true"),
+				),
+			},
+		},
+	})
+}
+
+func TestProviderRequestTimeout(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-r.Context().Done():
+		case <-time.After(5 * time.Second):
+		}
+	}))
+	defer server.Close()
+
+	resource.UnitTest(t, resource.TestCase{
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: fmt.Sprintf(`
+provider "pwpusher" {
+  url             = %q
+  request_timeout   = "100ms"
+  skip_health_check = true
+
+  retries {
+    max_attempts = 1
+  }
+}
+
+data "pwpusher_health" "test" {}
+`, server.URL),
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("data.pwpusher_health.test", "healthy", "false"),
+					resource.TestMatchResourceAttr("data.pwpusher_health.test", "message", regexp.MustCompile("deadline exceeded")),
+				),
+			},
+		},
+	})
+}
+
+func TestProviderHealthCheck(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/api/v1/version.json" {
+			fmt.Fprint(w, `{"application_version":"1.0.0","api_version":"1.0","edition":"oss"}`)
+			return
+		}
+		if r.Header.Get("X-User-Token") != "valid" {
+			http.Error(w, "unauthorized", http.StatusUnauthorized)
+			return
+		}
+		fmt.Fprint(w, `[]`)
+	}))
+	defer server.Close()
+
+	config := func(url string, token string) string {
+		return fmt.Sprintf(`
+provider "pwpusher" {
+  url       = %q
+  email     = "user@example.com"
+  api_token = %q
+
+  retries {
+    max_attempts = 1
+  }
+}
+
+data "pwpusher_locales" "test" {}
+`, url, token)
+	}
+
+	resource.UnitTest(t, resource.TestCase{
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config:      config("http://127.0.0.1:1", "valid"),
+				ExpectError: regexp.MustCompile("Unable to Reach pwpusher"),
+			},
+			{
+				Config:      config(server.URL, "invalid"),
+				ExpectError: regexp.MustCompile("Invalid Credentials"),
+			},
+			{
+				Config: config(server.URL, "valid"),
+			},
+		},
+	})
+}
+
+func TestNormalizeServiceURL(t *testing.T) {
+	for raw, want := range map[string]string{
+		"https://pwpush.com":                      "https://pwpush.com",
+		"https://pwpush.com/":                     "https://pwpush.com",
+		"https://intranet.example.com/pwpush//":   "https://intranet.example.com/pwpush",
+		"http://localhost:5100/tools/pwpush":      "http://localhost:5100/tools/pwpush",
+		"pwpush.com":                              "",
+		"ftp://pwpush.com":                        "",
+		"https://pwpush.com/?locale=fr":           "",
+		"https://intranet.example.com/pwpush#top": "",
+	} {
+		got, err := normalizeServiceURL(raw)
+		if want == "" {
+			if err == nil {
+				t.Errorf("%q: expected an error, got %q", raw, got)
+			}
+			continue
+		}
+		if err != nil || got != want {
+			t.Errorf("%q: got %q, %v, want %q", raw, got, err, want)
+		}
+	}
+}
+
+func TestIsPlaintextURL(t *testing.T) {
+	for serviceURL, want := range map[string]bool{
+		"https://pwpush.com":           false,
+		"http://pwpush.com":            true,
+		"http://10.0.0.5:5100":         true,
+		"http://localhost:5100":        false,
+		"http://127.0.0.1:5100":        false,
+		"http://[::1]:5100/pwpush":     false,
+		"http://localhost.example.com": true,
+	} {
+		if got, err := isPlaintextURL(serviceURL); err != nil || got != want {
+			t.Errorf("%q: got %t, %v, want %t", serviceURL, got, err, want)
+		}
+	}
+}
+
+func TestProviderRequireHttps(t *testing.T) {
+	resource.UnitTest(t, resource.TestCase{
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: `
+provider "pwpusher" {
+  url               = "http://pwpush.example.com"
+  skip_health_check = true
+}
+
+data "pwpusher_locales" "test" {}
+`,
+				ExpectError: regexp.MustCompile("Plaintext Connection"),
+			},
+		},
+	})
+}
+
+func TestProviderEndpoint(t *testing.T) {
+	resource.UnitTest(t, resource.TestCase{
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: `
+provider "pwpusher" {
+  endpoint = "pwpush.org"
+}
+
+data "pwpusher_locales" "test" {}
+`,
+				PlanOnly:    true,
+				ExpectError: regexp.MustCompile("Invalid Endpoint"),
+			},
+			{
+				Config: `
+provider "pwpusher" {
+  endpoint = "pwpush.com"
+  url      = "https://pwpush.com"
+}
+
+data "pwpusher_locales" "test" {}
+`,
+				PlanOnly:    true,
+				ExpectError: regexp.MustCompile("Conflicting Configuration"),
+			},
+			{
+				Config: `
+provider "pwpusher" {
+  url = "pwpush.com"
+}
+
+data "pwpusher_locales" "test" {}
+`,
+				PlanOnly:    true,
+				ExpectError: regexp.MustCompile("Invalid URL"),
+			},
+		},
+	})
+}
+
+func TestProviderFake(t *testing.T) {
+	resource.UnitTest(t, resource.TestCase{
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				// The URL does not resolve, so any network access fails.
+				Config: `
+provider "pwpusher" {
+  url       = "https://pwpush.invalid"
+  email     = "user@example.com"
+  api_token = "token"
+  fake      = true
+}
+
+resource "pwpusher_text" "test" {
+  password = "one"
+}
+
+data "pwpusher_push_viewed" "test" {
+  id = pwpusher_text.test.id
+}
+`,
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestMatchResourceAttr("pwpusher_text.test", "id", regexp.MustCompile("^fake-")),
+					resource.TestCheckResourceAttr("data.pwpusher_push_viewed.test", "viewed", "true"),
+				),
+			},
+		},
+	})
+}
+
+func TestProviderDial(t *testing.T) {
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Host != "pwpush.invalid" {
+			http.Error(w, "misdirected request", http.StatusMisdirectedRequest)
+			return
+		}
+		fmt.Fprint(w, `{"application_version":"1.0.0","api_version":"1.0","edition":"oss"}`)
+	})
+
+	socket := filepath.Join(t.TempDir(), "pwpush.sock")
+	listener, err := net.Listen("unix", socket)
+	if err != nil {
+		t.Fatal(err)
+	}
+	unixServer := httptest.NewUnstartedServer(handler)
+	unixServer.Listener = listener
+	unixServer.Start()
+	defer unixServer.Close()
+
+	tcpServer := httptest.NewServer(handler)
+	defer tcpServer.Close()
+
+	for name, setting := range map[string]string{
+		"unix socket":  fmt.Sprintf("unix_socket = %q", socket),
+		"dial address": fmt.Sprintf("dial_address = %q", tcpServer.Listener.Addr().String()),
+	} {
+		t.Run(name, func(t *testing.T) {
+			resource.UnitTest(t, resource.TestCase{
+				ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+				Steps: []resource.TestStep{
+					{
+						Config: fmt.Sprintf(`
+provider "pwpusher" {
+  url           = "http://pwpush.invalid"
+  require_https = false
+  %s
+}
+
+data "pwpusher_health" "test" {}
+`, setting),
+						Check: resource.TestCheckResourceAttr("data.pwpusher_health.test", "healthy", "true"),
+					},
+				},
+			})
+		})
+	}
+}
+
+func TestProviderAcceptLanguage(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/api/v1/version.json" {
+			fmt.Fprint(w, `{"application_version":"1.0.0","api_version":"1.0","edition":"oss"}`)
+			return
+		}
+		message := "Invalid token"
+		if r.Header.Get("Accept-Language") == "de" {
+			message = "Ungültiges Token"
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusUnauthorized)
+		fmt.Fprintf(w, `{"error":%q}`, message)
+	}))
+	defer server.Close()
+
+	resource.UnitTest(t, resource.TestCase{
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: fmt.Sprintf(`
+provider "pwpusher" {
+  url             = %q
+  email           = "user@example.com"
+  api_token       = "invalid"
+  accept_language = "de"
+}
+
+data "pwpusher_locales" "test" {}
+`, server.URL),
+				ExpectError: regexp.MustCompile("401 Unauthorized: Ungültiges Token"),
+			},
+		},
+	})
+}
+
+func TestProviderStrictDecoding(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprint(w, `{"application_version":"2.0.0","api_version":"1.0","edition":"oss","build":"abc"}`)
+	}))
+	defer server.Close()
+
+	config := func(strict bool) string {
+		return fmt.Sprintf(`
+provider "pwpusher" {
+  url             = %q
+  strict_decoding = %t
+}
+
+data "pwpusher_health" "test" {}
+`, server.URL, strict)
+	}
+	resource.UnitTest(t, resource.TestCase{
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config:      config(true),
+				ExpectError: regexp.MustCompile(`json: unknown field "build"`),
+			},
+			{
+				Config: config(false),
+				Check:  resource.TestCheckResourceAttr("data.pwpusher_health.test", "application_version", "2.0.0"),
+			},
+		},
+	})
+}
+
+func TestProviderMaxResponseBytes(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/html")
+		fmt.Fprint(w, "<html>"+strings.Repeat("<p>Welcome</p>", 100)+"</html>")
+	}))
+	defer server.Close()
+
+	config := func(limit int) string {
+		return fmt.Sprintf(`
+provider "pwpusher" {
+  url                = %q
+  max_response_bytes = %d
+}
+
+data "pwpusher_health" "test" {}
+`, server.URL, limit)
+	}
+	resource.UnitTest(t, resource.TestCase{
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config:      config(0),
+				ExpectError: regexp.MustCompile("must be at least 1"),
+			},
+			{
+				Config:      config(100),
+				ExpectError: regexp.MustCompile(`than the limit of 100 bytes`),
+			},
+		},
+	})
+}
+
+func TestProviderCompressRequests(t *testing.T) {
+	var encoding string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		if r.Method != http.MethodPost {
+			fmt.Fprint(w, `{"application_version":"1.0.0","api_version":"1.0","edition":"oss"}`)
+			return
+		}
+		encoding = r.Header.Get("Content-Encoding")
+		w.WriteHeader(http.StatusCreated)
+		fmt.Fprint(w, `{"url_token":"abc"}`)
+	}))
+	defer server.Close()
+
+	resource.UnitTest(t, resource.TestCase{
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: fmt.Sprintf(`
+provider "pwpusher" {
+  url               = %q
+  compress_requests = true
+}
+
+resource "pwpusher_text" "test" {
+  password = %q
+}
+`, server.URL, strings.Repeat("secret ", client.CompressMinBytes)),
+				Check: func(*terraform.State) error {
+					if encoding != "gzip" {
+						return fmt.Errorf("got a push with content encoding %q, want gzip", encoding)
+					}
+					return nil
+				},
+			},
+		},
+	})
+}
+
+func TestProviderConnectionSettings(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"application_version":"1.0.0","api_version":"1.0","edition":"oss"}`)
+	}))
+	defer server.Close()
+
+	config := func(settings string) string {
+		return fmt.Sprintf(`
+provider "pwpusher" {
+  url = %q
+  %s
+}
+
+data "pwpusher_health" "test" {}
+`, server.URL, settings)
+	}
+	resource.UnitTest(t, resource.TestCase{
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config:      config("max_idle_conns_per_host = 0"),
+				ExpectError: regexp.MustCompile("must be at least 1"),
+			},
+			{
+				Config:      config(`idle_conn_timeout = "soon"`),
+				ExpectError: regexp.MustCompile("Invalid Duration"),
+			},
+			{
+				Config: config(`
+  disable_http2           = true
+  max_idle_conns_per_host = 50
+  idle_conn_timeout       = "5m"
+`),
+				Check: resource.TestCheckResourceAttr("data.pwpusher_health.test", "healthy", "true"),
+			},
+		},
+	})
+}
+
+func TestProviderCircuitBreaker(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer server.Close()
+
+	resource.UnitTest(t, resource.TestCase{
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				// The failed health check opens the circuit.
+				Config: fmt.Sprintf(`
+provider "pwpusher" {
+  url                       = %q
+  circuit_breaker_threshold = 1
+
+  retries {
+    max_attempts = 1
+  }
+}
+
+data "pwpusher_stats" "test" {}
+`, server.URL),
+				ExpectError: regexp.MustCompile(`after 1 consecutive failures`),
+			},
+			{
+				Config: fmt.Sprintf(`
+provider "pwpusher" {
+  url                       = %q
+  circuit_breaker_threshold = -1
+}
+
+data "pwpusher_stats" "test" {}
+`, server.URL),
+				ExpectError: regexp.MustCompile("must be at least 0"),
+			},
+		},
+	})
+}
+
+func TestProviderReadCacheTTL(t *testing.T) {
+	resource.UnitTest(t, resource.TestCase{
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: `
+provider "pwpusher" {
+  url            = "https://pwpush.example.com"
+  read_cache_ttl = "soon"
+}
+
+data "pwpusher_stats" "test" {}
+`,
+				ExpectError: regexp.MustCompile("Invalid Duration"),
+			},
+		},
+	})
+}
+
+func TestProviderMetrics(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"application_version":"1.50.0","api_version":"1.0","edition":"oss"}`))
+	}))
+	defer server.Close()
+
+	statsd, err := net.ListenPacket("udp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer statsd.Close()
+
+	resource.UnitTest(t, resource.TestCase{
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: fmt.Sprintf(`
+provider "pwpusher" {
+  url                    = %q
+  metrics_listen_address = "256.0.0.1:9464"
+}
+
+data "pwpusher_health" "test" {}
+`, server.URL),
+				ExpectError: regexp.MustCompile("Invalid Metrics Address"),
+			},
+			{
+				Config: fmt.Sprintf(`
+provider "pwpusher" {
+  url                    = %q
+  metrics_statsd_address = %q
+}
+
+data "pwpusher_health" "test" {}
+`, server.URL, statsd.LocalAddr().String()),
+				Check: func(*terraform.State) error {
+					buf := make([]byte, 1024)
+					if err := statsd.SetReadDeadline(time.Now().Add(5 * time.Second)); err != nil {
+						return err
+					}
+					n, _, err := statsd.ReadFrom(buf)
+					if err != nil {
+						return err
+					}
+					if got := string(buf[:n]); !strings.HasPrefix(got, "pwpusher.requests.Version:1|c\n") {
+						return fmt.Errorf("got datagram %q", got)
+					}
+					return nil
+				},
+			},
+		},
+	})
+}
+
+func TestProviderDebugHTTP(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"application_version":"1.50.0","api_version":"1.0","edition":"oss"}`))
+	}))
+	defer server.Close()
+
+	resource.UnitTest(t, resource.TestCase{
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: fmt.Sprintf(`
+provider "pwpusher" {
+  url        = %q
+  debug_http = true
+}
+
+data "pwpusher_health" "test" {}
+`, server.URL),
+				Check: resource.TestCheckResourceAttr("data.pwpusher_health.test", "application_version", "1.50.0"),
+			},
+		},
+	})
+}
+
+func TestProviderHARPath(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		if r.URL.Path == "/p/active.json" {
+			_, _ = w.Write([]byte(`[]`))
+			return
+		}
+		_, _ = w.Write([]byte(`{"application_version":"1.50.0","api_version":"1.0","edition":"oss"}`))
+	}))
+	defer server.Close()
+	harPath := filepath.Join(t.TempDir(), "capture.har")
+
+	resource.UnitTest(t, resource.TestCase{
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: fmt.Sprintf(`
+provider "pwpusher" {
+  url       = %q
+  email     = "user@example.com"
+  api_token = "api-token"
+  har_path  = %q
+}
+
+data "pwpusher_health" "test" {}
+`, server.URL, harPath),
+				Check: func(*terraform.State) error {
+					data, err := os.ReadFile(harPath)
+					if err != nil {
+						return err
+					}
+					if strings.Contains(string(data), "api-token") {
+						return fmt.Errorf("got the API token in the capture: %s", data)
+					}
+					if !strings.Contains(string(data), "/api/v1/version.json") {
+						return fmt.Errorf("got no version request in the capture: %s", data)
+					}
+					return nil
+				},
+			},
+		},
+	})
+}
@@ -0,0 +1,193 @@
+// Copyright (c) Plex, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package provider
+
+import (
+	"context"
+	"fmt"
+	"terraform-provider-pwpusher/internal/client"
+
+	"github.com/hashicorp/terraform-plugin-framework-timeouts/datasource/timeouts"
+	"github.com/hashicorp/terraform-plugin-framework/datasource"
+	"github.com/hashicorp/terraform-plugin-framework/datasource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+// Ensure provider defined types fully satisfy framework interfaces.
+var _ datasource.DataSource = &PushCheckDataSource{}
+
+func NewPushCheckDataSource() datasource.DataSource {
+	return &PushCheckDataSource{}
+}
+
+// PushCheckDataSource defines the data source implementation.
+type PushCheckDataSource struct {
+	providerData ProviderData
+}
+
+// PushCheckDataSourceModel describes the data source data model.
+type PushCheckDataSourceModel struct {
+	Id                types.String   `tfsdk:"id"`
+	MinViewsRemaining types.Int32    `tfsdk:"min_views_remaining"`
+	MinDaysRemaining  types.Int32    `tfsdk:"min_days_remaining"`
+	MustNotBeExpired  types.Bool     `tfsdk:"must_not_be_expired"`
+	Expired           types.Bool     `tfsdk:"expired"`
+	DaysRemaining     types.Int32    `tfsdk:"days_remaining"`
+	ViewsRemaining    types.Int32    `tfsdk:"views_remaining"`
+	Timeouts          timeouts.Value `tfsdk:"timeouts"`
+}
+
+func (d *PushCheckDataSource) Metadata(ctx context.Context, req datasource.MetadataRequest, resp *datasource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_push_check"
+}
+
+func (d *PushCheckDataSource) Schema(ctx context.Context, req datasource.SchemaRequest, resp *datasource.SchemaResponse) {
+	resp.Schema = schema.Schema{
+		// This description is used by the documentation generator and the language server.
+		MarkdownDescription: "Asserts that a push of the authenticated account is still usable. Reading this data source fails with a descriptive error when any of the configured assertions is violated. The push is looked up on the active and expired dashboards, so checking it does not consume a view, and reading it fails when it is on neither",
+
+		Attributes: map[string]schema.Attribute{
+			"id": schema.StringAttribute{
+				MarkdownDescription: "Identifier of the secret in the pwpusher app",
+				Required:            true,
+			},
+			"min_views_remaining": schema.Int32Attribute{
+				MarkdownDescription: "Fail when the secret can be viewed fewer than this many more times",
+				Optional:            true,
+			},
+			"min_days_remaining": schema.Int32Attribute{
+				MarkdownDescription: "Fail when the secret expires in fewer than this many days",
+				Optional:            true,
+			},
+			"must_not_be_expired": schema.BoolAttribute{
+				MarkdownDescription: "Fail when the secret has expired",
+				Optional:            true,
+			},
+			"expired": schema.BoolAttribute{
+				MarkdownDescription: "If the secret has expired",
+				Computed:            true,
+			},
+			"days_remaining": schema.Int32Attribute{
+				MarkdownDescription: "The number of days left that the secret can be viewed",
+				Computed:            true,
+			},
+			"views_remaining": schema.Int32Attribute{
+				MarkdownDescription: "The number of times that the secret can be viewed",
+				Computed:            true,
+			},
+		},
+
+		Blocks: map[string]schema.Block{
+			"timeouts": timeouts.Block(ctx),
+		},
+	}
+}
+
+func (d *PushCheckDataSource) Configure(ctx context.Context, req datasource.ConfigureRequest, resp *datasource.ConfigureResponse) {
+	// Prevent panic if the provider has not been configured.
+	if req.ProviderData == nil {
+		return
+	}
+
+	providerData, ok := req.ProviderData.(ProviderData)
+
+	if !ok {
+		resp.Diagnostics.AddError(
+			"Unexpected Data Source Configure Type",
+			fmt.Sprintf("Expected ProviderData, got: %T. Please report this issue to the provider developers.", req.ProviderData),
+		)
+
+		return
+	}
+
+	d.providerData = providerData
+}
+
+func (d *PushCheckDataSource) Read(ctx context.Context, req datasource.ReadRequest, resp *datasource.ReadResponse) {
+	var data PushCheckDataSourceModel
+
+	// Read Terraform configuration data into the model
+	resp.Diagnostics.Append(req.Config.Get(ctx, &data)...)
+
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	readTimeout, diags := data.Timeouts.Read(ctx, defaultDataSourceReadTimeout)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	ctx, cancel := context.WithTimeout(ctx, readTimeout)
+	defer cancel()
+
+	ctx, op := d.providerData.operationContext(ctx, logSubsystemDataSources, "pwpusher_push_check read")
+	defer op.end(&resp.Diagnostics)
+
+	// Pushes move from the active to the expired dashboard once they
+	// expire.
+	var push client.Push
+	found := false
+	for _, dashboard := range []string{"active", "expired"} {
+		pushes, err := d.providerData.apiClient().ListPushes(ctx, dashboard)
+		if err != nil {
+			resp.Diagnostics.AddError("Client Error", fmt.Sprintf("Unable to list %s pushes, got error: %s", dashboard, err))
+			return
+		}
+		if push, found = findPush(pushes, data.Id.ValueString()); found {
+			break
+		}
+	}
+	if !found {
+		resp.Diagnostics.AddAttributeError(
+			path.Root("id"),
+			"Push Not Found",
+			fmt.Sprintf("The push %s was not found on the dashboard of the authenticated account", data.Id.ValueString()),
+		)
+		return
+	}
+
+	data.Expired = types.BoolValue(push.Expired)
+	data.DaysRemaining = types.Int32Value(int32(push.DaysRemaining))
+	data.ViewsRemaining = types.Int32Value(int32(push.ViewsRemaining))
+
+	if data.MustNotBeExpired.ValueBool() && push.Expired {
+		resp.Diagnostics.AddAttributeError(
+			path.Root("must_not_be_expired"),
+			"Push Check Failed",
+			fmt.Sprintf("The push %s has expired", push.ID),
+		)
+	}
+	if !data.MinDaysRemaining.IsNull() && int32(push.DaysRemaining) < data.MinDaysRemaining.ValueInt32() {
+		resp.Diagnostics.AddAttributeError(
+			path.Root("min_days_remaining"),
+			"Push Check Failed",
+			fmt.Sprintf("The push %s has %d days remaining, expected at least %d", push.ID, push.DaysRemaining, data.MinDaysRemaining.ValueInt32()),
+		)
+	}
+	if !data.MinViewsRemaining.IsNull() && int32(push.ViewsRemaining) < data.MinViewsRemaining.ValueInt32() {
+		resp.Diagnostics.AddAttributeError(
+			path.Root("min_views_remaining"),
+			"Push Check Failed",
+			fmt.Sprintf("The push %s has %d views remaining, expected at least %d", push.ID, push.ViewsRemaining, data.MinViewsRemaining.ValueInt32()),
+		)
+	}
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	// Save data into Terraform state
+	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+}
+
+// findPush returns the push of pushes whose token is id.
+func findPush(pushes []client.Push, id string) (client.Push, bool) {
+	for _, push := range pushes {
+		if push.ID == id {
+			return push, true
+		}
+	}
+	return client.Push{}, false
+}
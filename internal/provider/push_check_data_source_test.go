@@ -0,0 +1,93 @@
+// Copyright (c) Plex, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package provider
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"terraform-provider-pwpusher/internal/client/clienttest"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-testing/helper/resource"
+)
+
+func TestAccPushCheckDataSource(t *testing.T) {
+	resource.Test(t, resource.TestCase{
+		PreCheck:                 func() { testAccPreCheck(t) },
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			// Read testing
+			{
+				Config: testAccPushCheckDataSourceConfig(1),
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("data.pwpusher_push_check.test", "expired", "false"),
+					resource.TestCheckResourceAttrSet("data.pwpusher_push_check.test", "views_remaining"),
+				),
+			},
+			// Violated assertion
+			{
+				Config:      testAccPushCheckDataSourceConfig(100),
+				ExpectError: regexp.MustCompile("expected at least 100"),
+			},
+		},
+	})
+}
+
+func TestPushCheckDataSourceFakeClient(t *testing.T) {
+	server := clienttest.NewServer()
+	defer server.Close()
+
+	resource.UnitTest(t, resource.TestCase{
+		ProtoV6ProviderFactories: testFakeClientProviderFactories(server),
+		Steps: []resource.TestStep{
+			{
+				Config: testFakeClientProviderConfig(server) + testAccPushCheckDataSourceConfig(1),
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("data.pwpusher_push_check.test", "expired", "false"),
+					resource.TestCheckResourceAttr("data.pwpusher_push_check.test", "views_remaining", fmt.Sprint(clienttest.DefaultExpireAfterViews)),
+					resource.TestCheckResourceAttr("data.pwpusher_push_check.test", "days_remaining", fmt.Sprint(clienttest.DefaultExpireAfterDays)),
+				),
+			},
+			{
+				PreConfig: func() {
+					server.View("token1")
+				},
+				Config:      testFakeClientProviderConfig(server) + testAccPushCheckDataSourceConfig(clienttest.DefaultExpireAfterViews),
+				ExpectError: regexp.MustCompile("has 4 views remaining, expected at least 5"),
+			},
+			{
+				PreConfig: func() {
+					if err := server.Client("").ExpirePush(context.Background(), "token1"); err != nil {
+						t.Fatal(err)
+					}
+				},
+				Config:      testFakeClientProviderConfig(server) + testAccPushCheckDataSourceConfig(1),
+				ExpectError: regexp.MustCompile("The push token1 has expired"),
+			},
+			{
+				Config: testFakeClientProviderConfig(server) + `
+data "pwpusher_push_check" "missing" {
+  id = "missing"
+}
+`,
+				ExpectError: regexp.MustCompile(`The push missing was not found on the dashboard of the authenticated\s+account`),
+			},
+		},
+	})
+}
+
+func testAccPushCheckDataSourceConfig(minViews int) string {
+	return fmt.Sprintf(`
+resource "pwpusher_text" "test" {
+  password = "one"
+}
+
+data "pwpusher_push_check" "test" {
+  id                  = pwpusher_text.test.id
+  must_not_be_expired = true
+  min_views_remaining = %[1]d
+}
+`, minViews)
+}
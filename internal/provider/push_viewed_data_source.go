@@ -0,0 +1,230 @@
+// Copyright (c) Plex, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package provider
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"terraform-provider-pwpusher/internal/client"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-framework-timeouts/datasource/timeouts"
+	"github.com/hashicorp/terraform-plugin-framework/datasource"
+	"github.com/hashicorp/terraform-plugin-framework/datasource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+)
+
+// Ensure provider defined types fully satisfy framework interfaces.
+var _ datasource.DataSource = &PushViewedDataSource{}
+
+const (
+	defaultViewedTimeout      = "10m"
+	defaultViewedPollInterval = "30s"
+)
+
+// minViewedPollInterval is the shortest poll_interval, which keeps waits
+// from polling the audit log in a tight loop.
+const minViewedPollInterval = time.Second
+
+func NewPushViewedDataSource() datasource.DataSource {
+	return &PushViewedDataSource{}
+}
+
+// PushViewedDataSource defines the data source implementation.
+type PushViewedDataSource struct {
+	providerData ProviderData
+}
+
+// PushViewedDataSourceModel describes the data source data model.
+type PushViewedDataSourceModel struct {
+	Id            types.String   `tfsdk:"id"`
+	Timeout       types.String   `tfsdk:"timeout"`
+	PollInterval  types.String   `tfsdk:"poll_interval"`
+	Viewed        types.Bool     `tfsdk:"viewed"`
+	ViewCount     types.Int32    `tfsdk:"view_count"`
+	FirstViewedAt types.String   `tfsdk:"first_viewed_at"`
+	Timeouts      timeouts.Value `tfsdk:"timeouts"`
+}
+
+func (d *PushViewedDataSource) Metadata(ctx context.Context, req datasource.MetadataRequest, resp *datasource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_push_viewed"
+}
+
+func (d *PushViewedDataSource) Schema(ctx context.Context, req datasource.SchemaRequest, resp *datasource.SchemaResponse) {
+	resp.Schema = schema.Schema{
+		// This description is used by the documentation generator and the language server.
+		MarkdownDescription: "Waits until a push has been viewed at least once. Reading this data source blocks until the audit log of the push records a successful view, or fails once the timeout is reached",
+
+		Attributes: map[string]schema.Attribute{
+			"id": schema.StringAttribute{
+				MarkdownDescription: "Identifier of the secret in the pwpusher app",
+				Required:            true,
+			},
+			"timeout": schema.StringAttribute{
+				MarkdownDescription: "How long to wait for the first view, as a duration string such as `30m`. Defaults to `" + defaultViewedTimeout + "`",
+				Optional:            true,
+			},
+			"poll_interval": schema.StringAttribute{
+				MarkdownDescription: "How often to check the audit log, as a duration string such as `10s`, at least `" + minViewedPollInterval.String() + "`. Defaults to `" + defaultViewedPollInterval + "`",
+				Optional:            true,
+			},
+			"viewed": schema.BoolAttribute{
+				MarkdownDescription: "If the secret has been viewed",
+				Computed:            true,
+			},
+			"view_count": schema.Int32Attribute{
+				MarkdownDescription: "The number of successful views of the secret",
+				Computed:            true,
+			},
+			"first_viewed_at": schema.StringAttribute{
+				MarkdownDescription: "The timestamp of the first successful view of the secret",
+				Computed:            true,
+			},
+		},
+
+		Blocks: map[string]schema.Block{
+			"timeouts": timeouts.Block(ctx),
+		},
+	}
+}
+
+func (d *PushViewedDataSource) Configure(ctx context.Context, req datasource.ConfigureRequest, resp *datasource.ConfigureResponse) {
+	// Prevent panic if the provider has not been configured.
+	if req.ProviderData == nil {
+		return
+	}
+
+	providerData, ok := req.ProviderData.(ProviderData)
+
+	if !ok {
+		resp.Diagnostics.AddError(
+			"Unexpected Data Source Configure Type",
+			fmt.Sprintf("Expected ProviderData, got: %T. Please report this issue to the provider developers.", req.ProviderData),
+		)
+
+		return
+	}
+
+	d.providerData = providerData
+}
+
+func (d *PushViewedDataSource) Read(ctx context.Context, req datasource.ReadRequest, resp *datasource.ReadResponse) {
+	var data PushViewedDataSourceModel
+
+	// Read Terraform configuration data into the model
+	resp.Diagnostics.Append(req.Config.Get(ctx, &data)...)
+
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	timeout, err := time.ParseDuration(stringValueOrDefault(data.Timeout, defaultViewedTimeout))
+	if err != nil {
+		resp.Diagnostics.AddAttributeError(path.Root("timeout"), "Invalid Duration", err.Error())
+	} else if timeout <= 0 {
+		resp.Diagnostics.AddAttributeError(path.Root("timeout"), "Invalid Duration", "The timeout attribute must be a positive duration.")
+	}
+	interval, err := time.ParseDuration(stringValueOrDefault(data.PollInterval, defaultViewedPollInterval))
+	if err != nil {
+		resp.Diagnostics.AddAttributeError(path.Root("poll_interval"), "Invalid Duration", err.Error())
+	} else if interval < minViewedPollInterval {
+		resp.Diagnostics.AddAttributeError(path.Root("poll_interval"), "Invalid Duration", fmt.Sprintf("The poll_interval attribute must be at least %s.", minViewedPollInterval))
+	}
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	// The read timeout bounds the whole wait, so by default leave room for
+	// the last poll on top of the wait timeout.
+	readTimeout, diags := data.Timeouts.Read(ctx, timeout+defaultDataSourceReadTimeout)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	ctx, cancel := context.WithTimeout(ctx, readTimeout)
+	defer cancel()
+
+	ctx, op := d.providerData.operationContext(ctx, logSubsystemDataSources, "pwpusher_push_viewed read")
+	defer op.end(&resp.Diagnostics)
+
+	waitCtx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	for {
+		auditLog, err := d.providerData.apiClient().Audit(waitCtx, data.Id.ValueString())
+		if errors.Is(err, client.ErrNotFound) {
+			resp.Diagnostics.AddAttributeError(
+				path.Root("id"),
+				"Push Not Found",
+				fmt.Sprintf("The pwpusher service has no push %s to wait for", data.Id.ValueString()),
+			)
+			return
+		}
+		if err != nil && waitCtx.Err() == nil {
+			resp.Diagnostics.AddError("Client Error", fmt.Sprintf("Unable to read audit log, got error: %s", err))
+			return
+		}
+
+		count, firstViewedAt := successfulViews(auditLog)
+		if count > 0 {
+			data.Viewed = types.BoolValue(true)
+			data.ViewCount = types.Int32Value(count)
+			data.FirstViewedAt = types.StringValue(firstViewedAt)
+			break
+		}
+
+		tflog.SubsystemDebug(ctx, logSubsystemDataSources, "push has not been viewed yet", map[string]interface{}{
+			"token":         data.Id.ValueString(),
+			"poll_interval": interval.String(),
+		})
+
+		select {
+		case <-waitCtx.Done():
+			// Terraform cancels the context of the read when it is
+			// interrupted, which is not a push left unviewed.
+			if ctx.Err() != nil {
+				resp.Diagnostics.AddError("Read Cancelled", fmt.Sprintf("Stopped waiting for the push %s to be viewed: %s", data.Id.ValueString(), ctx.Err()))
+				return
+			}
+			resp.Diagnostics.AddError(
+				"Push Not Viewed",
+				fmt.Sprintf("The push %s did not record a view within %s", data.Id.ValueString(), timeout),
+			)
+			return
+		case <-time.After(interval):
+		}
+	}
+
+	// Save data into Terraform state
+	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+}
+
+// successfulViews returns the number of successful retrievals recorded in
+// the audit log along with the timestamp of the earliest one.
+func successfulViews(auditLog client.AuditLog) (int32, string) {
+	var count int32
+	var first string
+	for _, view := range auditLog.Views {
+		if view.Kind != client.AuditViewKindView || !view.Successful {
+			continue
+		}
+		count++
+		if first == "" || view.CreatedAt < first {
+			first = view.CreatedAt
+		}
+	}
+	return count, first
+}
+
+// stringValueOrDefault returns the value of s, or def when s is null or
+// unknown.
+func stringValueOrDefault(s types.String, def string) string {
+	if s.IsNull() || s.IsUnknown() {
+		return def
+	}
+	return s.ValueString()
+}
@@ -0,0 +1,92 @@
+// Copyright (c) Plex, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package provider
+
+import (
+	"regexp"
+	"terraform-provider-pwpusher/internal/client/clienttest"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-testing/helper/resource"
+)
+
+func TestAccPushViewedDataSource(t *testing.T) {
+	resource.Test(t, resource.TestCase{
+		PreCheck:                 func() { testAccPreCheck(t) },
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			// A freshly created push has not been viewed, so the wait times out
+			{
+				Config:      testAccPushViewedDataSourceConfig,
+				ExpectError: regexp.MustCompile("did not record a view"),
+			},
+		},
+	})
+}
+
+func TestPushViewedDataSourceFakeClient(t *testing.T) {
+	server := clienttest.NewServer()
+	defer server.Close()
+
+	resource.UnitTest(t, resource.TestCase{
+		ProtoV6ProviderFactories: testFakeClientProviderFactories(server),
+		Steps: []resource.TestStep{
+			{
+				Config: testFakeClientProviderConfig(server) + `
+data "pwpusher_push_viewed" "test" {
+  id = "missing"
+}
+`,
+				ExpectError: regexp.MustCompile("has no push missing to wait for"),
+			},
+			{
+				Config: testFakeClientProviderConfig(server) + `
+data "pwpusher_push_viewed" "test" {
+  id            = "missing"
+  poll_interval = "0s"
+}
+`,
+				ExpectError: regexp.MustCompile(`poll_interval attribute must be at least 1s`),
+			},
+			{
+				Config: testFakeClientProviderConfig(server) + `
+data "pwpusher_push_viewed" "test" {
+  id      = "missing"
+  timeout = "-1m"
+}
+`,
+				ExpectError: regexp.MustCompile(`timeout attribute must be a positive duration`),
+			},
+			{
+				Config:      testFakeClientProviderConfig(server) + testAccPushViewedDataSourceConfig,
+				ExpectError: regexp.MustCompile("did not record a view"),
+			},
+			{
+				PreConfig: func() {
+					// The failed step left the push behind in the state.
+					server.View("token1")
+					server.View("token1")
+				},
+				Config: testFakeClientProviderConfig(server) + testAccPushViewedDataSourceConfig,
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("data.pwpusher_push_viewed.test", "viewed", "true"),
+					resource.TestCheckResourceAttr("data.pwpusher_push_viewed.test", "view_count", "2"),
+					resource.TestCheckResourceAttrSet("data.pwpusher_push_viewed.test", "first_viewed_at"),
+				),
+			},
+		},
+	})
+}
+
+const testAccPushViewedDataSourceConfig = `
+resource "pwpusher_text" "test" {
+  password = "one"
+}
+
+data "pwpusher_push_viewed" "test" {
+  id            = pwpusher_text.test.id
+  timeout       = "5s"
+  poll_interval = "1s"
+}
+`
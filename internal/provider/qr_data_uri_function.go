@@ -0,0 +1,79 @@
+// Copyright (c) Plex, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package provider
+
+import (
+	"context"
+	"encoding/base64"
+	"fmt"
+
+	"github.com/hashicorp/terraform-plugin-framework/function"
+	qrcode "github.com/skip2/go-qrcode"
+)
+
+// Ensure provider defined types fully satisfy framework interfaces.
+var _ function.Function = &QrDataUriFunction{}
+
+const (
+	defaultQrSize = 256
+	maxQrSize     = 4096
+)
+
+func NewQrDataUriFunction() function.Function {
+	return &QrDataUriFunction{}
+}
+
+// QrDataUriFunction defines the function implementation.
+type QrDataUriFunction struct{}
+
+func (f *QrDataUriFunction) Metadata(ctx context.Context, req function.MetadataRequest, resp *function.MetadataResponse) {
+	resp.Name = "qr_data_uri"
+}
+
+func (f *QrDataUriFunction) Definition(ctx context.Context, req function.DefinitionRequest, resp *function.DefinitionResponse) {
+	resp.Definition = function.Definition{
+		Summary:             "Render a string as a QR code data URI",
+		MarkdownDescription: "Renders a string, typically a push URL, as a QR code PNG image and returns it as a `data:image/png;base64,...` URI for embedding in HTML or markdown. The code uses the medium error recovery level",
+		Parameters: []function.Parameter{
+			function.StringParameter{
+				Name:                "content",
+				MarkdownDescription: "The string to encode",
+			},
+			function.Int64Parameter{
+				Name:                "size",
+				MarkdownDescription: fmt.Sprintf("The width and height of the image in pixels, or null for %d", defaultQrSize),
+				AllowNullValue:      true,
+			},
+		},
+		Return: function.StringReturn{},
+	}
+}
+
+func (f *QrDataUriFunction) Run(ctx context.Context, req function.RunRequest, resp *function.RunResponse) {
+	var content string
+	var size *int64
+
+	resp.Error = function.ConcatFuncErrors(req.Arguments.Get(ctx, &content, &size))
+
+	if resp.Error != nil {
+		return
+	}
+
+	pixels := int64(defaultQrSize)
+	if size != nil {
+		pixels = *size
+	}
+	if pixels < 1 || pixels > maxQrSize {
+		resp.Error = function.NewArgumentFuncError(1, fmt.Sprintf("size must be between 1 and %d", maxQrSize))
+		return
+	}
+
+	png, err := qrcode.Encode(content, qrcode.Medium, int(pixels))
+	if err != nil {
+		resp.Error = function.NewArgumentFuncError(0, fmt.Sprintf("unable to encode content as a QR code: %s", err))
+		return
+	}
+
+	resp.Error = function.ConcatFuncErrors(resp.Result.Set(ctx, "data:image/png;base64,"+base64.StdEncoding.EncodeToString(png)))
+}
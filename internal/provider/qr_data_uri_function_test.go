@@ -0,0 +1,44 @@
+// Copyright (c) Plex, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package provider
+
+import (
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-testing/helper/resource"
+	"github.com/hashicorp/terraform-plugin-testing/knownvalue"
+	"github.com/hashicorp/terraform-plugin-testing/statecheck"
+	"github.com/hashicorp/terraform-plugin-testing/tfversion"
+)
+
+func TestQrDataUriFunction(t *testing.T) {
+	resource.UnitTest(t, resource.TestCase{
+		TerraformVersionChecks: []tfversion.TerraformVersionCheck{
+			tfversion.SkipBelow(tfversion.Version1_8_0),
+		},
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: `
+locals {
+  uri = provider::pwpusher::qr_data_uri("https://pwpush.com/p/abc123", 128)
+}
+
+output "test" {
+  value = {
+    prefix = startswith(local.uri, "data:image/png;base64,iVBORw0KGgo")
+    stable = local.uri == provider::pwpusher::qr_data_uri("https://pwpush.com/p/abc123", 128)
+  }
+}
+`,
+				ConfigStateChecks: []statecheck.StateCheck{
+					statecheck.ExpectKnownOutputValue("test", knownvalue.ObjectExact(map[string]knownvalue.Check{
+						"prefix": knownvalue.Bool(true),
+						"stable": knownvalue.Bool(true),
+					})),
+				},
+			},
+		},
+	})
+}
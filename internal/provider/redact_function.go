@@ -0,0 +1,75 @@
+// Copyright (c) Plex, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package provider
+
+import (
+	"context"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-framework/function"
+)
+
+// Ensure provider defined types fully satisfy framework interfaces.
+var _ function.Function = &RedactFunction{}
+
+// redactMask replaces every hidden character of a redacted secret.
+const redactMask = "*"
+
+func NewRedactFunction() function.Function {
+	return &RedactFunction{}
+}
+
+// RedactFunction defines the function implementation.
+type RedactFunction struct{}
+
+func (f *RedactFunction) Metadata(ctx context.Context, req function.MetadataRequest, resp *function.MetadataResponse) {
+	resp.Name = "redact"
+}
+
+func (f *RedactFunction) Definition(ctx context.Context, req function.DefinitionRequest, resp *function.DefinitionResponse) {
+	resp.Definition = function.Definition{
+		Summary:             "Mask all but the first and last characters of a secret",
+		MarkdownDescription: "Replaces every character of a secret with `" + redactMask + "` except for the first and last `keep` characters. Secrets shorter than four times `keep` are masked entirely, so the hint never reveals more than half of the secret",
+		Parameters: []function.Parameter{
+			function.StringParameter{
+				Name:                "secret",
+				MarkdownDescription: "The secret to redact",
+			},
+			function.Int64Parameter{
+				Name:                "keep",
+				MarkdownDescription: "The number of characters to keep on each end",
+			},
+		},
+		Return: function.StringReturn{},
+	}
+}
+
+func (f *RedactFunction) Run(ctx context.Context, req function.RunRequest, resp *function.RunResponse) {
+	var secret string
+	var keep int64
+
+	resp.Error = function.ConcatFuncErrors(req.Arguments.Get(ctx, &secret, &keep))
+
+	if resp.Error != nil {
+		return
+	}
+
+	if keep < 0 {
+		resp.Error = function.NewArgumentFuncError(1, "keep must not be negative")
+		return
+	}
+
+	resp.Error = function.ConcatFuncErrors(resp.Result.Set(ctx, redact(secret, keep)))
+}
+
+// redact masks all but the first and last keep characters of secret, or all
+// of them when that would reveal more than half of secret. keep is compared
+// without multiplying it, which would overflow for large values.
+func redact(secret string, keep int64) string {
+	runes := []rune(secret)
+	if keep > int64(len(runes)/4) {
+		return strings.Repeat(redactMask, len(runes))
+	}
+	return string(runes[:keep]) + strings.Repeat(redactMask, len(runes)-2*int(keep)) + string(runes[len(runes)-int(keep):])
+}
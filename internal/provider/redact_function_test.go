@@ -0,0 +1,61 @@
+// Copyright (c) Plex, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package provider
+
+import (
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-testing/helper/resource"
+	"github.com/hashicorp/terraform-plugin-testing/knownvalue"
+	"github.com/hashicorp/terraform-plugin-testing/statecheck"
+	"github.com/hashicorp/terraform-plugin-testing/tfversion"
+)
+
+func TestRedactFunction(t *testing.T) {
+	resource.UnitTest(t, resource.TestCase{
+		TerraformVersionChecks: []tfversion.TerraformVersionCheck{
+			tfversion.SkipBelow(tfversion.Version1_8_0),
+		},
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: `
+output "long" {
+  value = provider::pwpusher::redact("hunter2hunter2", 2)
+}
+
+output "short" {
+  value = provider::pwpusher::redact("abcd", 2)
+}
+`,
+				ConfigStateChecks: []statecheck.StateCheck{
+					statecheck.ExpectKnownOutputValue("long", knownvalue.StringExact("hu**********r2")),
+					statecheck.ExpectKnownOutputValue("short", knownvalue.StringExact("****")),
+				},
+			},
+		},
+	})
+}
+
+func TestRedact(t *testing.T) {
+	for _, test := range []struct {
+		secret string
+		keep   int64
+		want   string
+	}{
+		{"hunter2hunter2", 2, "hu**********r2"},
+		{"hunter2hunter2", 0, "**************"},
+		// Keeping 4 characters on both ends would reveal 8 of 10.
+		{"0123456789", 4, "**********"},
+		{"0123456789", 2, "01******89"},
+		{"01234567", 2, "01****67"},
+		{"0123456", 2, "*******"},
+		{"hunter2", 1 << 62, "*******"},
+		{"", 1, ""},
+	} {
+		if got := redact(test.secret, test.keep); got != test.want {
+			t.Errorf("redact(%q, %d): got %q, want %q", test.secret, test.keep, got, test.want)
+		}
+	}
+}
@@ -0,0 +1,147 @@
+// Copyright (c) Plex, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package provider
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+)
+
+// redactedText replaces the text matching a redaction pattern, like the
+// masks of tflog.
+const redactedText = "***"
+
+// logRedaction scrubs the text matching the log_redaction_patterns of the
+// provider from its logs and diagnostics, and the secrets of the push being
+// created, if any, from its diagnostics.
+type logRedaction struct {
+	patterns []*regexp.Regexp
+	secrets  []string
+}
+
+// newLogRedaction returns the redaction of patterns, a list of regular
+// expressions.
+func newLogRedaction(ctx context.Context, patterns types.List) (logRedaction, diag.Diagnostics) {
+	var diags diag.Diagnostics
+	var redaction logRedaction
+	var expressions []string
+	if !patterns.IsNull() {
+		diags.Append(patterns.ElementsAs(ctx, &expressions, false)...)
+	}
+	for i, expression := range expressions {
+		pattern, err := regexp.Compile(expression)
+		if err != nil {
+			diags.AddAttributeError(
+				path.Root("log_redaction_patterns").AtListIndex(i),
+				"Invalid Redaction Pattern",
+				fmt.Sprintf("Unable to parse the regular expression %q, got error: %s", expression, err),
+			)
+			continue
+		}
+		redaction.patterns = append(redaction.patterns, pattern)
+	}
+	return redaction, diags
+}
+
+// context returns a context whose logs are redacted.
+func (r logRedaction) context(ctx context.Context) context.Context {
+	if len(r.patterns) == 0 {
+		return ctx
+	}
+	ctx = tflog.MaskAllFieldValuesRegexes(ctx, r.patterns...)
+	ctx = tflog.MaskMessageRegexes(ctx, r.patterns...)
+	for _, subsystem := range logSubsystems {
+		ctx = tflog.SubsystemMaskAllFieldValuesRegexes(ctx, subsystem, r.patterns...)
+		ctx = tflog.SubsystemMaskMessageRegexes(ctx, subsystem, r.patterns...)
+	}
+	return ctx
+}
+
+// withSecrets returns the redaction also scrubbing each of secrets, such as
+// the payload and passphrase of a push, from diagnostics. Their logs are
+// masked by maskSecrets.
+func (r logRedaction) withSecrets(secrets ...string) logRedaction {
+	// The secrets of the redaction of the provider are not shared.
+	r.secrets = append([]string(nil), r.secrets...)
+	for _, secret := range secrets {
+		// Replacing an empty string would insert between every character.
+		if secret != "" {
+			r.secrets = append(r.secrets, secret)
+		}
+	}
+	return r
+}
+
+// diagnostics returns diags with their summaries and details redacted.
+func (r logRedaction) diagnostics(diags diag.Diagnostics) diag.Diagnostics {
+	if len(r.patterns) == 0 && len(r.secrets) == 0 {
+		return diags
+	}
+	redacted := make(diag.Diagnostics, 0, len(diags))
+	for _, d := range diags {
+		summary, detail := r.text(d.Summary()), r.text(d.Detail())
+		withPath, hasPath := d.(diag.DiagnosticWithPath)
+		switch {
+		case d.Severity() == diag.SeverityError && hasPath:
+			redacted = append(redacted, diag.NewAttributeErrorDiagnostic(withPath.Path(), summary, detail))
+		case d.Severity() == diag.SeverityError:
+			redacted = append(redacted, diag.NewErrorDiagnostic(summary, detail))
+		case hasPath:
+			redacted = append(redacted, diag.NewAttributeWarningDiagnostic(withPath.Path(), summary, detail))
+		default:
+			redacted = append(redacted, diag.NewWarningDiagnostic(summary, detail))
+		}
+	}
+	return redacted
+}
+
+// text returns s with the secrets and the text matching the patterns
+// replaced.
+func (r logRedaction) text(s string) string {
+	for _, secret := range r.secrets {
+		s = strings.ReplaceAll(s, secret, redactedText)
+	}
+	for _, pattern := range r.patterns {
+		s = pattern.ReplaceAllLiteralString(s, redactedText)
+	}
+	return s
+}
+
+// recoverPanic turns a panic of the operation deferring it into an error
+// diagnostic, so that its message goes through the redaction of the
+// diagnostics of the operation, deferred before, instead of straight to the
+// crash log of Terraform.
+func recoverPanic(diags *diag.Diagnostics) {
+	if value := recover(); value != nil {
+		diags.AddError(
+			"Internal Error",
+			fmt.Sprintf("The provider failed unexpectedly, please report this issue to its maintainers: %v", value),
+		)
+	}
+}
+
+// maskSecrets returns a context whose logs mask each of secrets, such as the
+// payload and passphrase of a push.
+func maskSecrets(ctx context.Context, secrets ...string) context.Context {
+	for _, secret := range secrets {
+		// Masking an empty string would mask between every character.
+		if secret == "" {
+			continue
+		}
+		ctx = tflog.MaskAllFieldValuesStrings(ctx, secret)
+		ctx = tflog.MaskMessageStrings(ctx, secret)
+		for _, subsystem := range logSubsystems {
+			ctx = tflog.SubsystemMaskAllFieldValuesStrings(ctx, subsystem, secret)
+			ctx = tflog.SubsystemMaskMessageStrings(ctx, subsystem, secret)
+		}
+	}
+	return ctx
+}
@@ -0,0 +1,86 @@
+// Copyright (c) Plex, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package provider
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-framework/attr"
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+	"github.com/hashicorp/terraform-plugin-log/tflogtest"
+)
+
+func TestLogRedaction(t *testing.T) {
+	redaction, diags := newLogRedaction(context.Background(), types.ListValueMust(types.StringType, []attr.Value{
+		types.StringValue(`tenant-[0-9]+`),
+	}))
+	if diags.HasError() {
+		t.Fatalf("unexpected error: %v", diags)
+	}
+
+	var logs bytes.Buffer
+	ctx := redaction.context(tflogtest.RootLogger(context.Background(), &logs))
+	ctx = maskSecrets(ctx, "top secret", "")
+	tflog.Info(ctx, "pushing for tenant-42", map[string]interface{}{"payload": "top secret", "path": "/p.json"})
+	if got := logs.String(); strings.Contains(got, "tenant-42") || strings.Contains(got, "top secret") || !strings.Contains(got, "/p.json") {
+		t.Errorf("got logs %s", got)
+	}
+
+	diags.AddAttributeError(path.Root("url"), "Error for tenant-42", "Unable to reach tenant-42.")
+	diags.AddWarning("Warning", "Pushed for tenant-7.")
+	redacted := redaction.diagnostics(diags)
+	if len(redacted) != 2 {
+		t.Fatalf("got %d diagnostics, want 2", len(redacted))
+	}
+	for _, d := range redacted {
+		if strings.Contains(d.Summary()+d.Detail(), "tenant-") {
+			t.Errorf("got diagnostic %q: %q", d.Summary(), d.Detail())
+		}
+	}
+	if _, ok := redacted[0].(diag.DiagnosticWithPath); !ok || redacted[1].Severity() != diag.SeverityWarning {
+		t.Error("got diagnostics without their path or severity")
+	}
+}
+
+func TestLogRedactionInvalid(t *testing.T) {
+	_, diags := newLogRedaction(context.Background(), types.ListValueMust(types.StringType, []attr.Value{
+		types.StringValue(`tenant-(`),
+	}))
+	if !diags.HasError() {
+		t.Error("expected an error")
+	}
+}
+
+func TestLogRedactionSecrets(t *testing.T) {
+	var provider logRedaction
+	redaction := provider.withSecrets("hunter2", "")
+	if len(provider.secrets) != 0 {
+		t.Error("got the secrets in the redaction of the provider")
+	}
+
+	var diags diag.Diagnostics
+	diags.AddAttributeError(path.Root("password"), "Invalid Push", "The service rejected hunter2.")
+	func() {
+		defer func() { diags = redaction.diagnostics(diags) }()
+		defer recoverPanic(&diags)
+		panic("unable to push hunter2")
+	}()
+	if len(diags) != 2 {
+		t.Fatalf("got %d diagnostics, want 2", len(diags))
+	}
+	for _, d := range diags {
+		if strings.Contains(d.Summary()+d.Detail(), "hunter2") || !strings.Contains(d.Detail(), redactedText) {
+			t.Errorf("got diagnostic %q: %q", d.Summary(), d.Detail())
+		}
+	}
+	if diags[1].Summary() != "Internal Error" {
+		t.Errorf("got diagnostic %q for the panic", diags[1].Summary())
+	}
+}
@@ -0,0 +1,59 @@
+// Copyright (c) Plex, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package provider
+
+import (
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+)
+
+// Values of the follow_redirects attribute of the provider.
+const (
+	followRedirectsAlways   = "always"
+	followRedirectsSameHost = "same_host"
+	followRedirectsNever    = "never"
+)
+
+// maxRedirects is the number of redirects a request follows at most, as for
+// the default policy of http.Client.
+const maxRedirects = 10
+
+// redirectPolicies maps the values of follow_redirects to the CheckRedirect
+// function of the clients of the provider.
+var redirectPolicies = map[string]func(req *http.Request, via []*http.Request) error{
+	followRedirectsAlways: func(req *http.Request, via []*http.Request) error {
+		if len(via) >= maxRedirects {
+			return fmt.Errorf("stopped after %d redirects", maxRedirects)
+		}
+		return nil
+	},
+	followRedirectsSameHost: func(req *http.Request, via []*http.Request) error {
+		if len(via) >= maxRedirects {
+			return fmt.Errorf("stopped after %d redirects", maxRedirects)
+		}
+		// Redirects to plaintext would expose the payload even on the same
+		// host.
+		original := via[0].URL
+		if req.URL.Host != original.Host || (original.Scheme == "https" && req.URL.Scheme != "https") {
+			return fmt.Errorf("redirected from %s to %s, which follow_redirects = %q does not allow", original.Redacted(), req.URL.Redacted(), followRedirectsSameHost)
+		}
+		return nil
+	},
+	followRedirectsNever: func(req *http.Request, via []*http.Request) error {
+		return fmt.Errorf("redirected from %s to %s, which follow_redirects = %q does not allow", via[0].URL.Redacted(), req.URL.Redacted(), followRedirectsNever)
+	},
+}
+
+// redirectPolicyNames returns the accepted values of follow_redirects for use
+// in messages.
+func redirectPolicyNames() string {
+	names := make([]string, 0, len(redirectPolicies))
+	for name := range redirectPolicies {
+		names = append(names, "`"+name+"`")
+	}
+	sort.Strings(names)
+	return strings.Join(names, ", ")
+}
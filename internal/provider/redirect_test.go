@@ -0,0 +1,49 @@
+// Copyright (c) Plex, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package provider
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestRedirectPolicies(t *testing.T) {
+	other := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer other.Close()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/same":
+			http.Redirect(w, r, "/target", http.StatusTemporaryRedirect)
+		case "/other":
+			http.Redirect(w, r, other.URL+"/target", http.StatusTemporaryRedirect)
+		}
+	}))
+	defer server.Close()
+
+	for name, test := range map[string]struct {
+		policy string
+		path   string
+		follow bool
+	}{
+		"always same host":  {policy: followRedirectsAlways, path: "/same", follow: true},
+		"always other host": {policy: followRedirectsAlways, path: "/other", follow: true},
+		"same host":         {policy: followRedirectsSameHost, path: "/same", follow: true},
+		"same host, other":  {policy: followRedirectsSameHost, path: "/other"},
+		"never":             {policy: followRedirectsNever, path: "/same"},
+		"never, other host": {policy: followRedirectsNever, path: "/other"},
+	} {
+		t.Run(name, func(t *testing.T) {
+			client := &http.Client{CheckRedirect: redirectPolicies[test.policy]}
+			resp, err := client.Get(server.URL + test.path)
+			if err == nil {
+				resp.Body.Close()
+			}
+			if followed := err == nil; followed != test.follow {
+				t.Errorf("followed: got %t, want %t (error: %v)", followed, test.follow, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,71 @@
+// Copyright (c) Plex, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package provider
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"net/http"
+
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+)
+
+// requestIDHeader is the header carrying the ID of the operation a request
+// belongs to, which the service records in its logs.
+const requestIDHeader = "X-Request-Id"
+
+// requestIDKey is the context key of the ID of the operation requests made
+// with the context belong to.
+type requestIDKey struct{}
+
+// withRequestID returns a context whose requests and logs carry a new ID
+// for an operation of the provider, and the ID.
+func withRequestID(ctx context.Context) (context.Context, string) {
+	random := make([]byte, 16)
+	// The ID only correlates logs, a failure to read randomness is not
+	// worth failing the operation over.
+	_, _ = rand.Read(random)
+	requestID := hex.EncodeToString(random)
+	ctx = context.WithValue(ctx, requestIDKey{}, requestID)
+	return tflog.SetField(ctx, "request_id", requestID), requestID
+}
+
+// withRequestIDDetail returns diags with the request ID appended to the
+// details of its errors, so that operators can hand it to the operators of
+// the service.
+func withRequestIDDetail(diags diag.Diagnostics, requestID string) diag.Diagnostics {
+	annotated := make(diag.Diagnostics, 0, len(diags))
+	for _, d := range diags {
+		if d.Severity() != diag.SeverityError {
+			annotated = append(annotated, d)
+			continue
+		}
+		detail := d.Detail() + "\n\nRequest ID: " + requestID
+		if withPath, ok := d.(diag.DiagnosticWithPath); ok {
+			annotated = append(annotated, diag.NewAttributeErrorDiagnostic(withPath.Path(), d.Summary(), detail))
+		} else {
+			annotated = append(annotated, diag.NewErrorDiagnostic(d.Summary(), detail))
+		}
+	}
+	return annotated
+}
+
+// requestIDTransport sends the request ID of the context of requests, if
+// any.
+type requestIDTransport struct {
+	next http.RoundTripper
+}
+
+func (t *requestIDTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	requestID, ok := req.Context().Value(requestIDKey{}).(string)
+	if !ok {
+		return t.next.RoundTrip(req)
+	}
+	// A RoundTripper must not modify the request it was given.
+	req = req.Clone(req.Context())
+	req.Header.Set(requestIDHeader, requestID)
+	return t.next.RoundTrip(req)
+}
@@ -0,0 +1,64 @@
+// Copyright (c) Plex, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package provider
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+)
+
+func TestRequestIDTransport(t *testing.T) {
+	var got []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		got = append(got, r.Header.Get(requestIDHeader))
+	}))
+	defer server.Close()
+
+	client := &http.Client{Transport: &requestIDTransport{next: http.DefaultTransport}}
+	ctx, requestID := withRequestID(context.Background())
+	for _, ctx := range []context.Context{ctx, ctx, context.Background()} {
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, server.URL, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		resp, err := client.Do(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		resp.Body.Close()
+	}
+
+	if len(requestID) != 32 || got[0] != requestID || got[1] != requestID || got[2] != "" {
+		t.Errorf("got request IDs %q, want %q twice then none", got, requestID)
+	}
+}
+
+func TestWithRequestIDDetail(t *testing.T) {
+	var diags diag.Diagnostics
+	diags.AddWarning("Warning", "Detail.")
+	diags.AddError("Error", "Detail.")
+	diags.AddAttributeError(path.Root("url"), "Attribute Error", "Detail.")
+
+	annotated := withRequestIDDetail(diags, "abc123")
+	if len(annotated) != 3 {
+		t.Fatalf("got %d diagnostics, want 3", len(annotated))
+	}
+	if annotated[0].Detail() != "Detail." {
+		t.Errorf("warning: got detail %q, want it unchanged", annotated[0].Detail())
+	}
+	for _, d := range annotated[1:] {
+		if !strings.HasSuffix(d.Detail(), "\n\nRequest ID: abc123") {
+			t.Errorf("%s: got detail %q, want the request ID", d.Summary(), d.Detail())
+		}
+	}
+	if withPath, ok := annotated[2].(diag.DiagnosticWithPath); !ok || !withPath.Path().Equal(path.Root("url")) {
+		t.Error("attribute error: lost its path")
+	}
+}
@@ -0,0 +1,32 @@
+// Copyright (c) Plex, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package provider
+
+import (
+	"context"
+	"terraform-provider-pwpusher/internal/client"
+
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+)
+
+// withRequestMetrics returns a context whose requests are counted in the
+// returned metrics, for logRequestMetrics to summarize at the end of an
+// operation of the provider.
+func withRequestMetrics(ctx context.Context) (context.Context, *client.Metrics) {
+	metrics := client.NewMetrics()
+	return client.WithMetrics(ctx, metrics), metrics
+}
+
+// logRequestMetrics logs to subsystem the counts, durations, retries and statuses of the
+// requests of operation, such as "pwpusher_text create", so that slow applies
+// can be diagnosed from the logs alone. Operations without requests are not
+// logged.
+func logRequestMetrics(ctx context.Context, subsystem, operation string, metrics *client.Metrics) {
+	fields := metrics.Fields()
+	if fields["requests"] == 0 {
+		return
+	}
+	fields["operation"] = operation
+	tflog.SubsystemDebug(ctx, subsystem, "pwpusher request summary", fields)
+}
@@ -0,0 +1,307 @@
+// Copyright (c) Plex, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package provider
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"math/rand/v2"
+	"net/http"
+	"slices"
+	"strconv"
+	"terraform-provider-pwpusher/internal/client"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+)
+
+// Defaults of the retries block of the provider.
+const (
+	defaultRetryMaxAttempts = 3
+	defaultRetryMinBackoff  = "1s"
+	defaultRetryMaxBackoff  = "30s"
+	defaultMaxRetryAfter    = "1m"
+	defaultMaintenanceTime  = "5m"
+)
+
+// maintenanceBackoff is the backoff between attempts of requests to a
+// service in maintenance.
+var maintenanceBackoff = retryPolicy{minBackoff: 5 * time.Second, maxBackoff: time.Minute}
+
+// defaultRetryOn are the response status codes retried by default.
+var defaultRetryOn = []int{
+	http.StatusInternalServerError,
+	http.StatusBadGateway,
+	http.StatusServiceUnavailable,
+	http.StatusGatewayTimeout,
+}
+
+// retryPolicy describes when and how often failed requests are retried.
+type retryPolicy struct {
+	maxAttempts int
+	minBackoff  time.Duration
+	maxBackoff  time.Duration
+	retryOn     []int
+
+	// maxRetryAfter caps the delay requested by the Retry-After header of
+	// rate limited responses.
+	maxRetryAfter time.Duration
+
+	// maintenanceTimeout bounds the time 503 responses are retried for,
+	// regardless of maxAttempts. Zero retries them like other failures.
+	maintenanceTimeout time.Duration
+}
+
+// defaultRetryPolicy returns the retry policy of a provider without a
+// retries block.
+func defaultRetryPolicy() retryPolicy {
+	policy := retryPolicy{
+		maxAttempts: defaultRetryMaxAttempts,
+		retryOn:     defaultRetryOn,
+	}
+	policy.minBackoff, _ = time.ParseDuration(defaultRetryMinBackoff)
+	policy.maxBackoff, _ = time.ParseDuration(defaultRetryMaxBackoff)
+	policy.maxRetryAfter, _ = time.ParseDuration(defaultMaxRetryAfter)
+	policy.maintenanceTimeout, _ = time.ParseDuration(defaultMaintenanceTime)
+	return policy
+}
+
+// newRetryPolicy returns base with the attributes set in a retries block,
+// which may be nil, overriding its settings.
+func newRetryPolicy(ctx context.Context, base retryPolicy, retries *RetriesModel) (retryPolicy, diag.Diagnostics) {
+	var diags diag.Diagnostics
+	policy := base
+	if retries == nil {
+		return policy, diags
+	}
+
+	if !retries.MaxAttempts.IsNull() {
+		if retries.MaxAttempts.ValueInt64() < 1 {
+			diags.AddAttributeError(
+				path.Root("retries").AtName("max_attempts"),
+				"Invalid Retries Configuration",
+				"The max_attempts attribute must be at least 1.",
+			)
+		}
+		policy.maxAttempts = int(retries.MaxAttempts.ValueInt64())
+	}
+
+	for _, duration := range []struct {
+		name  string
+		value types.String
+		out   *time.Duration
+	}{
+		{"min_backoff", retries.MinBackoff, &policy.minBackoff},
+		{"max_backoff", retries.MaxBackoff, &policy.maxBackoff},
+		{"max_retry_after", retries.MaxRetryAfter, &policy.maxRetryAfter},
+		{"maintenance_timeout", retries.MaintenanceTimeout, &policy.maintenanceTimeout},
+	} {
+		if duration.value.IsNull() {
+			continue
+		}
+		var err error
+		*duration.out, err = time.ParseDuration(duration.value.ValueString())
+		if err != nil {
+			diags.AddAttributeError(path.Root("retries").AtName(duration.name), "Invalid Duration", err.Error())
+		}
+	}
+	if policy.minBackoff > policy.maxBackoff {
+		diags.AddAttributeError(
+			path.Root("retries").AtName("max_backoff"),
+			"Invalid Retries Configuration",
+			"The max_backoff attribute must not be shorter than min_backoff.",
+		)
+	}
+
+	if !retries.RetryOn.IsNull() {
+		var retryOn []int64
+		diags.Append(retries.RetryOn.ElementsAs(ctx, &retryOn, false)...)
+		policy.retryOn = make([]int, len(retryOn))
+		for i, status := range retryOn {
+			policy.retryOn[i] = int(status)
+		}
+	}
+
+	return policy, diags
+}
+
+// retryPolicyKey is the context key of the retry policy overriding the one
+// of the provider for the requests made with the context.
+type retryPolicyKey struct{}
+
+// withRetryPolicy returns a context whose requests are retried according to
+// policy.
+func withRetryPolicy(ctx context.Context, policy retryPolicy) context.Context {
+	return context.WithValue(ctx, retryPolicyKey{}, policy)
+}
+
+// retryable returns whether a request that got resp and err is worth
+// another attempt. Rate limited requests always are.
+func (p retryPolicy) retryable(resp *http.Response, err error) bool {
+	if err != nil {
+		return true
+	}
+	return resp.StatusCode == http.StatusTooManyRequests || slices.Contains(p.retryOn, resp.StatusCode)
+}
+
+// backoff returns the jittered delay before the attempt following attempt,
+// doubling from minBackoff up to maxBackoff.
+func (p retryPolicy) backoff(attempt int) time.Duration {
+	delay := p.maxBackoff
+	if shift := attempt - 1; shift < 32 && p.minBackoff<<shift < p.maxBackoff {
+		delay = p.minBackoff << shift
+	}
+	if delay <= 0 {
+		return 0
+	}
+	// Waiting between half and all of the delay spreads out the retries of
+	// concurrent requests that failed together.
+	return delay/2 + rand.N(delay/2+1)
+}
+
+// retryTransport retries requests that fail with a network error or a
+// retryable response according to policy. Create requests that may have
+// succeeded anyway are only retried once the pushLookup of their context did
+// not find the push, and not at all without one, so that retries never push
+// a secret twice.
+type retryTransport struct {
+	policy retryPolicy
+	next   http.RoundTripper
+}
+
+func (t *retryTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	ctx := req.Context()
+	policy := t.policy
+	if override, ok := ctx.Value(retryPolicyKey{}).(retryPolicy); ok {
+		policy = override
+	}
+	attemptReq := req
+	var maintenanceStart time.Time
+	maintenanceAttempts := 0
+	for attempt := 1; ; {
+		resp, err := t.next.RoundTrip(attemptReq)
+
+		// A request body that cannot be read again cannot be resent.
+		rewindable := req.Body == nil || req.Body == http.NoBody || req.GetBody != nil
+		if !rewindable || ctx.Err() != nil || !policy.retryable(resp, err) {
+			return resp, err
+		}
+		lookup, _ := ctx.Value(pushLookupKey{}).(pushLookup)
+		uncertain := createMayHaveSucceeded(req, resp, err)
+		if uncertain && lookup == nil {
+			return resp, err
+		}
+
+		var delay time.Duration
+		switch {
+		case err == nil && resp.StatusCode == http.StatusServiceUnavailable && policy.maintenanceTimeout > 0:
+			// The service is most likely being upgraded, which takes longer
+			// than a transient failure, so these attempts are bounded by
+			// time instead.
+			if maintenanceStart.IsZero() {
+				maintenanceStart = time.Now()
+				tflog.SubsystemWarn(ctx, client.LogSubsystem, "The pwpusher service is unavailable, it may be in maintenance. Retrying until it is back", map[string]interface{}{
+					"path":                req.URL.Path,
+					"maintenance_timeout": policy.maintenanceTimeout.String(),
+				})
+			}
+			maintenanceAttempts++
+			delay = maintenanceBackoff.backoff(maintenanceAttempts)
+			if retryAfter, ok := parseRetryAfter(resp.Header.Get("Retry-After"), time.Now()); ok {
+				delay = min(retryAfter, policy.maxRetryAfter)
+			}
+			if time.Since(maintenanceStart)+delay > policy.maintenanceTimeout {
+				return resp, err
+			}
+		default:
+			if attempt >= policy.maxAttempts {
+				return resp, err
+			}
+			delay = policy.backoff(attempt)
+			if err == nil && resp.StatusCode == http.StatusTooManyRequests {
+				if retryAfter, ok := parseRetryAfter(resp.Header.Get("Retry-After"), time.Now()); ok {
+					// Retrying earlier than the server asks would only be
+					// rejected again.
+					if retryAfter > policy.maxRetryAfter {
+						return resp, err
+					}
+					delay = retryAfter
+				}
+			}
+			attempt++
+		}
+
+		fields := map[string]interface{}{
+			"method":  req.Method,
+			"path":    req.URL.Path,
+			"attempt": attempt + maintenanceAttempts,
+			"delay":   delay.String(),
+		}
+		if err != nil {
+			fields["error"] = err.Error()
+		} else {
+			fields["status"] = resp.Status
+			// Draining the body lets the connection be reused.
+			_, _ = io.Copy(io.Discard, resp.Body)
+			resp.Body.Close()
+		}
+		tflog.SubsystemDebug(ctx, client.LogSubsystem, "Retrying pwpusher request", fields)
+		client.RecordRetry(ctx)
+		spanFromContext(ctx).retried()
+
+		timer := time.NewTimer(delay)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return nil, ctx.Err()
+		case <-timer.C:
+		}
+
+		if uncertain {
+			push, found, lookupErr := lookup(ctx)
+			if lookupErr != nil {
+				return nil, fmt.Errorf("%w, unable to look for it on the dashboard: %w", errCreateUncertain, lookupErr)
+			}
+			if found {
+				tflog.SubsystemWarn(ctx, client.LogSubsystem, "Adopting the push created by a failed attempt instead of retrying", map[string]interface{}{
+					"path":  req.URL.Path,
+					"token": push.ID,
+				})
+				return fakeResponse(req, http.StatusCreated, push)
+			}
+		}
+
+		attemptReq = req.Clone(ctx)
+		if req.GetBody != nil {
+			body, err := req.GetBody()
+			if err != nil {
+				return nil, err
+			}
+			attemptReq.Body = body
+		}
+	}
+}
+
+// parseRetryAfter returns the delay requested by the value of a Retry-After
+// header, either a number of seconds or an HTTP date relative to now.
+func parseRetryAfter(value string, now time.Time) (time.Duration, bool) {
+	if value == "" {
+		return 0, false
+	}
+	if seconds, err := strconv.ParseInt(value, 10, 64); err == nil {
+		if seconds < 0 {
+			return 0, false
+		}
+		return time.Duration(seconds) * time.Second, true
+	}
+	date, err := http.ParseTime(value)
+	if err != nil {
+		return 0, false
+	}
+	return max(date.Sub(now), 0), true
+}
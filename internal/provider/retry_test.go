@@ -0,0 +1,351 @@
+// Copyright (c) Plex, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package provider
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"terraform-provider-pwpusher/internal/client"
+	"testing"
+	"time"
+)
+
+func TestRetryTransport(t *testing.T) {
+	for name, test := range map[string]struct {
+		failures     int
+		status       int
+		wantStatus   int
+		wantAttempts int
+	}{
+		"success": {
+			wantStatus:   http.StatusOK,
+			wantAttempts: 1,
+		},
+		"transient failures": {
+			failures:     2,
+			status:       http.StatusServiceUnavailable,
+			wantStatus:   http.StatusOK,
+			wantAttempts: 3,
+		},
+		"too many failures": {
+			failures:     5,
+			status:       http.StatusServiceUnavailable,
+			wantStatus:   http.StatusServiceUnavailable,
+			wantAttempts: 3,
+		},
+		"rate limited": {
+			failures:     1,
+			status:       http.StatusTooManyRequests,
+			wantStatus:   http.StatusOK,
+			wantAttempts: 2,
+		},
+		"not retryable": {
+			failures:     1,
+			status:       http.StatusNotFound,
+			wantStatus:   http.StatusNotFound,
+			wantAttempts: 1,
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			attempts := 0
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				attempts++
+				if body, _ := io.ReadAll(r.Body); string(body) != `{"payload":"secret"}` {
+					t.Errorf("attempt %d: got body %q", attempts, body)
+				}
+				if attempts <= test.failures {
+					w.Header().Set("Retry-After", "0")
+					w.WriteHeader(test.status)
+				}
+			}))
+			defer server.Close()
+
+			client := &http.Client{Transport: &retryTransport{
+				policy: retryPolicy{
+					maxAttempts: 3,
+					minBackoff:  time.Millisecond,
+					maxBackoff:  10 * time.Millisecond,
+					retryOn:     defaultRetryOn,
+				},
+				next: http.DefaultTransport,
+			}}
+
+			resp, err := client.Post(server.URL, "application/json", strings.NewReader(`{"payload":"secret"}`))
+			if err != nil {
+				t.Fatal(err)
+			}
+			resp.Body.Close()
+
+			if resp.StatusCode != test.wantStatus {
+				t.Errorf("got status %d, want %d", resp.StatusCode, test.wantStatus)
+			}
+			if attempts != test.wantAttempts {
+				t.Errorf("got %d attempts, want %d", attempts, test.wantAttempts)
+			}
+		})
+	}
+}
+
+func TestRetryPolicyBackoff(t *testing.T) {
+	policy := retryPolicy{minBackoff: time.Second, maxBackoff: 10 * time.Second}
+
+	for attempt, want := range map[int]time.Duration{
+		1:  time.Second,
+		2:  2 * time.Second,
+		3:  4 * time.Second,
+		4:  8 * time.Second,
+		5:  10 * time.Second,
+		64: 10 * time.Second,
+	} {
+		if got := policy.backoff(attempt); got < want/2 || got > want {
+			t.Errorf("attempt %d: got %s, want between %s and %s", attempt, got, want/2, want)
+		}
+	}
+}
+
+func TestRetryTransportRetryAfterCap(t *testing.T) {
+	attempts := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		attempts++
+		w.Header().Set("Retry-After", "3600")
+		w.WriteHeader(http.StatusTooManyRequests)
+	}))
+	defer server.Close()
+
+	client := &http.Client{Transport: &retryTransport{
+		policy: retryPolicy{maxAttempts: 3, maxRetryAfter: time.Minute},
+		next:   http.DefaultTransport,
+	}}
+
+	resp, err := client.Get(server.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+
+	if resp.StatusCode != http.StatusTooManyRequests || attempts != 1 {
+		t.Errorf("got status %d after %d attempts, want %d after 1", resp.StatusCode, attempts, http.StatusTooManyRequests)
+	}
+}
+
+func TestParseRetryAfter(t *testing.T) {
+	now := time.Date(2024, 11, 5, 12, 0, 0, 0, time.UTC)
+
+	for value, want := range map[string]struct {
+		delay time.Duration
+		ok    bool
+	}{
+		"":                              {},
+		"120":                           {delay: 2 * time.Minute, ok: true},
+		"-1":                            {},
+		"Tue, 05 Nov 2024 12:00:30 GMT": {delay: 30 * time.Second, ok: true},
+		"Tue, 05 Nov 2024 11:00:00 GMT": {delay: 0, ok: true},
+		"soon":                          {},
+	} {
+		delay, ok := parseRetryAfter(value, now)
+		if delay != want.delay || ok != want.ok {
+			t.Errorf("%q: got %s, %t, want %s, %t", value, delay, ok, want.delay, want.ok)
+		}
+	}
+}
+
+func TestRetryTransportMaintenance(t *testing.T) {
+	for name, test := range map[string]struct {
+		maintenanceTimeout time.Duration
+		retryAfter         string
+		wantStatus         int
+		wantAttempts       int
+	}{
+		"back before the timeout": {
+			maintenanceTimeout: time.Minute,
+			retryAfter:         "0",
+			wantStatus:         http.StatusOK,
+			wantAttempts:       6,
+		},
+		"longer than the timeout": {
+			maintenanceTimeout: 500 * time.Millisecond,
+			retryAfter:         "1",
+			wantStatus:         http.StatusServiceUnavailable,
+			wantAttempts:       1,
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			attempts := 0
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				attempts++
+				if attempts <= 5 {
+					w.Header().Set("Retry-After", test.retryAfter)
+					w.WriteHeader(http.StatusServiceUnavailable)
+				}
+			}))
+			defer server.Close()
+
+			client := &http.Client{Transport: &retryTransport{
+				policy: retryPolicy{
+					maxAttempts:        2,
+					retryOn:            defaultRetryOn,
+					maxRetryAfter:      time.Minute,
+					maintenanceTimeout: test.maintenanceTimeout,
+				},
+				next: http.DefaultTransport,
+			}}
+
+			resp, err := client.Get(server.URL)
+			if err != nil {
+				t.Fatal(err)
+			}
+			resp.Body.Close()
+
+			if resp.StatusCode != test.wantStatus {
+				t.Errorf("got status %d, want %d", resp.StatusCode, test.wantStatus)
+			}
+			if attempts != test.wantAttempts {
+				t.Errorf("got %d attempts, want %d", attempts, test.wantAttempts)
+			}
+		})
+	}
+}
+
+func TestRetryTransportMetrics(t *testing.T) {
+	attempts := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		attempts++
+		if attempts < 3 {
+			w.WriteHeader(http.StatusTooManyRequests)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"application_version":"1.0.0"}`))
+	}))
+	defer server.Close()
+
+	pushClient := client.New(&http.Client{Transport: &retryTransport{
+		policy: retryPolicy{maxAttempts: 3, minBackoff: time.Millisecond, maxBackoff: time.Millisecond},
+		next:   http.DefaultTransport,
+	}}, server.URL, "")
+	ctx, metrics := withRequestMetrics(context.Background())
+	if _, err := pushClient.Version(ctx); err != nil {
+		t.Fatal(err)
+	}
+
+	got := metrics.Summary()["Version"]
+	if got.Requests != 1 || got.Retries != 2 || got.Statuses[http.StatusOK] != 1 {
+		t.Errorf("got %+v, want 1 request with 2 retries answered with 200", got)
+	}
+}
+
+func TestRetryTransportCreateLookup(t *testing.T) {
+	created := client.Push{ID: "token1", Note: "Terraform create ID: abc"}
+	for name, test := range map[string]struct {
+		lookup       pushLookup
+		wantStatus   int
+		wantAttempts int
+		wantErr      error
+	}{
+		"anonymous": {
+			wantStatus:   http.StatusGatewayTimeout,
+			wantAttempts: 1,
+		},
+		"created": {
+			lookup: func(context.Context) (client.Push, bool, error) {
+				return created, true, nil
+			},
+			wantStatus:   http.StatusCreated,
+			wantAttempts: 1,
+		},
+		"not created": {
+			lookup: func(context.Context) (client.Push, bool, error) {
+				return client.Push{}, false, nil
+			},
+			wantStatus:   http.StatusOK,
+			wantAttempts: 2,
+		},
+		"lookup failed": {
+			lookup: func(context.Context) (client.Push, bool, error) {
+				return client.Push{}, false, client.ErrUnauthorized
+			},
+			wantAttempts: 1,
+			wantErr:      errCreateUncertain,
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			attempts := 0
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				attempts++
+				if attempts == 1 {
+					w.WriteHeader(http.StatusGatewayTimeout)
+				}
+			}))
+			defer server.Close()
+
+			ctx := context.Background()
+			if test.lookup != nil {
+				ctx = withPushLookup(ctx, test.lookup)
+			}
+			req, err := http.NewRequestWithContext(ctx, http.MethodPost, server.URL+"/p.json", strings.NewReader(`{"push":{}}`))
+			if err != nil {
+				t.Fatal(err)
+			}
+			transport := &retryTransport{
+				policy: retryPolicy{maxAttempts: 3, minBackoff: time.Millisecond, maxBackoff: time.Millisecond, retryOn: defaultRetryOn},
+				next:   http.DefaultTransport,
+			}
+			resp, err := (&http.Client{Transport: transport}).Do(req)
+			if attempts != test.wantAttempts {
+				t.Errorf("got %d attempts, want %d", attempts, test.wantAttempts)
+			}
+			if test.wantErr != nil {
+				if !errors.Is(err, test.wantErr) {
+					t.Errorf("got error %v, want %v", err, test.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			body, _ := io.ReadAll(resp.Body)
+			resp.Body.Close()
+			if resp.StatusCode != test.wantStatus {
+				t.Errorf("got status %d, want %d", resp.StatusCode, test.wantStatus)
+			}
+			if test.wantStatus == http.StatusCreated && !strings.Contains(string(body), `"url_token":"token1"`) {
+				t.Errorf("got body %s", body)
+			}
+		})
+	}
+}
+
+func TestCreateMayHaveSucceeded(t *testing.T) {
+	post := httptest.NewRequest(http.MethodPost, "/p.json", nil)
+	for name, test := range map[string]struct {
+		req    *http.Request
+		status int
+		err    error
+		want   bool
+	}{
+		"timeout":             {req: post, err: context.DeadlineExceeded, want: true},
+		"connection refused":  {req: post, err: &net.OpError{Op: "dial", Err: errors.New("connection refused")}},
+		"connection reset":    {req: post, err: &net.OpError{Op: "read", Err: errors.New("connection reset by peer")}, want: true},
+		"gateway timeout":     {req: post, status: http.StatusGatewayTimeout, want: true},
+		"server error":        {req: post, status: http.StatusInternalServerError, want: true},
+		"service unavailable": {req: post, status: http.StatusServiceUnavailable},
+		"rate limited":        {req: post, status: http.StatusTooManyRequests},
+		"read":                {req: httptest.NewRequest(http.MethodGet, "/p/active.json", nil), status: http.StatusGatewayTimeout},
+	} {
+		t.Run(name, func(t *testing.T) {
+			var resp *http.Response
+			if test.err == nil {
+				resp = &http.Response{StatusCode: test.status}
+			}
+			if got := createMayHaveSucceeded(test.req, resp, test.err); got != test.want {
+				t.Errorf("got %t, want %t", got, test.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,90 @@
+// Copyright (c) Plex, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package provider
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/binary"
+	"fmt"
+
+	"github.com/hashicorp/terraform-plugin-framework/function"
+)
+
+// Seeds shorter than minSeedLength or with fewer than minSeedChars distinct
+// characters are rejected as too easy to guess: anyone who can guess the
+// seed can compute the secret derived from it.
+const (
+	minSeedLength = 16
+	minSeedChars  = 8
+)
+
+// seedDescription is the description of the seed parameter of the functions
+// deriving a secret from it.
+const seedDescription = "marked sensitive, such as a `sensitive` input variable, since the result is only as secret as the seed. Values visible in the plan or the state, such as the `id` of a `terraform_data` resource, are not secret"
+
+// checkSeed returns an error for the seed argument at position when seed is
+// too short or has too few distinct characters.
+func checkSeed(position int64, seed string) *function.FuncError {
+	chars := map[rune]bool{}
+	for _, c := range seed {
+		chars[c] = true
+	}
+	if len(seed) < minSeedLength || len(chars) < minSeedChars {
+		return function.NewArgumentFuncError(position, fmt.Sprintf("seed must be at least %d characters long with at least %d distinct characters, use a long random secret", minSeedLength, minSeedChars))
+	}
+	return nil
+}
+
+// seededRand is a deterministic stream of random numbers derived from a
+// seed with HMAC-SHA256 in counter mode. Terraform expects provider
+// functions to return the same result for the same arguments at plan and
+// apply time, so generating functions draw from a seed instead of the
+// system random source.
+type seededRand struct {
+	key     []byte
+	counter uint64
+	buf     []byte
+}
+
+// newSeededRand returns a stream for seed. The purpose separates the
+// streams of different functions called with the same seed.
+func newSeededRand(seed, purpose string) *seededRand {
+	mac := hmac.New(sha256.New, []byte(seed))
+	mac.Write([]byte(purpose))
+	return &seededRand{key: mac.Sum(nil)}
+}
+
+func (r *seededRand) uint32() uint32 {
+	if len(r.buf) < 4 {
+		mac := hmac.New(sha256.New, r.key)
+		var block [8]byte
+		binary.BigEndian.PutUint64(block[:], r.counter)
+		mac.Write(block[:])
+		r.counter++
+		r.buf = mac.Sum(nil)
+	}
+	v := binary.BigEndian.Uint32(r.buf)
+	r.buf = r.buf[4:]
+	return v
+}
+
+// intn returns a uniformly distributed number in [0, n).
+func (r *seededRand) intn(n int) int {
+	// Reject the values of the incomplete last range to avoid modulo bias.
+	limit := ^uint32(0) - ^uint32(0)%uint32(n)
+	for {
+		if v := r.uint32(); v < limit {
+			return int(v % uint32(n))
+		}
+	}
+}
+
+// shuffle randomly reorders b in place.
+func (r *seededRand) shuffle(b []byte) {
+	for i := len(b) - 1; i > 0; i-- {
+		j := r.intn(i + 1)
+		b[i], b[j] = b[j], b[i]
+	}
+}
@@ -0,0 +1,125 @@
+// Copyright (c) Plex, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package provider
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"terraform-provider-pwpusher/internal/client"
+)
+
+// serverInfo remembers what the service reports about itself, its version,
+// locales, push defaults and which routes it has, for the lifetime of the provider instance, so that
+// the validations and data sources consulting them share a single lookup.
+// Failed lookups are not remembered, so that the next use tries again.
+type serverInfo struct {
+	mu      sync.Mutex
+	version *client.Version
+	locales map[string]string
+	form    *client.PushForm
+	routes  map[string]bool
+}
+
+// serverVersion returns the version information of the service, looking it
+// up on first use.
+func (d ProviderData) serverVersion(ctx context.Context) (client.Version, error) {
+	if d.serverInfo == nil {
+		return d.apiClient().Version(ctx)
+	}
+	d.serverInfo.mu.Lock()
+	defer d.serverInfo.mu.Unlock()
+	if d.serverInfo.version != nil {
+		return *d.serverInfo.version, nil
+	}
+
+	version, err := d.apiClient().Version(ctx)
+	if err != nil {
+		return client.Version{}, err
+	}
+	d.serverInfo.version = &version
+	return version, nil
+}
+
+// routeExists reports whether the service routes path, looking it up on first
+// use.
+func (d ProviderData) routeExists(ctx context.Context, path string) (bool, error) {
+	if d.serverInfo == nil {
+		return d.apiClient().RouteExists(ctx, path)
+	}
+	d.serverInfo.mu.Lock()
+	defer d.serverInfo.mu.Unlock()
+	if exists, ok := d.serverInfo.routes[path]; ok {
+		return exists, nil
+	}
+
+	exists, err := d.apiClient().RouteExists(ctx, path)
+	if err != nil {
+		return false, err
+	}
+	if d.serverInfo.routes == nil {
+		d.serverInfo.routes = map[string]bool{}
+	}
+	d.serverInfo.routes[path] = exists
+	return exists, nil
+}
+
+// serverLocales returns the UI locales of the service, looking them up on
+// first use, and whether the service listed them. Services that do not, such
+// as ones with a custom front page, get supportedLocales instead.
+func (d ProviderData) serverLocales(ctx context.Context) (map[string]string, bool, error) {
+	if d.serverInfo != nil {
+		d.serverInfo.mu.Lock()
+		defer d.serverInfo.mu.Unlock()
+		if d.serverInfo.locales != nil {
+			return localesOrDefault(d.serverInfo.locales)
+		}
+	}
+
+	locales, err := d.apiClient().Locales(ctx)
+	if errors.Is(err, client.ErrNotFound) {
+		locales, err = map[string]string{}, nil
+	}
+	if err != nil {
+		return nil, false, err
+	}
+	if d.serverInfo != nil {
+		d.serverInfo.locales = locales
+	}
+	return localesOrDefault(locales)
+}
+
+// localesOrDefault returns locales, or supportedLocales when it is empty, and
+// whether they are locales.
+func localesOrDefault(locales map[string]string) (map[string]string, bool, error) {
+	if len(locales) == 0 {
+		return supportedLocales, false, nil
+	}
+	return locales, true, nil
+}
+
+// serverPushDefaults returns the push defaults of the service, looking them
+// up on first use. The settings its new push form does not show, all of them
+// for services without one such as in fake mode, are those of appPushDefaults.
+func (d ProviderData) serverPushDefaults(ctx context.Context) (pushDefaults, error) {
+	if d.serverInfo != nil {
+		d.serverInfo.mu.Lock()
+		defer d.serverInfo.mu.Unlock()
+		if d.serverInfo.form != nil {
+			return newPushDefaults(*d.serverInfo.form), nil
+		}
+	}
+
+	form, err := d.apiClient().PushForm(ctx)
+	if errors.Is(err, client.ErrNotFound) {
+		form, err = client.PushForm{}, nil
+	}
+	if err != nil {
+		return pushDefaults{}, err
+	}
+	if d.serverInfo != nil {
+		d.serverInfo.form = &form
+	}
+	return newPushDefaults(form), nil
+}
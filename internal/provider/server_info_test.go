@@ -0,0 +1,75 @@
+// Copyright (c) Plex, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package provider
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+func TestProviderDataServerInfo(t *testing.T) {
+	requests := map[string]int{}
+	healthy := false
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests[r.URL.Path]++
+		switch {
+		case !healthy:
+			w.WriteHeader(http.StatusInternalServerError)
+		case r.URL.Path == "/api/v1/version.json":
+			w.Header().Set("Content-Type", "application/json")
+			_, _ = w.Write([]byte(`{"application_version":"1.50.0","api_version":"1.0","edition":"pro"}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	ctx := context.Background()
+	d := ProviderData{client: server.Client(), anonymousClient: server.Client(), url: types.StringValue(server.URL), serverInfo: &serverInfo{}}
+
+	// Failed lookups are tried again.
+	if _, err := d.serverVersion(ctx); err == nil {
+		t.Fatal("expected an error from an unhealthy service")
+	}
+	healthy = true
+	for range 2 {
+		version, err := d.serverVersion(ctx)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if version.Edition != "pro" {
+			t.Errorf("got edition %q, want pro", version.Edition)
+		}
+		exists, err := d.routeExists(ctx, "/f/active.json")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if exists {
+			t.Error("got an existing route, want none")
+		}
+	}
+	if got := requests["/api/v1/version.json"]; got != 2 {
+		t.Errorf("got %d version requests, want 2", got)
+	}
+	if got := requests["/f/active.json"]; got != 1 {
+		t.Errorf("got %d route requests, want 1", got)
+	}
+
+	// Other services are looked up again.
+	other, diags := d.withEndpoint(path.Root("url"), server.URL+"/other")
+	if diags.HasError() {
+		t.Fatal(diags)
+	}
+	if _, err := other.routeExists(ctx, "/f/active.json"); err != nil {
+		t.Fatal(err)
+	}
+	if got := requests["/other/f/active.json"]; got != 1 {
+		t.Errorf("got %d route requests to the other service, want 1", got)
+	}
+}
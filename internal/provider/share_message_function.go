@@ -0,0 +1,104 @@
+// Copyright (c) Plex, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package provider
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"text/template"
+
+	"github.com/hashicorp/terraform-plugin-framework/function"
+)
+
+// Ensure provider defined types fully satisfy framework interfaces.
+var _ function.Function = &ShareMessageFunction{}
+
+// defaultShareMessageTemplate is the template share_message renders when
+// none is supplied.
+const defaultShareMessageTemplate = `I shared a secret with you: {{.URL}}
+
+{{.Remaining}} before the link expires.`
+
+func NewShareMessageFunction() function.Function {
+	return &ShareMessageFunction{}
+}
+
+// ShareMessageFunction defines the function implementation.
+type ShareMessageFunction struct{}
+
+// ShareMessageData is the data share_message templates are rendered with.
+type ShareMessageData struct {
+	URL            string
+	DaysRemaining  int64
+	ViewsRemaining int64
+	Remaining      string
+}
+
+func (f *ShareMessageFunction) Metadata(ctx context.Context, req function.MetadataRequest, resp *function.MetadataResponse) {
+	resp.Name = "share_message"
+}
+
+func (f *ShareMessageFunction) Definition(ctx context.Context, req function.DefinitionRequest, resp *function.DefinitionResponse) {
+	resp.Definition = function.Definition{
+		Summary:             "Render a message sharing a push",
+		MarkdownDescription: "Renders a message for delivering a push through chat or email. The template uses the [Go template syntax](https://pkg.go.dev/text/template) with the fields `{{.URL}}`, `{{.DaysRemaining}}`, `{{.ViewsRemaining}}` and `{{.Remaining}}`, the latter as returned by `humanize_remaining`. The default template is:\n\n```text\n" + defaultShareMessageTemplate + "\n```",
+		Parameters: []function.Parameter{
+			function.StringParameter{
+				Name:                "url",
+				MarkdownDescription: "The URL of the push",
+			},
+			function.Int64Parameter{
+				Name:                "days_remaining",
+				MarkdownDescription: "The `days_remaining` of the push",
+			},
+			function.Int64Parameter{
+				Name:                "views_remaining",
+				MarkdownDescription: "The `views_remaining` of the push",
+			},
+			function.StringParameter{
+				Name:                "template",
+				MarkdownDescription: "The template of the message, or null for the default",
+				AllowNullValue:      true,
+			},
+		},
+		Return: function.StringReturn{},
+	}
+}
+
+func (f *ShareMessageFunction) Run(ctx context.Context, req function.RunRequest, resp *function.RunResponse) {
+	var url string
+	var days, views int64
+	var text *string
+
+	resp.Error = function.ConcatFuncErrors(req.Arguments.Get(ctx, &url, &days, &views, &text))
+
+	if resp.Error != nil {
+		return
+	}
+
+	if text == nil {
+		text = new(string)
+		*text = defaultShareMessageTemplate
+	}
+	tmpl, err := template.New("share_message").Option("missingkey=error").Parse(*text)
+	if err != nil {
+		resp.Error = function.NewArgumentFuncError(3, fmt.Sprintf("invalid template: %s", err))
+		return
+	}
+
+	var message strings.Builder
+	err = tmpl.Execute(&message, ShareMessageData{
+		URL:            url,
+		DaysRemaining:  days,
+		ViewsRemaining: views,
+		Remaining:      humanizeRemaining(&days, &views),
+	})
+	if err != nil {
+		resp.Error = function.NewArgumentFuncError(3, fmt.Sprintf("unable to render template: %s", err))
+		return
+	}
+
+	resp.Error = function.ConcatFuncErrors(resp.Result.Set(ctx, message.String()))
+}
@@ -0,0 +1,48 @@
+// Copyright (c) Plex, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package provider
+
+import (
+	"regexp"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-testing/helper/resource"
+	"github.com/hashicorp/terraform-plugin-testing/knownvalue"
+	"github.com/hashicorp/terraform-plugin-testing/statecheck"
+	"github.com/hashicorp/terraform-plugin-testing/tfversion"
+)
+
+func TestShareMessageFunction(t *testing.T) {
+	resource.UnitTest(t, resource.TestCase{
+		TerraformVersionChecks: []tfversion.TerraformVersionCheck{
+			tfversion.SkipBelow(tfversion.Version1_8_0),
+		},
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: `
+output "default" {
+  value = provider::pwpusher::share_message("https://pwpush.com/p/abc123", 3, 2, null)
+}
+
+output "custom" {
+  value = provider::pwpusher::share_message("https://pwpush.com/p/abc123", 3, 2, "{{.URL}} ({{.ViewsRemaining}} views)")
+}
+`,
+				ConfigStateChecks: []statecheck.StateCheck{
+					statecheck.ExpectKnownOutputValue("default", knownvalue.StringExact("I shared a secret with you: https://pwpush.com/p/abc123\n\n3 days or 2 views left before the link expires.")),
+					statecheck.ExpectKnownOutputValue("custom", knownvalue.StringExact("https://pwpush.com/p/abc123 (2 views)")),
+				},
+			},
+			{
+				Config: `
+output "test" {
+  value = provider::pwpusher::share_message("https://pwpush.com/p/abc123", 3, 2, "{{.Missing}}")
+}
+`,
+				ExpectError: regexp.MustCompile("unable to render template"),
+			},
+		},
+	})
+}
@@ -0,0 +1,194 @@
+// Copyright (c) Plex, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package provider
+
+import (
+	"context"
+	"fmt"
+	"terraform-provider-pwpusher/internal/client"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-framework-timeouts/datasource/timeouts"
+	"github.com/hashicorp/terraform-plugin-framework/datasource"
+	"github.com/hashicorp/terraform-plugin-framework/datasource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+// Ensure provider defined types fully satisfy framework interfaces.
+var _ datasource.DataSource = &StatsDataSource{}
+
+const (
+	defaultStatsExpiringWithinDays = 1
+	defaultStatsPeriodDays         = 30
+)
+
+func NewStatsDataSource() datasource.DataSource {
+	return &StatsDataSource{}
+}
+
+// StatsDataSource defines the data source implementation.
+type StatsDataSource struct {
+	providerData ProviderData
+}
+
+// StatsDataSourceModel describes the data source data model.
+type StatsDataSourceModel struct {
+	ExpiringWithinDays types.Int32    `tfsdk:"expiring_within_days"`
+	PeriodDays         types.Int32    `tfsdk:"period_days"`
+	ActiveCount        types.Int32    `tfsdk:"active_count"`
+	ExpiredCount       types.Int32    `tfsdk:"expired_count"`
+	ExpiringCount      types.Int32    `tfsdk:"expiring_count"`
+	ViewCount          types.Int32    `tfsdk:"view_count"`
+	Timeouts           timeouts.Value `tfsdk:"timeouts"`
+}
+
+func (d *StatsDataSource) Metadata(ctx context.Context, req datasource.MetadataRequest, resp *datasource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_stats"
+}
+
+func (d *StatsDataSource) Schema(ctx context.Context, req datasource.SchemaRequest, resp *datasource.SchemaResponse) {
+	resp.Schema = schema.Schema{
+		// This description is used by the documentation generator and the language server.
+		MarkdownDescription: "Aggregate counts of the pushes owned by the authenticated account",
+
+		Attributes: map[string]schema.Attribute{
+			"expiring_within_days": schema.Int32Attribute{
+				MarkdownDescription: fmt.Sprintf("Count active pushes with at most this many days remaining as expiring. Defaults to `%d`", defaultStatsExpiringWithinDays),
+				Optional:            true,
+				Computed:            true,
+			},
+			"period_days": schema.Int32Attribute{
+				MarkdownDescription: fmt.Sprintf("Only count views within this many days. Defaults to `%d`", defaultStatsPeriodDays),
+				Optional:            true,
+				Computed:            true,
+			},
+			"active_count": schema.Int32Attribute{
+				MarkdownDescription: "The number of active pushes",
+				Computed:            true,
+			},
+			"expired_count": schema.Int32Attribute{
+				MarkdownDescription: "The number of expired pushes",
+				Computed:            true,
+			},
+			"expiring_count": schema.Int32Attribute{
+				MarkdownDescription: "The number of active pushes expiring within `expiring_within_days`",
+				Computed:            true,
+			},
+			"view_count": schema.Int32Attribute{
+				MarkdownDescription: "The number of successful views of the pushes within `period_days`, from their audit logs. Reading it fetches the audit log of every push that did not expire before the period",
+				Computed:            true,
+			},
+		},
+
+		Blocks: map[string]schema.Block{
+			"timeouts": timeouts.Block(ctx),
+		},
+	}
+}
+
+func (d *StatsDataSource) Configure(ctx context.Context, req datasource.ConfigureRequest, resp *datasource.ConfigureResponse) {
+	// Prevent panic if the provider has not been configured.
+	if req.ProviderData == nil {
+		return
+	}
+
+	providerData, ok := req.ProviderData.(ProviderData)
+
+	if !ok {
+		resp.Diagnostics.AddError(
+			"Unexpected Data Source Configure Type",
+			fmt.Sprintf("Expected ProviderData, got: %T. Please report this issue to the provider developers.", req.ProviderData),
+		)
+
+		return
+	}
+
+	d.providerData = providerData
+}
+
+func (d *StatsDataSource) Read(ctx context.Context, req datasource.ReadRequest, resp *datasource.ReadResponse) {
+	var data StatsDataSourceModel
+
+	// Read Terraform configuration data into the model
+	resp.Diagnostics.Append(req.Config.Get(ctx, &data)...)
+
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	readTimeout, diags := data.Timeouts.Read(ctx, defaultDataSourceReadTimeout)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	ctx, cancel := context.WithTimeout(ctx, readTimeout)
+	defer cancel()
+
+	ctx, op := d.providerData.operationContext(ctx, logSubsystemDataSources, "pwpusher_stats read")
+	defer op.end(&resp.Diagnostics)
+
+	if data.ExpiringWithinDays.IsNull() {
+		data.ExpiringWithinDays = types.Int32Value(defaultStatsExpiringWithinDays)
+	}
+	if data.PeriodDays.IsNull() {
+		data.PeriodDays = types.Int32Value(defaultStatsPeriodDays)
+	}
+
+	active, err := d.providerData.apiClient().ListPushes(ctx, "active")
+	if err != nil {
+		resp.Diagnostics.AddError("Client Error", fmt.Sprintf("Unable to list active pushes, got error: %s", err))
+		return
+	}
+	expired, err := d.providerData.apiClient().ListPushes(ctx, "expired")
+	if err != nil {
+		resp.Diagnostics.AddError("Client Error", fmt.Sprintf("Unable to list expired pushes, got error: %s", err))
+		return
+	}
+
+	var expiring int32
+	for _, push := range active {
+		if int32(push.DaysRemaining) <= data.ExpiringWithinDays.ValueInt32() {
+			expiring++
+		}
+	}
+
+	// The dashboards do not tell when pushes were viewed, their audit logs
+	// do. Pushes that expired before the period cannot have been viewed
+	// within it.
+	since := time.Now().AddDate(0, 0, -int(data.PeriodDays.ValueInt32()))
+	var views int32
+	for _, push := range append(active, expired...) {
+		if expiredAt, err := time.Parse(time.RFC3339, push.ExpiredAt); push.Expired && err == nil && expiredAt.Before(since) {
+			continue
+		}
+		auditLog, err := d.providerData.apiClient().Audit(ctx, push.ID)
+		if err != nil {
+			resp.Diagnostics.AddError("Client Error", fmt.Sprintf("Unable to read the audit log of push %s, got error: %s", push.ID, err))
+			return
+		}
+		views += successfulViewsSince(auditLog, since)
+	}
+
+	data.ActiveCount = types.Int32Value(int32(len(active)))
+	data.ExpiredCount = types.Int32Value(int32(len(expired)))
+	data.ExpiringCount = types.Int32Value(expiring)
+	data.ViewCount = types.Int32Value(views)
+
+	// Save data into Terraform state
+	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+}
+
+// successfulViewsSince returns the number of successful retrievals recorded
+// in the audit log at since or later.
+func successfulViewsSince(auditLog client.AuditLog, since time.Time) int32 {
+	var count int32
+	for _, view := range auditLog.Views {
+		createdAt, err := time.Parse(time.RFC3339, view.CreatedAt)
+		if view.Kind != client.AuditViewKindView || !view.Successful || err != nil || createdAt.Before(since) {
+			continue
+		}
+		count++
+	}
+	return count
+}
@@ -0,0 +1,89 @@
+// Copyright (c) Plex, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package provider
+
+import (
+	"context"
+	"terraform-provider-pwpusher/internal/client"
+	"terraform-provider-pwpusher/internal/client/clienttest"
+	"testing"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-testing/helper/resource"
+)
+
+func TestAccStatsDataSource(t *testing.T) {
+	resource.Test(t, resource.TestCase{
+		PreCheck:                 func() { testAccPreCheck(t) },
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			// Read testing
+			{
+				Config: testAccStatsDataSourceConfig,
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("data.pwpusher_stats.test", "expiring_within_days", "1"),
+					resource.TestCheckResourceAttr("data.pwpusher_stats.test", "period_days", "30"),
+					resource.TestCheckResourceAttrSet("data.pwpusher_stats.test", "active_count"),
+					resource.TestCheckResourceAttrSet("data.pwpusher_stats.test", "view_count"),
+				),
+			},
+		},
+	})
+}
+
+func TestStatsDataSourceFakeClient(t *testing.T) {
+	server := clienttest.NewServer()
+	defer server.Close()
+
+	ctx := context.Background()
+	pushClient := server.Client("")
+	for _, password := range []string{"one", "two", "three"} {
+		if _, err := pushClient.CreatePush(ctx, client.CurrentAPI, client.Payload{Password: password, Kind: "text"}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	server.View("token1")
+	server.View("token1")
+	if err := pushClient.ExpirePush(ctx, "token2"); err != nil {
+		t.Fatal(err)
+	}
+
+	resource.UnitTest(t, resource.TestCase{
+		ProtoV6ProviderFactories: testFakeClientProviderFactories(server),
+		Steps: []resource.TestStep{
+			{
+				Config: testFakeClientProviderConfig(server) + `
+data "pwpusher_stats" "test" {
+  expiring_within_days = 7
+}
+`,
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("data.pwpusher_stats.test", "active_count", "2"),
+					resource.TestCheckResourceAttr("data.pwpusher_stats.test", "expired_count", "1"),
+					resource.TestCheckResourceAttr("data.pwpusher_stats.test", "expiring_count", "2"),
+					resource.TestCheckResourceAttr("data.pwpusher_stats.test", "view_count", "2"),
+				),
+			},
+		},
+	})
+}
+
+func TestSuccessfulViewsSince(t *testing.T) {
+	since := time.Date(2024, 5, 1, 0, 0, 0, 0, time.UTC)
+	auditLog := client.AuditLog{Views: []client.AuditView{
+		{Successful: true, CreatedAt: "2024-04-30T23:59:59Z", Kind: client.AuditViewKindView},
+		{Successful: true, CreatedAt: "2024-05-01T00:00:00Z", Kind: client.AuditViewKindView},
+		{Successful: true, CreatedAt: "2024-05-02T12:00:00Z", Kind: client.AuditViewKindView},
+		{Successful: false, CreatedAt: "2024-05-02T12:00:00Z", Kind: client.AuditViewKindView},
+		{Successful: true, CreatedAt: "2024-05-03T12:00:00Z", Kind: client.AuditViewKindView + 1},
+		{Successful: true, CreatedAt: "not a time", Kind: client.AuditViewKindView},
+	}}
+	if got := successfulViewsSince(auditLog, since); got != 2 {
+		t.Errorf("got %d views, want the 2 successful retrievals from %s on", got, since)
+	}
+}
+
+const testAccStatsDataSourceConfig = `
+data "pwpusher_stats" "test" {}
+`
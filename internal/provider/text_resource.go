@@ -4,12 +4,18 @@
 package provider
 
 import (
-	"bytes"
 	"context"
-	"encoding/json"
+	"errors"
 	"fmt"
-	"io"
-
+	"reflect"
+	"sort"
+	"strings"
+	"terraform-provider-pwpusher/internal/client"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-framework/attr"
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/path"
 	"github.com/hashicorp/terraform-plugin-framework/resource"
 	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
 	"github.com/hashicorp/terraform-plugin-framework/resource/schema/planmodifier"
@@ -21,6 +27,7 @@ import (
 // Ensure provider defined types fully satisfy framework interfaces.
 var _ resource.Resource = &TextResource{}
 var _ resource.ResourceWithImportState = &TextResource{}
+var _ resource.ResourceWithModifyPlan = &TextResource{}
 
 func NewTextResource() resource.Resource {
 	return &TextResource{}
@@ -31,48 +38,31 @@ type TextResource struct {
 	providerData ProviderData
 }
 
-type SecretPayload struct {
-	Password   string  `json:"payload"`
-	Passphrase *string `json:"passphrase"`
-	// ExpireAfterDays   int    `json:"expire_after_days"`
-	// ExpireAfterViews  int    `json:"expire_after_views"`
-	DeletableByViewer bool   `json:"deletable_by_viewer"`
-	RetrievalStep     bool   `json:"retrieval_step"`
-	Kind              string `json:"kind"`
-}
-
-// Secret -
-type Secret struct {
-	ID                string `json:"url_token"`
-	ExpireAfterDays   int    `json:"expire_after_days"`
-	ExpireAfterViews  int    `json:"expire_after_views"`
-	Expired           bool   `json:"expired"`
-	CreatedAt         string `json:"created_at"`
-	UpdatedAt         string `json:"updated_at"`
-	Deleted           bool   `json:"deleted"`
-	DeletableByViewer bool   `json:"deletable_by_viewer"`
-	RetrievalStep     bool   `json:"retrieval_step"`
-	ExpiredAt         string `json:"expired_on"`
-	DaysRemaining     int    `json:"days_remaining"`
-	ViewsRemaining    int    `json:"views_remaining"`
-}
-
 // TextResourceModel describes the resource data model.
 type TextResourceModel struct {
-	Id                types.String `tfsdk:"id"`
-	Password          types.String `tfsdk:"password"`
-	Passphrase        *string      `tfsdk:"passphrase"`
-	ExpireAfterDays   types.Int32  `tfsdk:"expire_after_days"`
-	ExpireAfterViews  types.Int32  `tfsdk:"expire_after_views"`
-	Expired           types.Bool   `tfsdk:"expired"`
-	CreatedAt         types.String `tfsdk:"created_at"`
-	UpdatedAt         types.String `tfsdk:"updated_at"`
-	Deleted           types.Bool   `tfsdk:"deleted"`
-	DeletableByViewer types.Bool   `tfsdk:"deletable_by_viewer"`
-	RetrievalStep     types.Bool   `tfsdk:"retrieval_step"`
-	ExpiredAt         types.String `tfsdk:"expired_on"`
-	DaysRemaining     types.Int32  `tfsdk:"days_remaining"`
-	ViewsRemaining    types.Int32  `tfsdk:"views_remaining"`
+	Id                types.String  `tfsdk:"id"`
+	Password          types.String  `tfsdk:"password"`
+	Passphrase        *string       `tfsdk:"passphrase"`
+	ExpireAfterDays   types.Int32   `tfsdk:"expire_after_days"`
+	ExpireAfterViews  types.Int32   `tfsdk:"expire_after_views"`
+	Expired           types.Bool    `tfsdk:"expired"`
+	CreatedAt         types.String  `tfsdk:"created_at"`
+	UpdatedAt         types.String  `tfsdk:"updated_at"`
+	Deleted           types.Bool    `tfsdk:"deleted"`
+	DeletableByViewer types.Bool    `tfsdk:"deletable_by_viewer"`
+	RetrievalStep     types.Bool    `tfsdk:"retrieval_step"`
+	ExpiredAt         types.String  `tfsdk:"expired_on"`
+	DaysRemaining     types.Int32   `tfsdk:"days_remaining"`
+	ViewsRemaining    types.Int32   `tfsdk:"views_remaining"`
+	Endpoint          types.String  `tfsdk:"endpoint"`
+	AccountId         types.String  `tfsdk:"account_id"`
+	Locale            types.String  `tfsdk:"locale"`
+	Name              types.String  `tfsdk:"name"`
+	ExportPath        types.String  `tfsdk:"export_path"`
+	DedupeWindow      types.String  `tfsdk:"dedupe_window"`
+	DedupeKey         types.String  `tfsdk:"dedupe_key"`
+	Url               types.String  `tfsdk:"url"`
+	Retries           *RetriesModel `tfsdk:"retries"`
 }
 
 func (r *TextResource) Metadata(ctx context.Context, req resource.MetadataRequest, resp *resource.MetadataResponse) {
@@ -82,7 +72,7 @@ func (r *TextResource) Metadata(ctx context.Context, req resource.MetadataReques
 func (r *TextResource) Schema(ctx context.Context, req resource.SchemaRequest, resp *resource.SchemaResponse) {
 	resp.Schema = schema.Schema{
 		// This description is used by the documentation generator and the language server.
-		MarkdownDescription: "The Text resource that will get pushed to the secret server",
+		MarkdownDescription: "The Text resource that will get pushed to the secret server. Destroying it expires the push, which the service refuses for anonymous pushes not deletable by the viewer, left to expire on their own",
 
 		Attributes: map[string]schema.Attribute{
 			"password": schema.StringAttribute{
@@ -91,7 +81,7 @@ func (r *TextResource) Schema(ctx context.Context, req resource.SchemaRequest, r
 				Sensitive:           true,
 			},
 			"passphrase": schema.StringAttribute{
-				MarkdownDescription: "Require recipients to enter this passphrase to view the created item",
+				MarkdownDescription: "Require recipients to enter this passphrase to view the created item. Defaults to the `default_passphrase` of the provider",
 				Optional:            true,
 				Sensitive:           true,
 			},
@@ -150,6 +140,92 @@ func (r *TextResource) Schema(ctx context.Context, req resource.SchemaRequest, r
 				Computed:            true,
 				MarkdownDescription: "The number of times that the secret can be viewed",
 			},
+			"endpoint": schema.StringAttribute{
+				Optional:            true,
+				MarkdownDescription: "The URL of the pwpusher service to push the secret to instead of the one of the provider. The push is anonymous, the credentials, cookies and headers of the provider are only sent to its own service",
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.RequiresReplace(),
+				},
+			},
+			"locale": schema.StringAttribute{
+				Optional:            true,
+				MarkdownDescription: "The locale of the app in `url`, one of the codes of the `pwpusher_locales` data source. Defaults to the `default_locale` of the provider",
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.RequiresReplace(),
+				},
+			},
+			"name": schema.StringAttribute{
+				Optional:            true,
+				MarkdownDescription: "The name of the push in the dashboard of the account, after the `name_prefix` of the provider. Only shown for authenticated pushes, the legacy API does not support names",
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.RequiresReplace(),
+				},
+			},
+			"export_path": schema.StringAttribute{
+				Optional:            true,
+				MarkdownDescription: "A file to write the URL, expiration settings and viewing instructions of the push to once it is created, for pipelines to attach to tickets or release notes. Written as Markdown when the path ends with `.md` and as JSON otherwise. Changing it moves the file without replacing the push, and the file is removed when the push is destroyed unless it holds the details of another push by then",
+			},
+			"dedupe_window": schema.StringAttribute{
+				Optional:            true,
+				MarkdownDescription: "Adopt the most recent active push of the dashboard created with the same `dedupe_key`, payload and settings less than this duration ago, such as `15m`, instead of creating another one, so that applying again after a partial failure does not push the secret twice. Requires the provider to authenticate with the service of the push, and `dedupe_key`. The pushes record a keyed fingerprint of their key, payload and settings in their note to be found by. Defaults to creating a new push",
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.RequiresReplace(),
+				},
+			},
+			"dedupe_key": schema.StringAttribute{
+				Optional:            true,
+				MarkdownDescription: "Tells the pushes of the resource apart from the identical pushes of other resources for `dedupe_window`, which would otherwise adopt each other's push. Use a value unique among the resources of the user, such as the address of the resource, `module.app.pwpusher_text.db`, which providers are not told. Required with `dedupe_window`",
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.RequiresReplace(),
+				},
+			},
+			"url": schema.StringAttribute{
+				Computed:            true,
+				MarkdownDescription: "The URL recipients open to view the secret",
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.UseStateForUnknown(),
+				},
+			},
+			"account_id": schema.StringAttribute{
+				Optional:            true,
+				MarkdownDescription: "The ID of the pwpush.com Pro account to create the push in instead of the one of the provider. Requires the provider to authenticate with the service of the push",
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.RequiresReplace(),
+				},
+			},
+		},
+
+		Blocks: map[string]schema.Block{
+			"retries": schema.SingleNestedBlock{
+				MarkdownDescription: "Overrides the `retries` settings of the provider for the requests of this resource, for example to disable retries of a large payload",
+				Attributes: map[string]schema.Attribute{
+					"max_attempts": schema.Int64Attribute{
+						MarkdownDescription: "The maximum number of attempts of a request, including the first one. `1` disables retries",
+						Optional:            true,
+					},
+					"min_backoff": schema.StringAttribute{
+						MarkdownDescription: "The backoff after the first failed attempt, doubled after each following one",
+						Optional:            true,
+					},
+					"max_backoff": schema.StringAttribute{
+						MarkdownDescription: "The maximum backoff between two attempts",
+						Optional:            true,
+					},
+					"retry_on": schema.ListAttribute{
+						MarkdownDescription: "The response status codes to retry",
+						ElementType:         types.Int64Type,
+						Optional:            true,
+					},
+					"max_retry_after": schema.StringAttribute{
+						MarkdownDescription: "The longest delay requested by the `Retry-After` header of a rate limited response to wait for before retrying",
+						Optional:            true,
+					},
+					"maintenance_timeout": schema.StringAttribute{
+						MarkdownDescription: "How long `503` responses are retried for regardless of `max_attempts`",
+						Optional:            true,
+					},
+				},
+			},
 		},
 	}
 }
@@ -174,45 +250,236 @@ func (r *TextResource) Configure(ctx context.Context, req resource.ConfigureRequ
 	r.providerData = providerData
 }
 
+func (r *TextResource) ModifyPlan(ctx context.Context, req resource.ModifyPlanRequest, resp *resource.ModifyPlanResponse) {
+	// Nothing to check when the push is destroyed.
+	if req.Plan.Raw.IsNull() {
+		return
+	}
+
+	var data TextResourceModel
+
+	// The configuration tells unset attributes, which the push inherits
+	// defaults for, from computed ones
+	resp.Diagnostics.Append(req.Config.Get(ctx, &data)...)
+
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	resp.Diagnostics.Append(r.providerData.policy.checkPush(data, r.providerData)...)
+}
+
 func (r *TextResource) Create(ctx context.Context, req resource.CreateRequest, resp *resource.CreateResponse) {
 	var data TextResourceModel
 
 	// Read Terraform plan data into the model
 	resp.Diagnostics.Append(req.Plan.Get(ctx, &data)...)
 
-	payload := SecretPayload{
+	start := time.Now()
+	ctx, op := r.providerData.operationContext(ctx, logSubsystemTextResource, "pwpusher_text create")
+	defer op.end(&resp.Diagnostics)
+	defer recoverPanic(&resp.Diagnostics)
+
+	payload := client.Payload{
 		Password:   data.Password.ValueString(),
 		Passphrase: data.Passphrase,
-		// ExpireAfterDays:   data.ExpireAfterDays.ValueInt32Pointer(),
-		// ExpireAfterViews:  int(data.ExpireAfterViews.ValueInt32()),
+		// Unset expiration settings are unknown, zero for the defaults of
+		// the service.
+		ExpireAfterDays:   int(data.ExpireAfterDays.ValueInt32()),
+		ExpireAfterViews:  int(data.ExpireAfterViews.ValueInt32()),
 		DeletableByViewer: data.DeletableByViewer.ValueBool(),
 		RetrievalStep:     data.RetrievalStep.ValueBool(),
 		Kind:              "text",
 	}
-	payloadBytes, err := json.Marshal(payload)
-	if err != nil {
+	providerData := r.providerData
+	if !data.Endpoint.IsNull() {
+		var diags diag.Diagnostics
+		providerData, diags = providerData.withEndpoint(path.Root("endpoint"), data.Endpoint.ValueString())
+		resp.Diagnostics.Append(diags...)
+	}
+	if !data.AccountId.IsNull() {
+		if providerData.email == "" {
+			resp.Diagnostics.AddAttributeError(
+				path.Root("account_id"),
+				"Missing Credentials",
+				"The account_id attribute requires the provider to authenticate with the pwpusher service of the push, anonymous pushes do not belong to an account.",
+			)
+		}
+		providerData.accountID = data.AccountId.ValueString()
+	}
+	payload.AccountID = providerData.accountID
+	payload.Name = providerData.pushName(data.Name.ValueString())
+	// Notes are only shown to the owner of the push.
+	note, diags := moduleNote(ctx, req.ProviderMeta)
+	resp.Diagnostics.Append(diags...)
+	if providerData.email != "" {
+		payload.Note = note
+	}
+	locale := stringValueOrDefault(data.Locale, providerData.defaultLocale)
+	if !data.Locale.IsNull() {
+		locales, _, err := providerData.serverLocales(ctx)
+		if err != nil {
+			resp.Diagnostics.AddError("Client Error", fmt.Sprintf("Unable to read the locales of the pwpusher service, got error: %s", err))
+			return
+		}
+		resp.Diagnostics.Append(checkLocale(path.Root("locale"), locale, locales)...)
+	}
+	if payload.Passphrase == nil {
+		payload.Passphrase = providerData.defaultPassphrase
+	}
+	// No diagnostic or log of the push may show its secrets, whatever the
+	// service or a dependency puts in their messages.
+	ctx = op.withSecrets(ctx, payload.Password, types.StringPointerValue(payload.Passphrase).ValueString())
+	resp.Diagnostics.Append(providerData.policy.checkPayload(payload.Password)...)
+	retries, diags := newRetryPolicy(ctx, providerData.retries, data.Retries)
+	resp.Diagnostics.Append(diags...)
+	var dedupeWindow time.Duration
+	if !data.DedupeWindow.IsNull() {
+		window, err := time.ParseDuration(data.DedupeWindow.ValueString())
+		if err != nil {
+			resp.Diagnostics.AddAttributeError(path.Root("dedupe_window"), "Invalid Duration", err.Error())
+		} else if window <= 0 {
+			resp.Diagnostics.AddAttributeError(path.Root("dedupe_window"), "Invalid Duration", "The dedupe_window attribute must be a positive duration.")
+		}
+		if providerData.email == "" {
+			resp.Diagnostics.AddAttributeError(
+				path.Root("dedupe_window"),
+				"Missing Credentials",
+				"The dedupe_window attribute requires the provider to authenticate with the pwpusher service of the push, anonymous pushes are on no dashboard to be found in.",
+			)
+		}
+		if data.DedupeKey.ValueString() == "" {
+			resp.Diagnostics.AddAttributeError(
+				path.Root("dedupe_key"),
+				"Missing Dedupe Key",
+				"The dedupe_window attribute requires a dedupe_key unique to the resource, such as its address, so that resources with the same payload do not adopt each other's push.",
+			)
+		}
+		dedupeWindow = window
+	}
+	if resp.Diagnostics.HasError() {
 		return
 	}
+	ctx = withRetryPolicy(ctx, retries)
 
-	res, err := r.providerData.client.Post(r.providerData.url.ValueString()+"/p.json", "application/json", bytes.NewReader(payloadBytes))
+	api, err := providerData.compatibility(ctx)
 	if err != nil {
+		resp.Diagnostics.AddError("Client Error", fmt.Sprintf("Unable to detect the API of the pwpusher service, got error: %s", err))
+		return
+	}
+	_, err = api.EncodePush(payload)
+	if errors.Is(err, client.ErrPassphraseUnsupported) {
+		resp.Diagnostics.AddAttributeError(
+			path.Root("passphrase"),
+			"Unsupported Passphrase",
+			fmt.Sprintf("The pwpusher service at %s uses the legacy API, whose releases do not support passphrases.", providerData.url.ValueString()),
+		)
+		return
+	}
+	if errors.Is(err, client.ErrAccountUnsupported) {
+		resp.Diagnostics.AddAttributeError(
+			path.Root("account_id"),
+			"Unsupported Account",
+			fmt.Sprintf("The pwpusher service at %s uses the legacy API, whose releases do not support accounts.", providerData.url.ValueString()),
+		)
 		return
 	}
-
-	newSecret := Secret{}
-	body, err := io.ReadAll(res.Body)
-	err = json.Unmarshal(body, &newSecret)
 	if err != nil {
+		resp.Diagnostics.AddError("Client Error", fmt.Sprintf("Unable to encode the push, got error: %s", err))
+		return
+	}
+	if dedupeWindow > 0 && api.LegacyPayload {
+		resp.Diagnostics.AddAttributeError(
+			path.Root("dedupe_window"),
+			"Unsupported Dedupe Window",
+			fmt.Sprintf("The pwpusher service at %s uses the legacy API, whose releases do not support notes to find pushes by.", providerData.url.ValueString()),
+		)
 		return
 	}
 
-	bodyBytes, err := io.ReadAll(res.Body)
-	bodyString := string(bodyBytes)
-	tflog.Trace(ctx, bodyString)
+	newSecret := client.Push{}
+	if providerData.dryRun {
+		defaults, err := providerData.serverPushDefaults(ctx)
+		if err != nil {
+			resp.Diagnostics.AddError("Client Error", fmt.Sprintf("Unable to read the push defaults of the pwpusher service, got error: %s", err))
+			return
+		}
+		if validation := validatePayload(payload.Password, defaults.maxPayloadBytes); !validation.Valid {
+			resp.Diagnostics.AddAttributeError(path.Root("password"), "Invalid Payload", validation.Message+".")
+			return
+		}
+		newSecret, err = dryRunSecret(payload, defaults)
+		if err != nil {
+			resp.Diagnostics.AddError("Client Error", fmt.Sprintf("Unable to simulate the push, got error: %s", err))
+			return
+		}
+		resp.Diagnostics.AddWarning(
+			"Dry Run",
+			"The provider is configured with dry_run, so the push was not created and its URL does not work. Set dry_run to false and replace the resource to create it.",
+		)
+	} else {
+		pushClient := providerData.apiClient()
+		adopted := false
+		if dedupeWindow > 0 {
+			fingerprint, err := pushFingerprint(providerData.fingerprintKey, data.DedupeKey.ValueString(), payload)
+			if err != nil {
+				resp.Diagnostics.AddError("Client Error", fmt.Sprintf("Unable to fingerprint the push, got error: %s", err))
+				return
+			}
+			newSecret, adopted, err = findRecentPush(ctx, pushClient, fingerprint, dedupeWindow, time.Now())
+			if err != nil {
+				resp.Diagnostics.AddError("Client Error", fmt.Sprintf("Unable to look for a recent identical push, got error: %s", err))
+				return
+			}
+			payload.Note = noteWithMarker(payload.Note, fingerprint)
+		}
+		// The dashboard of the owner of the push tells whether an attempt
+		// that failed created it anyway. The legacy API has no notes to
+		// find it by, and the fake service never fails.
+		if !adopted && providerData.email != "" && !api.LegacyPayload && !providerData.fake {
+			marker, err := newCreateMarker()
+			if err != nil {
+				resp.Diagnostics.AddError("Client Error", fmt.Sprintf("Unable to generate the marker of the push, got error: %s", err))
+				return
+			}
+			payload.Note = noteWithMarker(payload.Note, marker)
+			ctx = withPushLookup(ctx, dashboardLookup(pushClient, marker))
+		}
+		if adopted {
+			tflog.SubsystemInfo(ctx, logSubsystemTextResource, "Adopted a recent identical push instead of creating one", map[string]interface{}{
+				"token":      newSecret.ID,
+				"created_at": newSecret.CreatedAt,
+			})
+		} else {
+			newSecret, err = pushClient.CreatePush(ctx, api, payload)
+			if err != nil {
+				resp.Diagnostics.Append(createError(ctx, err)...)
+				return
+			}
+		}
+	}
+
+	// The defaults of the service may be larger than those of the app the
+	// policy was checked with at plan time.
+	if diags := providerData.policy.checkCreated(newSecret); diags.HasError() {
+		resp.Diagnostics.Append(diags...)
+		if !providerData.dryRun {
+			if err := providerData.apiClient().ExpirePush(ctx, newSecret.ID); err != nil {
+				resp.Diagnostics.AddError("Client Error", fmt.Sprintf("Unable to expire the push breaking the policy of the provider, got error: %s", err))
+			}
+		}
+		return
+	}
 
 	// For the purposes of this example code, hardcoding a response value to
 	// save into the Terraform state.
 	data.Id = types.StringValue(newSecret.ID)
+	pushURL, err := providerData.apiClient().PushURL(newSecret.ID, locale)
+	if err != nil {
+		resp.Diagnostics.AddError("Client Error", fmt.Sprintf("Unable to build the URL of the push, got error: %s", err))
+		return
+	}
+	data.Url = types.StringValue(pushURL)
 	data.ExpireAfterDays = types.Int32Value(int32(newSecret.ExpireAfterDays))
 	data.ExpireAfterViews = types.Int32Value(int32(newSecret.ExpireAfterViews))
 	data.Expired = types.BoolValue(newSecret.Expired)
@@ -225,9 +492,25 @@ func (r *TextResource) Create(ctx context.Context, req resource.CreateRequest, r
 	data.DaysRemaining = types.Int32Value(int32(newSecret.DaysRemaining))
 	data.ViewsRemaining = types.Int32Value(int32(newSecret.ViewsRemaining))
 
-	// Write logs using the tflog package
-	// Documentation: https://terraform.io/plugin/log\
-	tflog.Trace(ctx, "created a resource")
+	if err := providerData.auditLog.write(textAuditRecord(auditActionCreate, data, providerData)); err != nil {
+		resp.Diagnostics.AddError("Audit Log Error", fmt.Sprintf("The push was created, but unable to record it in the audit log, got error: %s", err))
+	}
+	if !data.ExportPath.IsNull() {
+		export := newPushExport(data, payload.Passphrase != nil)
+		if err := export.write(data.ExportPath.ValueString()); err != nil {
+			resp.Diagnostics.AddAttributeError(
+				path.Root("export_path"),
+				"Export Error",
+				fmt.Sprintf("The push was created, but unable to write its details to %s, got error: %s", data.ExportPath.ValueString(), err),
+			)
+		}
+	}
+
+	tflog.SubsystemDebug(ctx, logSubsystemTextResource, "Created push", map[string]interface{}{
+		"token":       data.Id.ValueString(),
+		"dry_run":     providerData.dryRun,
+		"duration_ms": time.Since(start).Milliseconds(),
+	})
 
 	// Save data into Terraform state
 	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
@@ -248,15 +531,45 @@ func (r *TextResource) Read(ctx context.Context, req resource.ReadRequest, resp
 }
 
 func (r *TextResource) Update(ctx context.Context, req resource.UpdateRequest, resp *resource.UpdateResponse) {
-	var data TextResourceModel
+	var plan, data TextResourceModel
 
-	// Read Terraform plan data into the model
-	resp.Diagnostics.Append(req.Plan.Get(ctx, &data)...)
+	// Read Terraform plan and prior state data into the models
+	resp.Diagnostics.Append(req.Plan.Get(ctx, &plan)...)
+	resp.Diagnostics.Append(req.State.Get(ctx, &data)...)
 
-	resp.Diagnostics.AddError("Client Error", fmt.Sprintf("Unable to update entry, not a permitted action"))
 	if resp.Diagnostics.HasError() {
 		return
 	}
+
+	// The service cannot change a push, only where its details are exported
+	// to can be.
+	if !exportPathChangeOnly(plan, data) {
+		resp.Diagnostics.AddError("Client Error", fmt.Sprintf("Unable to update entry, not a permitted action"))
+		return
+	}
+	oldPath := data.ExportPath
+	data.ExportPath = plan.ExportPath
+	if !data.ExportPath.IsNull() {
+		export := newPushExport(data, data.Passphrase != nil || r.providerData.defaultPassphrase != nil)
+		if err := export.write(data.ExportPath.ValueString()); err != nil {
+			resp.Diagnostics.AddAttributeError(
+				path.Root("export_path"),
+				"Export Error",
+				fmt.Sprintf("Unable to write the details of the push to %s, got error: %s", data.ExportPath.ValueString(), err),
+			)
+			return
+		}
+	}
+	if !oldPath.IsNull() && oldPath.ValueString() != data.ExportPath.ValueString() {
+		if err := removeExport(oldPath.ValueString(), data.Url.ValueString()); err != nil {
+			resp.Diagnostics.AddAttributeWarning(
+				path.Root("export_path"),
+				"Export Error",
+				fmt.Sprintf("The details of the push were moved, but unable to remove them from %s, got error: %s", oldPath.ValueString(), err),
+			)
+		}
+	}
+
 	// Save updated data into Terraform state
 	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
 }
@@ -270,6 +583,162 @@ func (r *TextResource) Delete(ctx context.Context, req resource.DeleteRequest, r
 	if resp.Diagnostics.HasError() {
 		return
 	}
+
+	// The endpoint was checked when the push was created, a provider
+	// configuration rejecting it since must not keep it from being
+	// destroyed.
+	providerData := r.providerData
+	if !data.Endpoint.IsNull() {
+		providerData, _ = providerData.withEndpoint(path.Root("endpoint"), data.Endpoint.ValueString())
+	}
+	ctx, op := providerData.operationContext(ctx, logSubsystemTextResource, "pwpusher_text delete")
+	defer op.end(&resp.Diagnostics)
+
+	token := data.Id.ValueString()
+	// Simulated pushes were never created, there is nothing to expire.
+	dryRun := strings.HasPrefix(token, dryRunTokenPrefix)
+	var err error
+	if !dryRun {
+		err = providerData.apiClient().ExpirePush(ctx, token)
+	}
+	switch {
+	case errors.Is(err, client.ErrNotFound):
+		// The service already deleted the push, it was not expired now.
+	case errors.Is(err, client.ErrUnauthorized):
+		resp.Diagnostics.AddWarning(
+			"Push Not Expired",
+			fmt.Sprintf("The pwpusher service refused to expire the push, which only its owner can do unless it is deletable by the viewer, so it stays viewable until it expires on its own. Got error: %s", err),
+		)
+	case err != nil:
+		resp.Diagnostics.AddError("Client Error", fmt.Sprintf("Unable to expire the push, got error: %s", err))
+		return
+	default:
+		record := textAuditRecord(auditActionExpire, data, providerData)
+		record.DryRun = dryRun
+		if err := providerData.auditLog.write(record); err != nil {
+			resp.Diagnostics.AddError("Audit Log Error", fmt.Sprintf("The push was expired, but unable to record it in the audit log, got error: %s", err))
+		}
+	}
+	if !data.ExportPath.IsNull() {
+		if err := removeExport(data.ExportPath.ValueString(), data.Url.ValueString()); err != nil {
+			resp.Diagnostics.AddAttributeError(
+				path.Root("export_path"),
+				"Export Error",
+				fmt.Sprintf("Unable to remove the details of the push from %s, got error: %s", data.ExportPath.ValueString(), err),
+			)
+		}
+	}
+}
+
+// exportPathChangeOnly returns whether plan only changes the export_path of
+// the push in state. Computed attributes left to the service are unknown in
+// the plan of any change.
+func exportPathChangeOnly(plan, state TextResourceModel) bool {
+	passphrase := types.StringPointerValue(plan.Passphrase).Equal(types.StringPointerValue(state.Passphrase))
+	changed := func(planned, prior attr.Value) bool {
+		return !planned.IsUnknown() && !planned.Equal(prior)
+	}
+	return passphrase &&
+		plan.Password.Equal(state.Password) &&
+		!changed(plan.ExpireAfterDays, state.ExpireAfterDays) &&
+		!changed(plan.ExpireAfterViews, state.ExpireAfterViews) &&
+		!changed(plan.DeletableByViewer, state.DeletableByViewer) &&
+		!changed(plan.RetrievalStep, state.RetrievalStep) &&
+		reflect.DeepEqual(plan.Retries, state.Retries)
+}
+
+// createError returns the diagnostics of a push that failed with err. A push
+// stopped by Terraform before the service answered may still have been
+// created, which the operator needs to know about.
+func createError(ctx context.Context, err error) diag.Diagnostics {
+	var diags diag.Diagnostics
+	if ctx.Err() != nil {
+		diags.AddError(
+			"Push Cancelled",
+			fmt.Sprintf("The push was stopped before the pwpusher service answered, so it may have been created without being recorded in the state. Expire it in the dashboard if it was. Got error: %s", err),
+		)
+		return diags
+	}
+	if errors.Is(err, errCreateUncertain) {
+		diags.AddError(
+			"Push May Exist",
+			fmt.Sprintf("An attempt to push failed after the pwpusher service may have created the push, and the provider could not tell whether it did, so it was not retried. Expire it in the dashboard if it was. Got error: %s", err),
+		)
+		return diags
+	}
+	var validation *client.ValidationError
+	if errors.As(err, &validation) {
+		fields := make([]string, 0, len(validation.Fields))
+		for field := range validation.Fields {
+			fields = append(fields, field)
+		}
+		sort.Strings(fields)
+		for _, field := range fields {
+			detail := fmt.Sprintf("The pwpusher service rejected the %s of the push: %s", field, strings.Join(validation.Fields[field], ", "))
+			if attribute, ok := pushFieldAttributes[field]; ok {
+				diags.AddAttributeError(path.Root(attribute), "Invalid Push", detail)
+			} else {
+				diags.AddError("Invalid Push", detail)
+			}
+		}
+		return diags
+	}
+	if errors.Is(err, client.ErrCircuitOpen) {
+		diags.AddError(
+			"Service Unavailable",
+			fmt.Sprintf("The push was not sent because the previous requests to the pwpusher service kept failing: %s", err),
+		)
+		return diags
+	}
+	if errors.Is(err, client.ErrRateLimited) {
+		diags.AddError(
+			"Rate Limited",
+			fmt.Sprintf("The pwpusher service kept refusing the push for exceeding its rate limit. Configure more retries with a longer backoff, or try again later. Got error: %s", err),
+		)
+		return diags
+	}
+	diags.AddError("Client Error", fmt.Sprintf("Unable to create the push, got error: %s", err))
+	return diags
+}
+
+// pushFieldAttributes maps the fields of new pushes in the API of the
+// service to the attributes of the resource setting them.
+var pushFieldAttributes = map[string]string{
+	"payload":             "password",
+	"passphrase":          "passphrase",
+	"expire_after_days":   "expire_after_days",
+	"expire_after_views":  "expire_after_views",
+	"deletable_by_viewer": "deletable_by_viewer",
+	"retrieval_step":      "retrieval_step",
+	"account_id":          "account_id",
+	"name":                "name",
+}
+
+// pushName returns the name of a push named name, prefixed when it is
+// authenticated.
+func (d ProviderData) pushName(name string) string {
+	if d.email == "" {
+		return name
+	}
+	return d.namePrefix + name
+}
+
+// textAuditRecord returns the audit log record of action on the push of data,
+// created with providerData.
+func textAuditRecord(action string, data TextResourceModel, providerData ProviderData) auditRecord {
+	return auditRecord{
+		Time:             time.Now().UTC(),
+		Action:           action,
+		ResourceType:     "pwpusher_text",
+		ServiceURL:       providerData.url.ValueString(),
+		Token:            data.Id.ValueString(),
+		Name:             providerData.pushName(data.Name.ValueString()),
+		ExpireAfterDays:  data.ExpireAfterDays.ValueInt32(),
+		ExpireAfterViews: data.ExpireAfterViews.ValueInt32(),
+		RetrievalStep:    data.RetrievalStep.ValueBool(),
+		Passphrase:       data.Passphrase != nil || providerData.defaultPassphrase != nil,
+		DryRun:           providerData.dryRun,
+	}
 }
 
 func (r *TextResource) ImportState(ctx context.Context, req resource.ImportStateRequest, resp *resource.ImportStateResponse) {
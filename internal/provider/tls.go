@@ -0,0 +1,325 @@
+// Copyright (c) Plex, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package provider
+
+import (
+	"context"
+	"crypto/sha256"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"os"
+	"slices"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+// spkiPinPrefix prefixes the public key pins of tls_pinned_public_keys, as
+// in the pins of HTTP Public Key Pinning.
+const spkiPinPrefix = "sha256/"
+
+// tlsKeylogs are the key log files of the process by path, so that the
+// provider instances of a process configured with the same file share it.
+var tlsKeylogs = struct {
+	mu    sync.Mutex
+	files map[string]*os.File
+}{files: map[string]*os.File{}}
+
+// openTLSKeylog returns the key log file at path, opening it for appending
+// unless a provider instance of the process already did. The file stays open
+// for the connections of the provider process.
+func openTLSKeylog(path string) (*os.File, error) {
+	tlsKeylogs.mu.Lock()
+	defer tlsKeylogs.mu.Unlock()
+	if file, ok := tlsKeylogs.files[path]; ok {
+		return file, nil
+	}
+	file, err := os.OpenFile(path, os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0o600)
+	if err != nil {
+		return nil, err
+	}
+	tlsKeylogs.files[path] = file
+	return file, nil
+}
+
+// fipsCipherSuites are the TLS 1.2 cipher suites approved by FIPS 140-3.
+var fipsCipherSuites = []uint16{
+	tls.TLS_ECDHE_ECDSA_WITH_AES_128_GCM_SHA256,
+	tls.TLS_ECDHE_ECDSA_WITH_AES_256_GCM_SHA384,
+	tls.TLS_ECDHE_RSA_WITH_AES_128_GCM_SHA256,
+	tls.TLS_ECDHE_RSA_WITH_AES_256_GCM_SHA384,
+}
+
+// tlsVersions maps the names accepted by tls_min_version to TLS versions.
+var tlsVersions = map[string]uint16{
+	"1.2": tls.VersionTLS12,
+	"1.3": tls.VersionTLS13,
+}
+
+// newTLSConfig returns the TLS configuration of connections to the service.
+func newTLSConfig(ctx context.Context, data PwPusherProviderModel) (*tls.Config, diag.Diagnostics) {
+	var diags diag.Diagnostics
+	config := &tls.Config{MinVersion: tls.VersionTLS12}
+
+	if !data.TlsMinVersion.IsNull() {
+		version, ok := tlsVersions[data.TlsMinVersion.ValueString()]
+		if !ok {
+			diags.AddAttributeError(
+				path.Root("tls_min_version"),
+				"Invalid TLS Version",
+				fmt.Sprintf("The tls_min_version attribute must be one of %s, got %q.", tlsVersionNames(), data.TlsMinVersion.ValueString()),
+			)
+		}
+		config.MinVersion = version
+	}
+
+	var cipherSuites []string
+	if !data.TlsCipherSuites.IsNull() {
+		diags.Append(data.TlsCipherSuites.ElementsAs(ctx, &cipherSuites, false)...)
+	}
+	for i, name := range cipherSuites {
+		id, ok := cipherSuiteID(name)
+		if !ok {
+			diags.AddAttributeError(
+				path.Root("tls_cipher_suites").AtListIndex(i),
+				"Invalid Cipher Suite",
+				fmt.Sprintf("%q is not a secure TLS 1.2 cipher suite supported by the provider.", name),
+			)
+			continue
+		}
+		config.CipherSuites = append(config.CipherSuites, id)
+	}
+
+	if data.StrictTls.ValueBool() {
+		diags.Append(applyStrictTLS(config, data)...)
+	}
+
+	config.InsecureSkipVerify = data.InsecureSkipTlsVerify.ValueBool()
+
+	if !data.TlsKeylogFile.IsNull() && !diags.HasError() {
+		keylog, err := openTLSKeylog(data.TlsKeylogFile.ValueString())
+		if err != nil {
+			diags.AddAttributeError(
+				path.Root("tls_keylog_file"),
+				"Unable to Open File",
+				fmt.Sprintf("Unable to open %s, got error: %s", data.TlsKeylogFile.ValueString(), err),
+			)
+		} else {
+			config.KeyLogWriter = keylog
+		}
+		diags.AddAttributeWarning(
+			path.Root("tls_keylog_file"),
+			"Insecure TLS Configuration",
+			fmt.Sprintf("The TLS session keys of connections to the pwpusher service are written to %s, so anyone with the file and a capture of the traffic can read the secrets sent to it. Only use tls_keylog_file to debug connections, and delete the file once done.", data.TlsKeylogFile.ValueString()),
+		)
+	}
+
+	var pins []string
+	if !data.TlsPinnedPublicKeys.IsNull() {
+		diags.Append(data.TlsPinnedPublicKeys.ElementsAs(ctx, &pins, false)...)
+	}
+	pinned := map[[sha256.Size]byte]bool{}
+	for i, pin := range pins {
+		digest, err := base64.StdEncoding.DecodeString(strings.TrimPrefix(pin, spkiPinPrefix))
+		if !strings.HasPrefix(pin, spkiPinPrefix) || err != nil || len(digest) != sha256.Size {
+			diags.AddAttributeError(
+				path.Root("tls_pinned_public_keys").AtListIndex(i),
+				"Invalid Public Key Pin",
+				fmt.Sprintf("%q is not a pin of the form %s followed by a base64 encoded SHA-256 digest.", pin, spkiPinPrefix),
+			)
+			continue
+		}
+		pinned[[sha256.Size]byte(digest)] = true
+	}
+	switch {
+	case len(pinned) > 0 && config.InsecureSkipVerify:
+		// Without a verified chain only the key of the certificate of the
+		// server, which the handshake proves it holds, can be trusted, as
+		// for the self-signed certificate of a lab service.
+		config.VerifyConnection = func(state tls.ConnectionState) error {
+			if len(state.PeerCertificates) == 0 || !pinned[sha256.Sum256(state.PeerCertificates[0].RawSubjectPublicKeyInfo)] {
+				return errors.New("the certificate of the server does not have a pinned public key")
+			}
+			return nil
+		}
+	case config.InsecureSkipVerify:
+		diags.AddAttributeWarning(
+			path.Root("insecure_skip_tls_verify"),
+			"Insecure TLS Configuration",
+			"The certificate of the pwpusher service is not verified, so anyone on the network path can read the secrets sent to it. Only use insecure_skip_tls_verify in lab environments, or pin the public key of the service with tls_pinned_public_keys.",
+		)
+	case len(pinned) > 0:
+		config.VerifyConnection = func(state tls.ConnectionState) error {
+			// Only the chains verified up to a trusted root count, the
+			// server may send any other certificate along with its own.
+			if len(state.VerifiedChains) == 0 {
+				return errors.New("the certificate chain of the server was not verified, public key pins cannot be checked")
+			}
+			for _, chain := range state.VerifiedChains {
+				for _, cert := range chain {
+					if pinned[sha256.Sum256(cert.RawSubjectPublicKeyInfo)] {
+						return nil
+					}
+				}
+			}
+			return errors.New("the verified certificate chain of the server does not contain a pinned public key")
+		}
+	}
+
+	caCert, caDiags := readPEM(data.CaCertPem, data.CaCertFile, "ca_cert_pem", "ca_cert_file")
+	diags.Append(caDiags...)
+	if caCert != nil {
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(caCert) {
+			diags.AddAttributeError(
+				path.Root("ca_cert_pem"),
+				"Invalid CA Certificate",
+				"The CA certificate bundle does not contain any PEM encoded certificate.",
+			)
+		}
+		config.RootCAs = pool
+	}
+
+	clientCert, certDiags := readPEM(data.ClientCertPem, data.ClientCertFile, "client_cert_pem", "client_cert_file")
+	diags.Append(certDiags...)
+	clientKey, keyDiags := readPEM(data.ClientKeyPem, data.ClientKeyFile, "client_key_pem", "client_key_file")
+	diags.Append(keyDiags...)
+	if diags.HasError() {
+		return nil, diags
+	}
+	if (clientCert == nil) != (clientKey == nil) {
+		diags.AddAttributeError(
+			path.Root("client_key_pem"),
+			"Incomplete Client Certificate",
+			"A client certificate and its private key must be set together to authenticate with mutual TLS.",
+		)
+		return nil, diags
+	}
+	if clientCert != nil {
+		certificate, err := tls.X509KeyPair(clientCert, clientKey)
+		if err != nil {
+			diags.AddAttributeError(
+				path.Root("client_cert_pem"),
+				"Invalid Client Certificate",
+				fmt.Sprintf("Unable to load the client certificate, got error: %s", err),
+			)
+			return nil, diags
+		}
+		config.Certificates = []tls.Certificate{certificate}
+	}
+
+	return config, diags
+}
+
+// applyStrictTLS restricts config to FIPS approved algorithms and checks that
+// the rest of the configuration does not weaken connections.
+func applyStrictTLS(config *tls.Config, data PwPusherProviderModel) diag.Diagnostics {
+	var diags diag.Diagnostics
+
+	// The cipher suites of TLS 1.3 cannot be restricted and include
+	// ChaCha20-Poly1305, so strict connections use TLS 1.2.
+	if config.MinVersion > tls.VersionTLS12 {
+		diags.AddAttributeError(
+			path.Root("tls_min_version"),
+			"Conflicting TLS Configuration",
+			"The strict_tls mode uses TLS 1.2, so tls_min_version cannot be set to a later version.",
+		)
+	}
+	config.MaxVersion = tls.VersionTLS12
+	config.CurvePreferences = []tls.CurveID{tls.CurveP256, tls.CurveP384, tls.CurveP521}
+
+	if config.CipherSuites == nil {
+		config.CipherSuites = fipsCipherSuites
+	}
+	for i, id := range config.CipherSuites {
+		if !slices.Contains(fipsCipherSuites, id) {
+			diags.AddAttributeError(
+				path.Root("tls_cipher_suites").AtListIndex(i),
+				"Conflicting TLS Configuration",
+				fmt.Sprintf("The strict_tls mode only allows FIPS approved cipher suites, %s is not one.", tls.CipherSuiteName(id)),
+			)
+		}
+	}
+
+	if data.InsecureSkipTlsVerify.ValueBool() {
+		diags.AddAttributeError(
+			path.Root("insecure_skip_tls_verify"),
+			"Conflicting TLS Configuration",
+			"The strict_tls mode cannot be used together with insecure_skip_tls_verify.",
+		)
+	}
+	if !data.TlsKeylogFile.IsNull() {
+		diags.AddAttributeError(
+			path.Root("tls_keylog_file"),
+			"Conflicting TLS Configuration",
+			"The strict_tls mode cannot be used together with tls_keylog_file.",
+		)
+	}
+	if !strings.HasPrefix(data.Url.ValueString(), "https://") {
+		diags.AddAttributeError(
+			path.Root("url"),
+			"Plaintext Connection",
+			fmt.Sprintf("The strict_tls mode requires an https URL, got %q.", data.Url.ValueString()),
+		)
+	}
+
+	return diags
+}
+
+// readPEM returns the PEM data set inline with the pemName attribute or
+// read from the file set with the fileName attribute, or nil when neither is
+// set.
+func readPEM(pemValue, fileValue types.String, pemName, fileName string) ([]byte, diag.Diagnostics) {
+	var diags diag.Diagnostics
+	switch {
+	case !pemValue.IsNull() && !fileValue.IsNull():
+		diags.AddAttributeError(
+			path.Root(fileName),
+			"Conflicting TLS Configuration",
+			fmt.Sprintf("Only one of the %s and %s attributes can be set.", pemName, fileName),
+		)
+	case !pemValue.IsNull():
+		return []byte(pemValue.ValueString()), diags
+	case !fileValue.IsNull():
+		content, err := os.ReadFile(fileValue.ValueString())
+		if err != nil {
+			diags.AddAttributeError(
+				path.Root(fileName),
+				"Unable to Read File",
+				fmt.Sprintf("Unable to read %s, got error: %s", fileValue.ValueString(), err),
+			)
+			return nil, diags
+		}
+		return content, diags
+	}
+	return nil, diags
+}
+
+// cipherSuiteID returns the ID of the secure cipher suite named name.
+func cipherSuiteID(name string) (uint16, bool) {
+	for _, suite := range tls.CipherSuites() {
+		if suite.Name == name {
+			return suite.ID, true
+		}
+	}
+	return 0, false
+}
+
+// tlsVersionNames returns the accepted TLS version names for use in
+// messages.
+func tlsVersionNames() string {
+	names := make([]string, 0, len(tlsVersions))
+	for name := range tlsVersions {
+		names = append(names, "`"+name+"`")
+	}
+	sort.Strings(names)
+	return strings.Join(names, ", ")
+}
@@ -0,0 +1,357 @@
+// Copyright (c) Plex, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package provider
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/base64"
+	"encoding/pem"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-framework/attr"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+func TestNewTLSConfig(t *testing.T) {
+	clientCert, clientKey := testClientCertificate(t)
+	clientCAs := x509.NewCertPool()
+	clientCAs.AppendCertsFromPEM(clientCert)
+
+	server := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	server.TLS = &tls.Config{ClientAuth: tls.RequireAndVerifyClientCert, ClientCAs: clientCAs}
+	server.StartTLS()
+	defer server.Close()
+
+	caCert := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: server.Certificate().Raw})
+	serverPin := sha256.Sum256(server.Certificate().RawSubjectPublicKeyInfo)
+	otherPin := sha256.Sum256([]byte("other key"))
+	caFile := filepath.Join(t.TempDir(), "ca.pem")
+	if err := os.WriteFile(caFile, caCert, 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	for name, test := range map[string]struct {
+		data    PwPusherProviderModel
+		connect bool
+	}{
+		"system trust store": {
+			data: PwPusherProviderModel{
+				ClientCertPem: types.StringValue(string(clientCert)),
+				ClientKeyPem:  types.StringValue(string(clientKey)),
+			},
+		},
+		"no client certificate": {
+			data: PwPusherProviderModel{
+				CaCertPem: types.StringValue(string(caCert)),
+			},
+		},
+		"client certificate": {
+			data: PwPusherProviderModel{
+				CaCertPem:     types.StringValue(string(caCert)),
+				ClientCertPem: types.StringValue(string(clientCert)),
+				ClientKeyPem:  types.StringValue(string(clientKey)),
+			},
+			connect: true,
+		},
+		"insecure skip verify": {
+			data: PwPusherProviderModel{
+				InsecureSkipTlsVerify: types.BoolValue(true),
+				ClientCertPem:         types.StringValue(string(clientCert)),
+				ClientKeyPem:          types.StringValue(string(clientKey)),
+			},
+			connect: true,
+		},
+		"tls 1.3": {
+			data: PwPusherProviderModel{
+				CaCertPem:     types.StringValue(string(caCert)),
+				ClientCertPem: types.StringValue(string(clientCert)),
+				ClientKeyPem:  types.StringValue(string(clientKey)),
+				TlsMinVersion: types.StringValue("1.3"),
+			},
+			connect: true,
+		},
+		"cipher suites": {
+			data: PwPusherProviderModel{
+				CaCertPem:     types.StringValue(string(caCert)),
+				ClientCertPem: types.StringValue(string(clientCert)),
+				ClientKeyPem:  types.StringValue(string(clientKey)),
+				TlsCipherSuites: types.ListValueMust(types.StringType, []attr.Value{
+					types.StringValue("TLS_ECDHE_RSA_WITH_AES_128_GCM_SHA256"),
+				}),
+			},
+			connect: true,
+		},
+		"pinned public key": {
+			data: PwPusherProviderModel{
+				CaCertPem:     types.StringValue(string(caCert)),
+				ClientCertPem: types.StringValue(string(clientCert)),
+				ClientKeyPem:  types.StringValue(string(clientKey)),
+				TlsPinnedPublicKeys: types.ListValueMust(types.StringType, []attr.Value{
+					types.StringValue("sha256/" + base64.StdEncoding.EncodeToString(otherPin[:])),
+					types.StringValue("sha256/" + base64.StdEncoding.EncodeToString(serverPin[:])),
+				}),
+			},
+			connect: true,
+		},
+		"other public key": {
+			data: PwPusherProviderModel{
+				CaCertPem:     types.StringValue(string(caCert)),
+				ClientCertPem: types.StringValue(string(clientCert)),
+				ClientKeyPem:  types.StringValue(string(clientKey)),
+				TlsPinnedPublicKeys: types.ListValueMust(types.StringType, []attr.Value{
+					types.StringValue("sha256/" + base64.StdEncoding.EncodeToString(otherPin[:])),
+				}),
+			},
+		},
+		"strict": {
+			data: PwPusherProviderModel{
+				Url:           types.StringValue(server.URL),
+				CaCertPem:     types.StringValue(string(caCert)),
+				ClientCertPem: types.StringValue(string(clientCert)),
+				ClientKeyPem:  types.StringValue(string(clientKey)),
+				StrictTls:     types.BoolValue(true),
+			},
+			connect: true,
+		},
+		"ca file": {
+			data: PwPusherProviderModel{
+				CaCertFile:    types.StringValue(caFile),
+				ClientCertPem: types.StringValue(string(clientCert)),
+				ClientKeyPem:  types.StringValue(string(clientKey)),
+			},
+			connect: true,
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			config, diags := newTLSConfig(context.Background(), test.data)
+			if diags.HasError() {
+				t.Fatalf("unexpected error: %v", diags)
+			}
+			if warned := diags.WarningsCount() > 0; warned != test.data.InsecureSkipTlsVerify.ValueBool() {
+				t.Errorf("warned: got %t, want %t", warned, test.data.InsecureSkipTlsVerify.ValueBool())
+			}
+			client := &http.Client{Transport: &http.Transport{TLSClientConfig: config}}
+
+			resp, err := client.Get(server.URL)
+			if err == nil {
+				resp.Body.Close()
+			}
+			if connected := err == nil; connected != test.connect {
+				t.Errorf("connected: got %t, want %t (error: %v)", connected, test.connect, err)
+			}
+		})
+	}
+}
+
+func TestNewTLSConfigUnverifiedPin(t *testing.T) {
+	server := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	server.TLS = &tls.Config{}
+	server.StartTLS()
+	defer server.Close()
+
+	// The server sends a certificate of another key after its own, that
+	// is not part of the chain verified up to the CA.
+	otherCert, _ := testClientCertificate(t)
+	block, _ := pem.Decode(otherCert)
+	server.TLS.Certificates[0].Certificate = append(server.TLS.Certificates[0].Certificate, block.Bytes)
+	other, err := x509.ParseCertificate(block.Bytes)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	caCert := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: server.Certificate().Raw})
+	for name, test := range map[string]struct {
+		pinned   *x509.Certificate
+		insecure bool
+		noCA     bool
+		connect  bool
+	}{
+		"server key":     {pinned: server.Certificate(), connect: true},
+		"unverified key": {pinned: other},
+		"insecure skip":  {pinned: server.Certificate(), insecure: true, connect: true},
+		// A self-signed certificate of a lab service, which no CA
+		// vouches for.
+		"insecure self-signed": {pinned: server.Certificate(), insecure: true, noCA: true, connect: true},
+		"insecure unverified":  {pinned: other, insecure: true, noCA: true},
+	} {
+		t.Run(name, func(t *testing.T) {
+			pin := sha256.Sum256(test.pinned.RawSubjectPublicKeyInfo)
+			ca := types.StringValue(string(caCert))
+			if test.noCA {
+				ca = types.StringNull()
+			}
+			config, diags := newTLSConfig(context.Background(), PwPusherProviderModel{
+				CaCertPem:             ca,
+				InsecureSkipTlsVerify: types.BoolValue(test.insecure),
+				TlsPinnedPublicKeys: types.ListValueMust(types.StringType, []attr.Value{
+					types.StringValue("sha256/" + base64.StdEncoding.EncodeToString(pin[:])),
+				}),
+			})
+			if diags.HasError() {
+				t.Fatalf("unexpected error: %v", diags)
+			}
+			client := &http.Client{Transport: &http.Transport{TLSClientConfig: config}}
+
+			resp, err := client.Get(server.URL)
+			if err == nil {
+				resp.Body.Close()
+			}
+			if connected := err == nil; connected != test.connect {
+				t.Errorf("connected: got %t, want %t (error: %v)", connected, test.connect, err)
+			}
+		})
+	}
+}
+
+func TestNewTLSConfigInvalid(t *testing.T) {
+	clientCert, clientKey := testClientCertificate(t)
+
+	for name, data := range map[string]PwPusherProviderModel{
+		"conflicting ca": {
+			CaCertPem:  types.StringValue(string(clientCert)),
+			CaCertFile: types.StringValue("ca.pem"),
+		},
+		"missing ca file": {
+			CaCertFile: types.StringValue(filepath.Join(t.TempDir(), "missing.pem")),
+		},
+		"invalid ca": {
+			CaCertPem: types.StringValue("not a certificate"),
+		},
+		"key without certificate": {
+			ClientKeyPem: types.StringValue(string(clientKey)),
+		},
+		"unsupported tls version": {
+			TlsMinVersion: types.StringValue("1.0"),
+		},
+		"insecure cipher suite": {
+			TlsCipherSuites: types.ListValueMust(types.StringType, []attr.Value{
+				types.StringValue("TLS_RSA_WITH_RC4_128_SHA"),
+			}),
+		},
+		"invalid pin": {
+			TlsPinnedPublicKeys: types.ListValueMust(types.StringType, []attr.Value{
+				types.StringValue("sha1/AAAA"),
+			}),
+		},
+		"strict plaintext": {
+			Url:       types.StringValue("http://localhost:5100"),
+			StrictTls: types.BoolValue(true),
+		},
+		"strict tls 1.3": {
+			Url:           types.StringValue("https://pwpush.com"),
+			TlsMinVersion: types.StringValue("1.3"),
+			StrictTls:     types.BoolValue(true),
+		},
+		"strict cipher suites": {
+			Url: types.StringValue("https://pwpush.com"),
+			TlsCipherSuites: types.ListValueMust(types.StringType, []attr.Value{
+				types.StringValue("TLS_ECDHE_RSA_WITH_CHACHA20_POLY1305_SHA256"),
+			}),
+			StrictTls: types.BoolValue(true),
+		},
+		"strict insecure": {
+			Url:                   types.StringValue("https://pwpush.com"),
+			InsecureSkipTlsVerify: types.BoolValue(true),
+			StrictTls:             types.BoolValue(true),
+		},
+		"strict keylog": {
+			Url:           types.StringValue("https://pwpush.com"),
+			TlsKeylogFile: types.StringValue(filepath.Join(t.TempDir(), "keys.log")),
+			StrictTls:     types.BoolValue(true),
+		},
+		"mismatched key": {
+			ClientCertPem: types.StringValue(string(clientCert)),
+			ClientKeyPem:  types.StringValue(string(clientCert)),
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			if _, diags := newTLSConfig(context.Background(), data); !diags.HasError() {
+				t.Error("expected an error")
+			}
+		})
+	}
+}
+
+func TestNewTLSConfigKeylog(t *testing.T) {
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer server.Close()
+
+	keylogFile := filepath.Join(t.TempDir(), "keys.log")
+	config, diags := newTLSConfig(context.Background(), PwPusherProviderModel{
+		CaCertPem:     types.StringValue(string(pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: server.Certificate().Raw}))),
+		TlsKeylogFile: types.StringValue(keylogFile),
+	})
+	if diags.HasError() {
+		t.Fatalf("unexpected error: %v", diags)
+	}
+	if diags.WarningsCount() != 1 {
+		t.Errorf("got %d warnings, want 1", diags.WarningsCount())
+	}
+
+	client := &http.Client{Transport: &http.Transport{TLSClientConfig: config}}
+	resp, err := client.Get(server.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+
+	content, err := os.ReadFile(keylogFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(content), "CLIENT_") {
+		t.Errorf("got key log %q, want session keys", content)
+	}
+
+	// Configuring the provider again shares the open file.
+	again, _ := newTLSConfig(context.Background(), PwPusherProviderModel{TlsKeylogFile: types.StringValue(keylogFile)})
+	if again.KeyLogWriter != config.KeyLogWriter {
+		t.Error("got another key log writer for the same file, want it shared")
+	}
+}
+
+// testClientCertificate returns a self-signed client certificate and its
+// private key, PEM encoded.
+func testClientCertificate(t *testing.T) (certPEM []byte, keyPEM []byte) {
+	t.Helper()
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	template := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "pwpusher test client"},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		KeyUsage:              x509.KeyUsageDigitalSignature | x509.KeyUsageCertSign,
+		ExtKeyUsage:           []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+		BasicConstraintsValid: true,
+		IsCA:                  true,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}),
+		pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER})
+}
@@ -0,0 +1,135 @@
+// Copyright (c) Plex, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package provider
+
+import (
+	"context"
+	"fmt"
+	"terraform-provider-pwpusher/internal/client"
+
+	"github.com/hashicorp/terraform-plugin-framework-timeouts/datasource/timeouts"
+	"github.com/hashicorp/terraform-plugin-framework/datasource"
+	"github.com/hashicorp/terraform-plugin-framework/datasource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+// Ensure provider defined types fully satisfy framework interfaces.
+var _ datasource.DataSource = &TokenInfoDataSource{}
+
+func NewTokenInfoDataSource() datasource.DataSource {
+	return &TokenInfoDataSource{}
+}
+
+// TokenInfoDataSource defines the data source implementation.
+type TokenInfoDataSource struct {
+	providerData ProviderData
+}
+
+// TokenInfoDataSourceModel describes the data source data model.
+type TokenInfoDataSourceModel struct {
+	Url      types.String   `tfsdk:"url"`
+	Email    types.String   `tfsdk:"email"`
+	Valid    types.Bool     `tfsdk:"valid"`
+	Timeouts timeouts.Value `tfsdk:"timeouts"`
+}
+
+func (d *TokenInfoDataSource) Metadata(ctx context.Context, req datasource.MetadataRequest, resp *datasource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_token_info"
+}
+
+func (d *TokenInfoDataSource) Schema(ctx context.Context, req datasource.SchemaRequest, resp *datasource.SchemaResponse) {
+	resp.Schema = schema.Schema{
+		// This description is used by the documentation generator and the language server.
+		MarkdownDescription: "Verifies that the provider credentials are accepted by the pwpusher service. Reading this data source fails when they are not, so that pipelines stop before creating anything",
+
+		Attributes: map[string]schema.Attribute{
+			"url": schema.StringAttribute{
+				MarkdownDescription: "The URL of the pwpusher service the credentials were checked against",
+				Computed:            true,
+			},
+			"email": schema.StringAttribute{
+				MarkdownDescription: "The email address of the account the credentials belong to",
+				Computed:            true,
+			},
+			"valid": schema.BoolAttribute{
+				MarkdownDescription: "If the credentials are valid, always `true` once the read succeeds",
+				Computed:            true,
+			},
+		},
+
+		Blocks: map[string]schema.Block{
+			"timeouts": timeouts.Block(ctx),
+		},
+	}
+}
+
+func (d *TokenInfoDataSource) Configure(ctx context.Context, req datasource.ConfigureRequest, resp *datasource.ConfigureResponse) {
+	// Prevent panic if the provider has not been configured.
+	if req.ProviderData == nil {
+		return
+	}
+
+	providerData, ok := req.ProviderData.(ProviderData)
+
+	if !ok {
+		resp.Diagnostics.AddError(
+			"Unexpected Data Source Configure Type",
+			fmt.Sprintf("Expected ProviderData, got: %T. Please report this issue to the provider developers.", req.ProviderData),
+		)
+
+		return
+	}
+
+	d.providerData = providerData
+}
+
+func (d *TokenInfoDataSource) Read(ctx context.Context, req datasource.ReadRequest, resp *datasource.ReadResponse) {
+	var data TokenInfoDataSourceModel
+
+	// Read Terraform configuration data into the model
+	resp.Diagnostics.Append(req.Config.Get(ctx, &data)...)
+
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	readTimeout, diags := data.Timeouts.Read(ctx, defaultDataSourceReadTimeout)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	ctx, cancel := context.WithTimeout(ctx, readTimeout)
+	defer cancel()
+
+	ctx, op := d.providerData.operationContext(ctx, logSubsystemDataSources, "pwpusher_token_info read")
+	defer op.end(&resp.Diagnostics)
+
+	if d.providerData.email == "" {
+		resp.Diagnostics.AddError(
+			"Missing Credentials",
+			"The provider is not configured with an email and api_token, so there are no credentials to verify.",
+		)
+		return
+	}
+
+	err := d.providerData.apiClient().CheckCredentials(ctx)
+	if respErr, ok := client.RejectedCredentials(err); ok {
+		resp.Diagnostics.AddError(
+			"Invalid Credentials",
+			fmt.Sprintf("The pwpusher service at %s rejected the provider credentials: %s", d.providerData.url.ValueString(), respErr.Reason()),
+		)
+		return
+	}
+	if err != nil {
+		resp.Diagnostics.AddError("Client Error", fmt.Sprintf("Unable to verify credentials, got error: %s", err))
+		return
+	}
+
+	data.Url = d.providerData.url
+	data.Email = types.StringValue(d.providerData.email)
+	data.Valid = types.BoolValue(true)
+
+	// Save data into Terraform state
+	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+}
@@ -0,0 +1,32 @@
+// Copyright (c) Plex, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package provider
+
+import (
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-testing/helper/resource"
+)
+
+func TestAccTokenInfoDataSource(t *testing.T) {
+	resource.Test(t, resource.TestCase{
+		PreCheck:                 func() { testAccPreCheck(t) },
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			// Read testing
+			{
+				Config: testAccTokenInfoDataSourceConfig,
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("data.pwpusher_token_info.test", "valid", "true"),
+					resource.TestCheckResourceAttrSet("data.pwpusher_token_info.test", "url"),
+					resource.TestCheckResourceAttrSet("data.pwpusher_token_info.test", "email"),
+				),
+			},
+		},
+	})
+}
+
+const testAccTokenInfoDataSourceConfig = `
+data "pwpusher_token_info" "test" {}
+`
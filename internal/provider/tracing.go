@@ -0,0 +1,479 @@
+// Copyright (c) Plex, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package provider
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"terraform-provider-pwpusher/internal/client"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+)
+
+// The span kinds of OTLP the provider records.
+const (
+	spanKindInternal = 1
+	spanKindClient   = 3
+)
+
+// spanStatusError is the OTLP status of the spans of failed operations and
+// requests.
+const spanStatusError = 2
+
+// traceparentHeader is the W3C Trace Context header propagating the span of
+// a request to the service.
+const traceparentHeader = "traceparent"
+
+// tracingServiceName names the provider in the resource and scope of the
+// exported spans.
+const tracingServiceName = "terraform-provider-pwpusher"
+
+// otlpExportTimeout bounds the export of the spans of an operation, which
+// must not hold up the apply for long when the collector is unreachable.
+const otlpExportTimeout = 10 * time.Second
+
+// maxPendingSpans bounds the spans kept for the next export, the others are
+// dropped.
+const maxPendingSpans = 2048
+
+// pushTokenPath matches the paths of the API holding the token of a push,
+// which gives access to it and must not reach the collector.
+var pushTokenPath = regexp.MustCompile(`/p/([^/]+?)(\.json|/audit\.json)?$`)
+
+// spanContext identifies a span and its trace.
+type spanContext struct {
+	traceID [16]byte
+	spanID  [8]byte
+}
+
+func (c spanContext) valid() bool {
+	return c.traceID != [16]byte{} && c.spanID != [8]byte{}
+}
+
+// parseTraceparent returns the span context of the value of a W3C
+// traceparent header, and whether it is a valid one.
+func parseTraceparent(value string) (spanContext, bool) {
+	var c spanContext
+	fields := strings.Split(strings.TrimSpace(value), "-")
+	if len(fields) < 4 || fields[0] != "00" || len(fields[1]) != 32 || len(fields[2]) != 16 {
+		return c, false
+	}
+	if _, err := hex.Decode(c.traceID[:], []byte(fields[1])); err != nil {
+		return c, false
+	}
+	if _, err := hex.Decode(c.spanID[:], []byte(fields[2])); err != nil {
+		return c, false
+	}
+	return c, c.valid()
+}
+
+// tracer records spans of the operations of the provider and of their
+// requests to the service, and exports them to an OpenTelemetry collector
+// with OTLP over HTTP, in its JSON encoding. A nil tracer records nothing.
+type tracer struct {
+	endpoint string
+	headers  http.Header
+	client   *http.Client
+	version  string
+	// parent is the remote parent of the spans of operations, from the
+	// TRACEPARENT environment variable, invalid for none.
+	parent spanContext
+
+	mu sync.Mutex
+	// pending are the ended spans waiting for the next export.
+	pending []*span
+}
+
+// newTracer returns a tracer exporting to the OTLP/HTTP traces endpoint of
+// a collector, with the headers of the OTEL_EXPORTER_OTLP_TRACES_HEADERS or
+// OTEL_EXPORTER_OTLP_HEADERS environment variable.
+func newTracer(endpoint, version string) (*tracer, error) {
+	u, err := url.Parse(endpoint)
+	if err != nil {
+		return nil, err
+	}
+	if (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return nil, fmt.Errorf("the endpoint must be an http or https URL, got %q", endpoint)
+	}
+	headersEnv := "OTEL_EXPORTER_OTLP_TRACES_HEADERS"
+	if os.Getenv(headersEnv) == "" {
+		headersEnv = "OTEL_EXPORTER_OTLP_HEADERS"
+	}
+	headers, err := parseOTLPHeaders(os.Getenv(headersEnv))
+	if err != nil {
+		return nil, fmt.Errorf("invalid %s environment variable: %w", headersEnv, err)
+	}
+	parent, _ := parseTraceparent(os.Getenv("TRACEPARENT"))
+
+	transport := newNetworkTransport(nil, http.ProxyFromEnvironment, nil, connectionSettings{maxIdleConnsPerHost: 1, idleConnTimeout: 90 * time.Second})
+	return &tracer{
+		endpoint: endpoint,
+		headers:  headers,
+		client:   &http.Client{Transport: transport, Timeout: otlpExportTimeout},
+		version:  version,
+		parent:   parent,
+	}, nil
+}
+
+// otlpTracesEndpoint returns the configured traces endpoint, defaulting to
+// the OTEL_EXPORTER_OTLP_TRACES_ENDPOINT environment variable, used as is,
+// or to the /v1/traces path below OTEL_EXPORTER_OTLP_ENDPOINT, as the
+// exporters of the OpenTelemetry SDKs do.
+func otlpTracesEndpoint(value types.String) types.String {
+	value = stringValueOrEnv(value, "OTEL_EXPORTER_OTLP_TRACES_ENDPOINT")
+	if !value.IsNull() {
+		return value
+	}
+	if base := os.Getenv("OTEL_EXPORTER_OTLP_ENDPOINT"); base != "" {
+		return types.StringValue(strings.TrimSuffix(base, "/") + "/v1/traces")
+	}
+	return value
+}
+
+// parseOTLPHeaders parses the value of the OTEL_EXPORTER_OTLP_HEADERS
+// environment variable, comma separated key=value pairs with percent-encoded
+// values.
+func parseOTLPHeaders(value string) (http.Header, error) {
+	headers := http.Header{}
+	for _, pair := range strings.Split(value, ",") {
+		if strings.TrimSpace(pair) == "" {
+			continue
+		}
+		key, encoded, ok := strings.Cut(pair, "=")
+		key = strings.TrimSpace(key)
+		if !ok || key == "" {
+			return nil, fmt.Errorf("expected key=value pairs, got %q", pair)
+		}
+		decoded, err := url.PathUnescape(strings.TrimSpace(encoded))
+		if err != nil {
+			return nil, fmt.Errorf("invalid value of %s: %w", key, err)
+		}
+		headers.Set(key, decoded)
+	}
+	return headers, nil
+}
+
+// spanKey is the context key of the span in progress.
+type spanKey struct{}
+
+// spanFromContext returns the span in progress of ctx, nil for none.
+func spanFromContext(ctx context.Context) *span {
+	s, _ := ctx.Value(spanKey{}).(*span)
+	return s
+}
+
+// start returns ctx with a new span in progress named name, a child of the
+// span in progress of ctx or else of the parent of the tracer, and the span.
+// The caller must finish it. It returns ctx and a nil span when t is nil.
+func (t *tracer) start(ctx context.Context, name string, kind int) (context.Context, *span) {
+	if t == nil {
+		return ctx, nil
+	}
+	s := &span{tracer: t, name: name, kind: kind, start: time.Now(), attributes: map[string]any{}}
+	parent := t.parent
+	if inProgress := spanFromContext(ctx); inProgress != nil {
+		parent = inProgress.context
+	}
+	if parent.valid() {
+		s.context.traceID = parent.traceID
+		s.parentID = parent.spanID
+	} else {
+		// The IDs only correlate spans, a failure to read randomness is
+		// not worth failing the operation over.
+		_, _ = rand.Read(s.context.traceID[:])
+	}
+	_, _ = rand.Read(s.context.spanID[:])
+	return context.WithValue(ctx, spanKey{}, s), s
+}
+
+// span is an operation or request of a trace. The methods of a nil span do
+// nothing.
+type span struct {
+	tracer   *tracer
+	context  spanContext
+	parentID [8]byte
+	name     string
+	kind     int
+	start    time.Time
+
+	mu         sync.Mutex
+	end        time.Time
+	attributes map[string]any
+	retries    int
+	failure    string
+}
+
+// setAttribute sets the attribute key of s to value, a string or an int.
+func (s *span) setAttribute(key string, value any) {
+	if s == nil {
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.attributes[key] = value
+}
+
+// retried records another attempt of the request of s.
+func (s *span) retried() {
+	if s == nil {
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.retries++
+}
+
+// traceparent returns the value of the traceparent header propagating s.
+func (s *span) traceparent() string {
+	return "00-" + hex.EncodeToString(s.context.traceID[:]) + "-" + hex.EncodeToString(s.context.spanID[:]) + "-01"
+}
+
+// finish ends s, as failed with the message failure unless it is empty, and
+// queues it for the next export of its tracer.
+func (s *span) finish(failure string) {
+	if s == nil {
+		return
+	}
+	s.mu.Lock()
+	if !s.end.IsZero() {
+		s.mu.Unlock()
+		return
+	}
+	s.end = time.Now()
+	s.failure = failure
+	s.mu.Unlock()
+
+	t := s.tracer
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if len(t.pending) < maxPendingSpans {
+		t.pending = append(t.pending, s)
+	}
+}
+
+// flush exports the ended spans, logging to subsystem when the collector
+// cannot be reached. Traces are best effort, they never fail the operation
+// they describe.
+func (t *tracer) flush(ctx context.Context, subsystem string) {
+	if t == nil {
+		return
+	}
+	t.mu.Lock()
+	spans := t.pending
+	t.pending = nil
+	t.mu.Unlock()
+	if len(spans) == 0 {
+		return
+	}
+
+	body, err := json.Marshal(t.export(spans))
+	if err != nil {
+		tflog.SubsystemWarn(ctx, subsystem, "Unable to encode traces", map[string]interface{}{"error": err.Error()})
+		return
+	}
+	// The export outlives a cancelled operation, whose spans tell why it
+	// was cancelled.
+	ctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), otlpExportTimeout)
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, t.endpoint, bytes.NewReader(body))
+	if err != nil {
+		tflog.SubsystemWarn(ctx, subsystem, "Unable to export traces", map[string]interface{}{"endpoint": t.endpoint, "error": err.Error()})
+		return
+	}
+	for key, values := range t.headers {
+		req.Header[key] = values
+	}
+	req.Header.Set("Content-Type", "application/json")
+	res, err := t.client.Do(req)
+	if err != nil {
+		tflog.SubsystemWarn(ctx, subsystem, "Unable to export traces", map[string]interface{}{"endpoint": t.endpoint, "error": err.Error()})
+		return
+	}
+	res.Body.Close()
+	if res.StatusCode < 200 || res.StatusCode > 299 {
+		tflog.SubsystemWarn(ctx, subsystem, "Unable to export traces", map[string]interface{}{"endpoint": t.endpoint, "status": res.Status})
+	}
+}
+
+// export returns the OTLP request exporting spans.
+func (t *tracer) export(spans []*span) otlpTraces {
+	exported := make([]otlpSpan, 0, len(spans))
+	for _, s := range spans {
+		exported = append(exported, s.export())
+	}
+	return otlpTraces{ResourceSpans: []otlpResourceSpans{{
+		Resource: otlpResource{Attributes: otlpAttributes(map[string]any{
+			"service.name":    tracingServiceName,
+			"service.version": t.version,
+		})},
+		ScopeSpans: []otlpScopeSpans{{
+			Scope: otlpScope{Name: tracingServiceName, Version: t.version},
+			Spans: exported,
+		}},
+	}}}
+}
+
+func (s *span) export() otlpSpan {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	exported := otlpSpan{
+		TraceID:           hex.EncodeToString(s.context.traceID[:]),
+		SpanID:            hex.EncodeToString(s.context.spanID[:]),
+		Name:              s.name,
+		Kind:              s.kind,
+		StartTimeUnixNano: strconv.FormatInt(s.start.UnixNano(), 10),
+		EndTimeUnixNano:   strconv.FormatInt(s.end.UnixNano(), 10),
+	}
+	attributes := s.attributes
+	if s.kind == spanKindClient {
+		attributes = make(map[string]any, len(s.attributes)+1)
+		for key, value := range s.attributes {
+			attributes[key] = value
+		}
+		attributes["pwpusher.retry_count"] = s.retries
+	}
+	exported.Attributes = otlpAttributes(attributes)
+	if s.parentID != [8]byte{} {
+		exported.ParentSpanID = hex.EncodeToString(s.parentID[:])
+	}
+	if s.failure != "" {
+		exported.Status = otlpStatus{Code: spanStatusError, Message: s.failure}
+	}
+	return exported
+}
+
+// otlpAttributes returns attributes in their OTLP encoding, sorted by key.
+func otlpAttributes(attributes map[string]any) []otlpAttribute {
+	keys := make([]string, 0, len(attributes))
+	for key := range attributes {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	encoded := make([]otlpAttribute, 0, len(keys))
+	for _, key := range keys {
+		var value otlpValue
+		switch v := attributes[key].(type) {
+		case int:
+			value.IntValue = strconv.Itoa(v)
+		default:
+			text := fmt.Sprint(v)
+			value.StringValue = &text
+		}
+		encoded = append(encoded, otlpAttribute{Key: key, Value: value})
+	}
+	return encoded
+}
+
+// The types of the JSON encoding of the OTLP export request of traces.
+type (
+	otlpTraces struct {
+		ResourceSpans []otlpResourceSpans `json:"resourceSpans"`
+	}
+	otlpResourceSpans struct {
+		Resource   otlpResource     `json:"resource"`
+		ScopeSpans []otlpScopeSpans `json:"scopeSpans"`
+	}
+	otlpResource struct {
+		Attributes []otlpAttribute `json:"attributes"`
+	}
+	otlpScopeSpans struct {
+		Scope otlpScope  `json:"scope"`
+		Spans []otlpSpan `json:"spans"`
+	}
+	otlpScope struct {
+		Name    string `json:"name"`
+		Version string `json:"version"`
+	}
+	otlpSpan struct {
+		TraceID           string          `json:"traceId"`
+		SpanID            string          `json:"spanId"`
+		ParentSpanID      string          `json:"parentSpanId,omitempty"`
+		Name              string          `json:"name"`
+		Kind              int             `json:"kind"`
+		StartTimeUnixNano string          `json:"startTimeUnixNano"`
+		EndTimeUnixNano   string          `json:"endTimeUnixNano"`
+		Attributes        []otlpAttribute `json:"attributes,omitempty"`
+		Status            otlpStatus      `json:"status"`
+	}
+	otlpStatus struct {
+		Code    int    `json:"code,omitempty"`
+		Message string `json:"message,omitempty"`
+	}
+	otlpAttribute struct {
+		Key   string    `json:"key"`
+		Value otlpValue `json:"value"`
+	}
+	otlpValue struct {
+		StringValue *string `json:"stringValue,omitempty"`
+		IntValue    string  `json:"intValue,omitempty"`
+	}
+)
+
+// tracingTransport records a client span for every request, retries
+// included, and propagates it to the service in the traceparent header. It
+// sends requests untraced when tracer is nil.
+type tracingTransport struct {
+	tracer *tracer
+	next   http.RoundTripper
+}
+
+func (t *tracingTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if t.tracer == nil {
+		return t.next.RoundTrip(req)
+	}
+	route, full := tracedURL(req.URL)
+	ctx, span := t.tracer.start(req.Context(), req.Method+" "+route, spanKindClient)
+	if operation := client.OperationName(ctx); operation != "" {
+		span.setAttribute("pwpusher.operation", operation)
+	}
+	span.setAttribute("http.request.method", req.Method)
+	span.setAttribute("url.full", full)
+	span.setAttribute("server.address", req.URL.Hostname())
+
+	// A RoundTripper must not modify the request it was given.
+	req = req.Clone(ctx)
+	req.Header.Set(traceparentHeader, span.traceparent())
+	resp, err := t.next.RoundTrip(req)
+	if err != nil {
+		span.finish(err.Error())
+		return nil, err
+	}
+	span.setAttribute("http.response.status_code", resp.StatusCode)
+	failure := ""
+	if resp.StatusCode >= http.StatusBadRequest {
+		failure = resp.Status
+	}
+	resp.Body = &afterClose{ReadCloser: resp.Body, done: func() { span.finish(failure) }}
+	return resp, nil
+}
+
+// tracedURL returns the path of u with the tokens of pushes replaced by
+// {url_token}, and u with that path and without its credentials.
+func tracedURL(u *url.URL) (route, full string) {
+	route = u.Path
+	if match := pushTokenPath.FindStringSubmatchIndex(route); match != nil {
+		if token := route[match[2]:match[3]]; token != "active" && token != "expired" {
+			route = route[:match[2]] + "{url_token}" + route[match[3]:]
+		}
+	}
+	full = u.Scheme + "://" + u.Host + route
+	if u.RawQuery != "" {
+		full += "?" + u.RawQuery
+	}
+	return route, full
+}
@@ -0,0 +1,336 @@
+// Copyright (c) Plex, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package provider
+
+import (
+	"context"
+	"crypto/tls"
+	"encoding/base64"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/cookiejar"
+	"net/url"
+	"sort"
+	"strings"
+	"sync"
+	"terraform-provider-pwpusher/internal/client"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+	"golang.org/x/net/http/httpproxy"
+	"golang.org/x/oauth2"
+	"golang.org/x/oauth2/clientcredentials"
+	"golang.org/x/time/rate"
+)
+
+// The defaults of the connection pool of the transport of the provider.
+// Terraform runs up to 10 operations in parallel, all against the same
+// service, which would each open a new connection with the 2 idle
+// connections per host of http.DefaultTransport.
+const (
+	transportMaxIdleConns               = 100
+	defaultTransportMaxIdleConnsPerHost = 10
+	defaultTransportIdleConnTimeout     = "90s"
+)
+
+// connectionSettings are the connection reuse settings of the transport of
+// the provider.
+type connectionSettings struct {
+	disableHTTP2        bool
+	maxIdleConnsPerHost int
+	idleConnTimeout     time.Duration
+}
+
+// newNetworkTransport returns the transport connecting to the service,
+// through proxy unless it is nil and with dial unless it is nil. It is built
+// from scratch rather than cloned from http.DefaultTransport, which other
+// code in the process may change.
+func newNetworkTransport(tlsConfig *tls.Config, proxy func(*http.Request) (*url.URL, error), dial dialFunc, settings connectionSettings) *http.Transport {
+	if dial == nil {
+		dial = newDialer().DialContext
+	}
+	transport := &http.Transport{
+		Proxy:                 proxy,
+		DialContext:           dial,
+		TLSClientConfig:       tlsConfig,
+		ForceAttemptHTTP2:     !settings.disableHTTP2,
+		MaxIdleConns:          max(transportMaxIdleConns, settings.maxIdleConnsPerHost),
+		MaxIdleConnsPerHost:   settings.maxIdleConnsPerHost,
+		IdleConnTimeout:       settings.idleConnTimeout,
+		TLSHandshakeTimeout:   10 * time.Second,
+		ExpectContinueTimeout: 1 * time.Second,
+	}
+	if settings.disableHTTP2 {
+		// A non-nil map without the h2 protocol keeps the transport from
+		// negotiating it.
+		transport.TLSNextProto = map[string]func(string, *tls.Conn) http.RoundTripper{}
+	}
+	return transport
+}
+
+// headerTransport sets headers on every request before handing it to the
+// next transport, so that calls made through any http.Client method carry
+// them.
+type headerTransport struct {
+	headers http.Header
+	next    http.RoundTripper
+}
+
+func (t *headerTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	// A RoundTripper must not modify the request it was given.
+	req = req.Clone(req.Context())
+	for key, values := range t.headers {
+		req.Header[key] = values
+	}
+	return t.next.RoundTrip(req)
+}
+
+// timeoutTransport bounds the duration of requests, including reading their
+// response, with a read timeout for requests that only read and a write
+// timeout for the others. A zero timeout disables it.
+type timeoutTransport struct {
+	read  time.Duration
+	write time.Duration
+	next  http.RoundTripper
+}
+
+func (t *timeoutTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	timeout := t.write
+	if req.Method == http.MethodGet || req.Method == http.MethodHead {
+		timeout = t.read
+	}
+	if timeout <= 0 {
+		return t.next.RoundTrip(req)
+	}
+
+	ctx, cancel := context.WithTimeout(req.Context(), timeout)
+	resp, err := t.next.RoundTrip(req.WithContext(ctx))
+	if err != nil {
+		cancel()
+		return nil, err
+	}
+	resp.Body = &afterClose{ReadCloser: resp.Body, done: cancel}
+	return resp, nil
+}
+
+// rateLimitTransport waits for limiter before sending requests, or sends
+// them right away when limiter is nil.
+type rateLimitTransport struct {
+	limiter *rate.Limiter
+	next    http.RoundTripper
+}
+
+func (t *rateLimitTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if t.limiter != nil {
+		if err := t.limiter.Wait(req.Context()); err != nil {
+			return nil, err
+		}
+	}
+	return t.next.RoundTrip(req)
+}
+
+// concurrencyTransport bounds the number of requests in flight with the
+// slots of sem, or sends them right away when sem is nil. A request holds its
+// slot until its response body is closed.
+type concurrencyTransport struct {
+	sem  chan struct{}
+	next http.RoundTripper
+}
+
+func (t *concurrencyTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if t.sem == nil {
+		return t.next.RoundTrip(req)
+	}
+	select {
+	case t.sem <- struct{}{}:
+	case <-req.Context().Done():
+		return nil, req.Context().Err()
+	}
+	release := func() { <-t.sem }
+
+	resp, err := t.next.RoundTrip(req)
+	if err != nil {
+		release()
+		return nil, err
+	}
+	resp.Body = &afterClose{ReadCloser: resp.Body, done: release}
+	return resp, nil
+}
+
+// afterClose calls done once the response body it wraps is closed.
+type afterClose struct {
+	io.ReadCloser
+	done func()
+	once sync.Once
+}
+
+func (b *afterClose) Close() error {
+	err := b.ReadCloser.Close()
+	b.once.Do(b.done)
+	return err
+}
+
+// failoverTransport sends requests that only read to the fallbacks of
+// primary, in order, when they cannot be sent to primary. Requests that
+// write are only sent to primary, as a request that failed to connect may
+// still have reached the service.
+type failoverTransport struct {
+	primary   string
+	fallbacks []string
+	next      http.RoundTripper
+}
+
+func (t *failoverTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	resp, err := t.next.RoundTrip(req)
+	if err == nil || (req.Method != http.MethodGet && req.Method != http.MethodHead) {
+		return resp, err
+	}
+	rest, ok := strings.CutPrefix(req.URL.String(), t.primary)
+	if !ok {
+		return resp, err
+	}
+
+	for _, fallback := range t.fallbacks {
+		if req.Context().Err() != nil {
+			return nil, err
+		}
+		tflog.SubsystemDebug(req.Context(), client.LogSubsystem, "Failing over to another pwpusher URL", map[string]interface{}{
+			"url":   fallback,
+			"error": err.Error(),
+		})
+		u, parseErr := url.Parse(fallback + rest)
+		if parseErr != nil {
+			return nil, parseErr
+		}
+		fallbackReq := req.Clone(req.Context())
+		fallbackReq.URL = u
+		fallbackReq.Host = ""
+		resp, err = t.next.RoundTrip(fallbackReq)
+		if err == nil {
+			return resp, nil
+		}
+	}
+	return nil, err
+}
+
+// basicAuthorization returns the value of an Authorization header carrying
+// HTTP Basic credentials, as set by http.Request.SetBasicAuth.
+func basicAuthorization(username, password string) string {
+	return "Basic " + base64.StdEncoding.EncodeToString([]byte(username+":"+password))
+}
+
+// newCookieJar returns a cookie jar holding cookies for the service at
+// serviceURL.
+func newCookieJar(serviceURL string, cookies map[string]string) (http.CookieJar, error) {
+	jar, err := cookiejar.New(nil)
+	if err != nil {
+		return nil, err
+	}
+	if len(cookies) == 0 {
+		return jar, nil
+	}
+	u, err := url.Parse(serviceURL)
+	if err != nil {
+		return nil, err
+	}
+	names := make([]string, 0, len(cookies))
+	for name := range cookies {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	seeded := make([]*http.Cookie, 0, len(names))
+	for _, name := range names {
+		cookie := &http.Cookie{Name: name, Value: cookies[name], Path: "/"}
+		if err := cookie.Valid(); err != nil {
+			return nil, err
+		}
+		seeded = append(seeded, cookie)
+	}
+	jar.SetCookies(u, seeded)
+	return jar, nil
+}
+
+// newProxyFunc returns the proxy selection function of the transport. The
+// proxy is read from the environment unless proxyURL is set, in which case
+// it replaces the HTTP_PROXY and HTTPS_PROXY variables. When user is set, it
+// replaces the credentials of the selected proxy, which the transport sends
+// in the Proxy-Authorization header.
+func newProxyFunc(proxyURL string, user *url.Userinfo) (func(*http.Request) (*url.URL, error), error) {
+	config := httpproxy.FromEnvironment()
+	if proxyURL != "" {
+		u, err := url.Parse(proxyURL)
+		if err != nil {
+			return nil, err
+		}
+		switch u.Scheme {
+		case "http", "https", "socks5":
+		default:
+			return nil, fmt.Errorf("the scheme of the proxy URL must be http, https or socks5, got %q", u.Scheme)
+		}
+		config.HTTPProxy = proxyURL
+		config.HTTPSProxy = proxyURL
+	}
+	proxy := config.ProxyFunc()
+	return func(req *http.Request) (*url.URL, error) {
+		u, err := proxy(req.URL)
+		if u == nil || err != nil || user == nil {
+			return u, err
+		}
+		authenticated := *u
+		authenticated.User = user
+		return &authenticated, nil
+	}, nil
+}
+
+// oauth2Transport authorizes requests with the tokens of config, requested
+// with the context of the request that needs one so that cancelling it also
+// cancels the token request. Tokens are reused until they expire.
+type oauth2Transport struct {
+	config clientcredentials.Config
+	// client sends the token requests.
+	client *http.Client
+	next   http.RoundTripper
+
+	// lock is held by the request fetching a token, as a channel so that
+	// the others can stop waiting for it when they are cancelled.
+	lock  chan struct{}
+	token *oauth2.Token
+}
+
+func newOAuth2Transport(config clientcredentials.Config, client *http.Client, next http.RoundTripper) *oauth2Transport {
+	return &oauth2Transport{config: config, client: client, next: next, lock: make(chan struct{}, 1)}
+}
+
+func (t *oauth2Transport) RoundTrip(req *http.Request) (*http.Response, error) {
+	token, err := t.validToken(req.Context())
+	if err != nil {
+		return nil, err
+	}
+	// A RoundTripper must not modify the request it was given.
+	req = req.Clone(req.Context())
+	token.SetAuthHeader(req)
+	return t.next.RoundTrip(req)
+}
+
+// validToken returns the current token, requesting a new one once it is
+// expired.
+func (t *oauth2Transport) validToken(ctx context.Context) (*oauth2.Token, error) {
+	select {
+	case t.lock <- struct{}{}:
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+	defer func() { <-t.lock }()
+
+	if t.token.Valid() {
+		return t.token, nil
+	}
+	token, err := t.config.Token(context.WithValue(ctx, oauth2.HTTPClient, t.client))
+	if err != nil {
+		return nil, err
+	}
+	t.token = token
+	return token, nil
+}
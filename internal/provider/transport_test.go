@@ -0,0 +1,335 @@
+// Copyright (c) Plex, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package provider
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"golang.org/x/oauth2/clientcredentials"
+	"golang.org/x/time/rate"
+)
+
+func TestHeaderTransport(t *testing.T) {
+	var got http.Header
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		got = r.Header
+	}))
+	defer server.Close()
+
+	headers := http.Header{}
+	headers.Set("X-User-Email", "user@example.com")
+	headers.Set("X-User-Token", "token")
+	client := &http.Client{Transport: &headerTransport{headers: headers, next: http.DefaultTransport}}
+
+	req, err := http.NewRequest(http.MethodGet, server.URL, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+
+	for key := range headers {
+		if got.Get(key) != headers.Get(key) {
+			t.Errorf("header %s: got %q, want %q", key, got.Get(key), headers.Get(key))
+		}
+	}
+	if req.Header.Get("X-User-Token") != "" {
+		t.Error("the original request was modified")
+	}
+}
+
+func TestNewNetworkTransport(t *testing.T) {
+	var mu sync.Mutex
+	conns := 0
+	server := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		time.Sleep(10 * time.Millisecond)
+	}))
+	server.Config.ConnState = func(_ net.Conn, state http.ConnState) {
+		if state == http.StateNew {
+			mu.Lock()
+			conns++
+			mu.Unlock()
+		}
+	}
+	server.Start()
+	defer server.Close()
+
+	transport := newNetworkTransport(nil, nil, nil, connectionSettings{maxIdleConnsPerHost: defaultTransportMaxIdleConnsPerHost})
+	httpClient := &http.Client{Transport: transport}
+
+	// Terraform runs 10 operations in parallel, which should keep reusing
+	// the connections of the first round.
+	for round := 0; round < 3; round++ {
+		var wg sync.WaitGroup
+		for i := 0; i < defaultTransportMaxIdleConnsPerHost; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				resp, err := httpClient.Get(server.URL)
+				if err != nil {
+					t.Error(err)
+					return
+				}
+				_, _ = io.Copy(io.Discard, resp.Body)
+				resp.Body.Close()
+			}()
+		}
+		wg.Wait()
+	}
+	mu.Lock()
+	defer mu.Unlock()
+	if conns > defaultTransportMaxIdleConnsPerHost {
+		t.Errorf("opened %d connections, want at most %d", conns, defaultTransportMaxIdleConnsPerHost)
+	}
+}
+
+func TestNewNetworkTransportHTTP2(t *testing.T) {
+	server := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	server.EnableHTTP2 = true
+	server.StartTLS()
+	defer server.Close()
+	tlsConfig := server.Client().Transport.(*http.Transport).TLSClientConfig
+
+	for disable, want := range map[bool]int{false: 2, true: 1} {
+		transport := newNetworkTransport(tlsConfig.Clone(), nil, nil, connectionSettings{disableHTTP2: disable})
+		resp, err := (&http.Client{Transport: transport}).Get(server.URL)
+		if err != nil {
+			t.Fatal(err)
+		}
+		resp.Body.Close()
+		if resp.ProtoMajor != want {
+			t.Errorf("disable_http2 = %t: got %s, want HTTP/%d", disable, resp.Proto, want)
+		}
+	}
+}
+
+func TestBasicAuthorization(t *testing.T) {
+	req := &http.Request{Header: http.Header{}}
+	req.SetBasicAuth("user", "pass:word")
+
+	if got, want := basicAuthorization("user", "pass:word"), req.Header.Get("Authorization"); got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+}
+
+func TestNewCookieJar(t *testing.T) {
+	var got []*http.Cookie
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		got = r.Cookies()
+		http.SetCookie(w, &http.Cookie{Name: "refreshed", Value: "yes"})
+	}))
+	defer server.Close()
+
+	jar, err := newCookieJar(server.URL, map[string]string{"session": "abc"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	client := &http.Client{Jar: jar}
+
+	for _, want := range []int{1, 2} {
+		resp, err := client.Get(server.URL + "/p.json")
+		if err != nil {
+			t.Fatal(err)
+		}
+		resp.Body.Close()
+		if len(got) != want {
+			t.Errorf("got %d cookies, want %d", len(got), want)
+		}
+	}
+
+	if _, err := newCookieJar(server.URL, map[string]string{"in valid": "abc"}); err == nil {
+		t.Error("expected an error for an invalid cookie name")
+	}
+}
+
+func TestTimeoutTransport(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-r.Context().Done():
+		case <-time.After(200 * time.Millisecond):
+		}
+	}))
+	defer server.Close()
+
+	client := &http.Client{Transport: &timeoutTransport{read: 50 * time.Millisecond, write: time.Second, next: http.DefaultTransport}}
+
+	if resp, err := client.Get(server.URL); err == nil {
+		resp.Body.Close()
+		t.Error("expected the read to time out")
+	}
+	resp, err := client.Post(server.URL, "application/json", strings.NewReader("{}"))
+	if err != nil {
+		t.Fatalf("unexpected error writing: %s", err)
+	}
+	if _, err := io.ReadAll(resp.Body); err != nil {
+		t.Errorf("unexpected error reading the response: %s", err)
+	}
+	resp.Body.Close()
+}
+
+func TestRateLimitTransport(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer server.Close()
+
+	client := &http.Client{Transport: &rateLimitTransport{
+		limiter: rate.NewLimiter(rate.Limit(20), 1),
+		next:    http.DefaultTransport,
+	}}
+
+	start := time.Now()
+	for i := 0; i < 5; i++ {
+		resp, err := client.Get(server.URL)
+		if err != nil {
+			t.Fatal(err)
+		}
+		resp.Body.Close()
+	}
+	// The first request goes out right away, the others 50ms apart.
+	if elapsed := time.Since(start); elapsed < 190*time.Millisecond {
+		t.Errorf("5 requests at 20 per second took %s", elapsed)
+	}
+}
+
+func TestConcurrencyTransport(t *testing.T) {
+	var mu sync.Mutex
+	inFlight, maxInFlight := 0, 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		inFlight++
+		maxInFlight = max(maxInFlight, inFlight)
+		mu.Unlock()
+		time.Sleep(20 * time.Millisecond)
+		mu.Lock()
+		inFlight--
+		mu.Unlock()
+	}))
+	defer server.Close()
+
+	client := &http.Client{Transport: &concurrencyTransport{
+		sem:  make(chan struct{}, 2),
+		next: http.DefaultTransport,
+	}}
+
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			resp, err := client.Get(server.URL)
+			if err != nil {
+				t.Error(err)
+				return
+			}
+			resp.Body.Close()
+		}()
+	}
+	wg.Wait()
+
+	if maxInFlight > 2 {
+		t.Errorf("got %d requests in flight, want at most 2", maxInFlight)
+	}
+}
+
+func TestFailoverTransport(t *testing.T) {
+	var methods []string
+	fallback := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		methods = append(methods, r.Method+" "+r.URL.Path)
+	}))
+	defer fallback.Close()
+
+	// Nothing listens on the primary URL.
+	primary := "http://127.0.0.1:1/pwpush"
+	client := &http.Client{Transport: &failoverTransport{
+		primary:   primary,
+		fallbacks: []string{"http://127.0.0.1:1/other", fallback.URL},
+		next:      http.DefaultTransport,
+	}}
+
+	resp, err := client.Get(primary + "/p/active.json?page=1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if _, err := client.Post(primary+"/p.json", "application/json", strings.NewReader("{}")); err == nil {
+		t.Error("expected the write to fail instead of failing over")
+	}
+
+	if len(methods) != 1 || methods[0] != "GET /p/active.json" {
+		t.Errorf("got requests %v on the fallback, want only GET /p/active.json", methods)
+	}
+}
+
+func TestOAuth2Transport(t *testing.T) {
+	var mu sync.Mutex
+	tokenRequests := 0
+	slow := make(chan struct{})
+	tokenServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		tokenRequests++
+		first := tokenRequests == 1
+		mu.Unlock()
+		if first {
+			// The first token request hangs until it is cancelled.
+			select {
+			case <-r.Context().Done():
+			case <-slow:
+			}
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprint(w, `{"access_token":"test-token","token_type":"Bearer","expires_in":3600}`)
+	}))
+	defer tokenServer.Close()
+	defer close(slow)
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer test-token" {
+			w.WriteHeader(http.StatusUnauthorized)
+		}
+	}))
+	defer server.Close()
+
+	config := clientcredentials.Config{ClientID: "client", ClientSecret: "secret", TokenURL: tokenServer.URL}
+	client := &http.Client{Transport: newOAuth2Transport(config, http.DefaultClient, http.DefaultTransport)}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	req, _ := http.NewRequestWithContext(ctx, http.MethodGet, server.URL, nil)
+	start := time.Now()
+	if resp, err := client.Do(req); err == nil {
+		resp.Body.Close()
+		t.Error("expected the cancelled token request to fail")
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("the cancelled request took %s", elapsed)
+	}
+
+	for i := 0; i < 2; i++ {
+		resp, err := client.Get(server.URL)
+		if err != nil {
+			t.Fatal(err)
+		}
+		resp.Body.Close()
+		if resp.StatusCode != http.StatusOK {
+			t.Errorf("got status %d, want %d", resp.StatusCode, http.StatusOK)
+		}
+	}
+	mu.Lock()
+	defer mu.Unlock()
+	if tokenRequests != 2 {
+		t.Errorf("got %d token requests, want 2", tokenRequests)
+	}
+}
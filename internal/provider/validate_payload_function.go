@@ -0,0 +1,97 @@
+// Copyright (c) Plex, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package provider
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/hashicorp/terraform-plugin-framework/attr"
+	"github.com/hashicorp/terraform-plugin-framework/function"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+// Ensure provider defined types fully satisfy framework interfaces.
+var _ function.Function = &ValidatePayloadFunction{}
+
+// defaultMaxPayloadBytes is the payload size limit validate_payload applies
+// when no server limit is supplied.
+const defaultMaxPayloadBytes = 1024 * 1024
+
+func NewValidatePayloadFunction() function.Function {
+	return &ValidatePayloadFunction{}
+}
+
+// ValidatePayloadFunction defines the function implementation.
+type ValidatePayloadFunction struct{}
+
+// PayloadValidation is the result of the validate_payload function.
+type PayloadValidation struct {
+	Valid   bool   `tfsdk:"valid"`
+	Message string `tfsdk:"message"`
+}
+
+func (f *ValidatePayloadFunction) Metadata(ctx context.Context, req function.MetadataRequest, resp *function.MetadataResponse) {
+	resp.Name = "validate_payload"
+}
+
+func (f *ValidatePayloadFunction) Definition(ctx context.Context, req function.DefinitionRequest, resp *function.DefinitionResponse) {
+	resp.Definition = function.Definition{
+		Summary:             "Check that a payload can be pushed",
+		MarkdownDescription: fmt.Sprintf("Checks that a payload is not empty, is valid UTF-8 without NUL characters and fits in the size limit. `valid` is `false` and `message` explains why when it does not, which fits variable validation blocks. The size limit defaults to %d bytes", defaultMaxPayloadBytes),
+		Parameters: []function.Parameter{
+			function.StringParameter{
+				Name:                "payload",
+				MarkdownDescription: "The payload to check",
+			},
+			function.Int64Parameter{
+				Name:                "max_bytes",
+				MarkdownDescription: "The size limit of the server in bytes, or null for the default",
+				AllowNullValue:      true,
+			},
+		},
+		Return: function.ObjectReturn{
+			AttributeTypes: map[string]attr.Type{
+				"valid":   types.BoolType,
+				"message": types.StringType,
+			},
+		},
+	}
+}
+
+func (f *ValidatePayloadFunction) Run(ctx context.Context, req function.RunRequest, resp *function.RunResponse) {
+	var payload string
+	var maxBytes *int64
+
+	resp.Error = function.ConcatFuncErrors(req.Arguments.Get(ctx, &payload, &maxBytes))
+
+	if resp.Error != nil {
+		return
+	}
+
+	limit := int64(defaultMaxPayloadBytes)
+	if maxBytes != nil {
+		limit = *maxBytes
+	}
+
+	resp.Error = function.ConcatFuncErrors(resp.Result.Set(ctx, validatePayload(payload, limit)))
+}
+
+// validatePayload checks payload against the encoding constraints and the
+// size limit.
+func validatePayload(payload string, limit int64) PayloadValidation {
+	switch {
+	case payload == "":
+		return PayloadValidation{Message: "The payload is empty"}
+	case !utf8.ValidString(payload):
+		return PayloadValidation{Message: "The payload is not valid UTF-8"}
+	case strings.ContainsRune(payload, 0):
+		return PayloadValidation{Message: "The payload contains NUL characters"}
+	case int64(len(payload)) > limit:
+		return PayloadValidation{Message: fmt.Sprintf("The payload is %d bytes, larger than the limit of %d bytes", len(payload), limit)}
+	}
+	return PayloadValidation{Valid: true}
+}
@@ -0,0 +1,45 @@
+// Copyright (c) Plex, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package provider
+
+import (
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-testing/helper/resource"
+	"github.com/hashicorp/terraform-plugin-testing/knownvalue"
+	"github.com/hashicorp/terraform-plugin-testing/statecheck"
+	"github.com/hashicorp/terraform-plugin-testing/tfversion"
+)
+
+func TestValidatePayloadFunction(t *testing.T) {
+	resource.UnitTest(t, resource.TestCase{
+		TerraformVersionChecks: []tfversion.TerraformVersionCheck{
+			tfversion.SkipBelow(tfversion.Version1_8_0),
+		},
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: `
+output "valid" {
+  value = provider::pwpusher::validate_payload("some-value", null)
+}
+
+output "oversized" {
+  value = provider::pwpusher::validate_payload("some-value", 4)
+}
+`,
+				ConfigStateChecks: []statecheck.StateCheck{
+					statecheck.ExpectKnownOutputValue("valid", knownvalue.ObjectExact(map[string]knownvalue.Check{
+						"valid":   knownvalue.Bool(true),
+						"message": knownvalue.StringExact(""),
+					})),
+					statecheck.ExpectKnownOutputValue("oversized", knownvalue.ObjectExact(map[string]knownvalue.Check{
+						"valid":   knownvalue.Bool(false),
+						"message": knownvalue.StringExact("The payload is 10 bytes, larger than the limit of 4 bytes"),
+					})),
+				},
+			},
+		},
+	})
+}
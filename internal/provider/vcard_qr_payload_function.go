@@ -0,0 +1,131 @@
+// Copyright (c) Plex, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package provider
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-framework/function"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+// Ensure provider defined types fully satisfy framework interfaces.
+var _ function.Function = &VcardQrPayloadFunction{}
+
+// vcardFields are the contact fields accepted by vcard_qr_payload mapped to
+// the vCard property they are written to, in output order. The name fields
+// are combined into the N and FN properties.
+var vcardFields = []struct {
+	field    string
+	property string
+}{
+	{"first_name", ""},
+	{"last_name", ""},
+	{"organization", "ORG"},
+	{"title", "TITLE"},
+	{"phone", "TEL"},
+	{"email", "EMAIL"},
+	{"url", "URL"},
+	{"address", "ADR"},
+	{"note", "NOTE"},
+}
+
+// vcardEscaper escapes the characters with a special meaning in vCard
+// property values.
+var vcardEscaper = strings.NewReplacer(`\`, `\\`, `,`, `\,`, `;`, `\;`, "\r\n", `\n`, "\n", `\n`)
+
+func NewVcardQrPayloadFunction() function.Function {
+	return &VcardQrPayloadFunction{}
+}
+
+// VcardQrPayloadFunction defines the function implementation.
+type VcardQrPayloadFunction struct{}
+
+func (f *VcardQrPayloadFunction) Metadata(ctx context.Context, req function.MetadataRequest, resp *function.MetadataResponse) {
+	resp.Name = "vcard_qr_payload"
+}
+
+func (f *VcardQrPayloadFunction) Definition(ctx context.Context, req function.DefinitionRequest, resp *function.DefinitionResponse) {
+	resp.Definition = function.Definition{
+		Summary:             "Build a vCard contact QR code payload",
+		MarkdownDescription: "Builds a vCard 3.0 payload from contact fields, for QR pushes that add a contact when scanned. The accepted fields are " + vcardFieldNames() + ", each optional, but at least one of `first_name` and `last_name` must be set",
+		Parameters: []function.Parameter{
+			function.MapParameter{
+				Name:                "contact",
+				MarkdownDescription: "The contact fields",
+				ElementType:         types.StringType,
+			},
+		},
+		Return: function.StringReturn{},
+	}
+}
+
+func (f *VcardQrPayloadFunction) Run(ctx context.Context, req function.RunRequest, resp *function.RunResponse) {
+	var contact map[string]string
+
+	resp.Error = function.ConcatFuncErrors(req.Arguments.Get(ctx, &contact))
+
+	if resp.Error != nil {
+		return
+	}
+
+	var unknown []string
+	for field := range contact {
+		if !isVcardField(field) {
+			unknown = append(unknown, field)
+		}
+	}
+	if len(unknown) > 0 {
+		sort.Strings(unknown)
+		resp.Error = function.NewArgumentFuncError(0, fmt.Sprintf("unsupported contact fields %s, expected %s", strings.Join(unknown, ", "), vcardFieldNames()))
+		return
+	}
+	first, last := contact["first_name"], contact["last_name"]
+	if first == "" && last == "" {
+		resp.Error = function.NewArgumentFuncError(0, "at least one of first_name and last_name must be set")
+		return
+	}
+
+	lines := []string{
+		"BEGIN:VCARD",
+		"VERSION:3.0",
+		"N:" + vcardEscaper.Replace(last) + ";" + vcardEscaper.Replace(first) + ";;;",
+		"FN:" + vcardEscaper.Replace(strings.TrimSpace(first+" "+last)),
+	}
+	for _, vf := range vcardFields {
+		value := contact[vf.field]
+		if vf.property == "" || value == "" {
+			continue
+		}
+		if vf.property == "ADR" {
+			lines = append(lines, "ADR:;;"+vcardEscaper.Replace(value)+";;;;")
+			continue
+		}
+		lines = append(lines, vf.property+":"+vcardEscaper.Replace(value))
+	}
+	lines = append(lines, "END:VCARD")
+
+	resp.Error = function.ConcatFuncErrors(resp.Result.Set(ctx, strings.Join(lines, "\r\n")))
+}
+
+func isVcardField(field string) bool {
+	for _, f := range vcardFields {
+		if f.field == field {
+			return true
+		}
+	}
+	return false
+}
+
+// vcardFieldNames returns the accepted contact fields for use in messages.
+func vcardFieldNames() string {
+	names := make([]string, len(vcardFields))
+	for i, f := range vcardFields {
+		names[i] = f.field
+	}
+	return strings.Join(names, ", ")
+}
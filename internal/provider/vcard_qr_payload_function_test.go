@@ -0,0 +1,53 @@
+// Copyright (c) Plex, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package provider
+
+import (
+	"regexp"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-testing/helper/resource"
+	"github.com/hashicorp/terraform-plugin-testing/knownvalue"
+	"github.com/hashicorp/terraform-plugin-testing/statecheck"
+	"github.com/hashicorp/terraform-plugin-testing/tfversion"
+)
+
+func TestVcardQrPayloadFunction(t *testing.T) {
+	resource.UnitTest(t, resource.TestCase{
+		TerraformVersionChecks: []tfversion.TerraformVersionCheck{
+			tfversion.SkipBelow(tfversion.Version1_8_0),
+		},
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: `
+output "test" {
+  value = provider::pwpusher::vcard_qr_payload({
+    first_name   = "Ada"
+    last_name    = "Lovelace"
+    organization = "Analytical Engines, Ltd"
+    email        = "ada@example.com"
+  })
+}
+`,
+				ConfigStateChecks: []statecheck.StateCheck{
+					statecheck.ExpectKnownOutputValue("test", knownvalue.StringExact(
+						"BEGIN:VCARD\r\nVERSION:3.0\r\nN:Lovelace;Ada;;;\r\nFN:Ada Lovelace\r\nORG:Analytical Engines\\, Ltd\r\nEMAIL:ada@example.com\r\nEND:VCARD",
+					)),
+				},
+			},
+			{
+				Config: `
+output "test" {
+  value = provider::pwpusher::vcard_qr_payload({
+    first_name = "Ada"
+    nickname   = "Countess"
+  })
+}
+`,
+				ExpectError: regexp.MustCompile("unsupported contact fields nickname"),
+			},
+		},
+	})
+}
@@ -0,0 +1,100 @@
+// Copyright (c) Plex, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package provider
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-framework/function"
+)
+
+// Ensure provider defined types fully satisfy framework interfaces.
+var _ function.Function = &WifiQrPayloadFunction{}
+
+// wifiSecurityNone is the security type of open networks.
+const wifiSecurityNone = "nopass"
+
+// wifiSecurityTypes are the security types of the WIFI: payload.
+var wifiSecurityTypes = []string{"WPA", "WEP", "SAE", wifiSecurityNone}
+
+// qrPayloadEscaper escapes the characters with a special meaning in the
+// fields of WIFI: and MECARD style payloads.
+var qrPayloadEscaper = strings.NewReplacer(`\`, `\\`, `;`, `\;`, `,`, `\,`, `:`, `\:`, `"`, `\"`)
+
+func NewWifiQrPayloadFunction() function.Function {
+	return &WifiQrPayloadFunction{}
+}
+
+// WifiQrPayloadFunction defines the function implementation.
+type WifiQrPayloadFunction struct{}
+
+func (f *WifiQrPayloadFunction) Metadata(ctx context.Context, req function.MetadataRequest, resp *function.MetadataResponse) {
+	resp.Name = "wifi_qr_payload"
+}
+
+func (f *WifiQrPayloadFunction) Definition(ctx context.Context, req function.DefinitionRequest, resp *function.DefinitionResponse) {
+	resp.Definition = function.Definition{
+		Summary:             "Build a Wi-Fi network QR code payload",
+		MarkdownDescription: "Builds the `WIFI:` payload that phones recognize in QR codes to join a Wi-Fi network, escaping the special characters of the SSID and password. Push the result as the payload of a QR push, or render it with `qr_data_uri`",
+		Parameters: []function.Parameter{
+			function.StringParameter{
+				Name:                "ssid",
+				MarkdownDescription: "The name of the network",
+			},
+			function.StringParameter{
+				Name:                "password",
+				MarkdownDescription: "The password of the network, empty for open networks",
+			},
+			function.StringParameter{
+				Name:                "security",
+				MarkdownDescription: "The security type of the network, one of " + strings.Join(wifiSecurityTypes, ", "),
+			},
+			function.BoolParameter{
+				Name:                "hidden",
+				MarkdownDescription: "If the network does not broadcast its SSID",
+			},
+		},
+		Return: function.StringReturn{},
+	}
+}
+
+func (f *WifiQrPayloadFunction) Run(ctx context.Context, req function.RunRequest, resp *function.RunResponse) {
+	var ssid, password, security string
+	var hidden bool
+
+	resp.Error = function.ConcatFuncErrors(req.Arguments.Get(ctx, &ssid, &password, &security, &hidden))
+
+	if resp.Error != nil {
+		return
+	}
+
+	if ssid == "" {
+		resp.Error = function.NewArgumentFuncError(0, "ssid must not be empty")
+		return
+	}
+	known := false
+	for _, t := range wifiSecurityTypes {
+		known = known || t == security
+	}
+	if !known {
+		resp.Error = function.NewArgumentFuncError(2, fmt.Sprintf("security must be one of %s, got %q", strings.Join(wifiSecurityTypes, ", "), security))
+		return
+	}
+	if (security == wifiSecurityNone) != (password == "") {
+		resp.Error = function.NewArgumentFuncError(1, fmt.Sprintf("password must be empty if and only if security is %s", wifiSecurityNone))
+		return
+	}
+
+	payload := "WIFI:T:" + security + ";S:" + qrPayloadEscaper.Replace(ssid) + ";"
+	if password != "" {
+		payload += "P:" + qrPayloadEscaper.Replace(password) + ";"
+	}
+	if hidden {
+		payload += "H:true;"
+	}
+
+	resp.Error = function.ConcatFuncErrors(resp.Result.Set(ctx, payload+";"))
+}
@@ -0,0 +1,48 @@
+// Copyright (c) Plex, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package provider
+
+import (
+	"regexp"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-testing/helper/resource"
+	"github.com/hashicorp/terraform-plugin-testing/knownvalue"
+	"github.com/hashicorp/terraform-plugin-testing/statecheck"
+	"github.com/hashicorp/terraform-plugin-testing/tfversion"
+)
+
+func TestWifiQrPayloadFunction(t *testing.T) {
+	resource.UnitTest(t, resource.TestCase{
+		TerraformVersionChecks: []tfversion.TerraformVersionCheck{
+			tfversion.SkipBelow(tfversion.Version1_8_0),
+		},
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: `
+output "wpa" {
+  value = provider::pwpusher::wifi_qr_payload("Office;5G", "pa:ss", "WPA", true)
+}
+
+output "open" {
+  value = provider::pwpusher::wifi_qr_payload("Guests", "", "nopass", false)
+}
+`,
+				ConfigStateChecks: []statecheck.StateCheck{
+					statecheck.ExpectKnownOutputValue("wpa", knownvalue.StringExact(`WIFI:T:WPA;S:Office\;5G;P:pa\:ss;H:true;;`)),
+					statecheck.ExpectKnownOutputValue("open", knownvalue.StringExact(`WIFI:T:nopass;S:Guests;;`)),
+				},
+			},
+			{
+				Config: `
+output "test" {
+  value = provider::pwpusher::wifi_qr_payload("Office", "secret", "WPA3", false)
+}
+`,
+				ExpectError: regexp.MustCompile("security must be one of"),
+			},
+		},
+	})
+}
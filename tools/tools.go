@@ -13,6 +13,9 @@ import (
 // Generate copyright headers
 //go:generate go run github.com/hashicorp/copywrite headers -d .. --config ../.copywrite.hcl
 
+// Generate the types and paths of the API client from its OpenAPI specification.
+//go:generate go -C .. generate ./internal/client
+
 // Format Terraform code for use in documentation.
 // If you do not have Terraform installed, you can remove the formatting command, but it is suggested
 // to ensure the documentation is formatted properly.